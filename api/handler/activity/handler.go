@@ -0,0 +1,206 @@
+package activity
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	_mongo "proman-backend/internal/pkg/mongo"
+	"proman-backend/internal/pkg/util"
+	"strings"
+)
+
+const defaultLimit = 50
+
+type Handler struct {
+	userRepo     *repository.UserCollRepository
+	projectRepo  *repository.ProjectCollRepository
+	taskRepo     *repository.TaskCollRepository
+	activityRepo *repository.ActivityCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:     repository.NewUserCollRepository(db),
+		projectRepo:  repository.NewProjectCollRepository(db),
+		taskRepo:     repository.NewTaskCollRepository(db),
+		activityRepo: repository.NewActivityCollRepository(db),
+	}
+
+	activity := e.Group("/api", context.ContextHandler)
+
+	activity.GET("/project/:id/activity", h.projectActivity)
+	activity.GET("/task/:id/activity", h.taskActivity)
+
+	admin := e.Group("/api/admin", context.ContextHandler, context.AdminOnly)
+
+	admin.GET("/activity", h.feed)
+
+	return h
+}
+
+// Project Activity
+// @Tags Activity
+// @Summary Get the activity of a project, its tasks and its schedules
+// @ID activity-project
+// @Router /api/project/{id}/activity [get]
+// @Param id path string true "Project ID"
+// @Param type query string false "Entity type" Enums(project, task, schedule, milestone, sprint)
+// @Param userId query string false "Actor ID"
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Param page query int false "Page number pagination"
+// @Param limit query int false "Limit pagination"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) projectActivity(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.findProject(oId)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	cq := newQuery(c)
+	cq.ProjectId = project.ID
+	return h.render(c, cq, bson.NilObjectID)
+}
+
+// Task Activity
+// @Tags Activity
+// @Summary Get the activity of a task
+// @ID activity-task
+// @Router /api/task/{id}/activity [get]
+// @Param id path string true "Task ID"
+// @Param userId query string false "Actor ID"
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Param page query int false "Page number pagination"
+// @Param limit query int false "Limit pagination"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) taskActivity(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	task, err := h.taskRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	cq := newQuery(c)
+	cq.Type = ""
+	return h.render(c, cq, task.ID)
+}
+
+// Activity Feed
+// @Tags Admin
+// @Summary Get the activity of every project, task, schedule and user
+// @ID admin-activity
+// @Router /api/admin/activity [get]
+// @Param type query string false "Entity type" Enums(project, task, schedule, milestone, sprint, user)
+// @Param userId query string false "Actor ID"
+// @Param projectId query string false "Project ID"
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Param page query int false "Page number pagination"
+// @Param limit query int false "Limit pagination"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) feed(c echo.Context) error {
+	return h.render(c, newQuery(c), bson.NilObjectID)
+}
+
+// newQuery reads the common query, the feed is paginated by default as it grows with every change
+func newQuery(c echo.Context) *util.CommonQuery {
+	cq := util.NewCommonQuery(c)
+	if strings.TrimSpace(c.QueryParam("limit")) == "" {
+		cq.Limit = defaultLimit
+	}
+	return cq
+}
+
+func (h *Handler) render(c echo.Context, cq *util.CommonQuery, entityID bson.ObjectID) error {
+	activities, err := h.activityRepo.FindAll(cq, entityID)
+	if err != nil {
+		log.Errorf("Error finding activity: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	total, err := h.activityRepo.Count(cq, entityID)
+	if err != nil {
+		log.Errorf("Error counting activity: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	actors := map[bson.ObjectID]map[string]interface{}{}
+	docs := make([]map[string]interface{}, 0)
+	for _, activity := range activities {
+		actor, exists := actors[activity.ActorID]
+		if !exists {
+			actor = map[string]interface{}{"_id": activity.ActorID, "name": "", "position": ""}
+			if user, err := h.userRepo.FindOneByID(activity.ActorID); err == nil {
+				actor["name"] = user.Name
+				actor["position"] = user.Position
+			}
+			actors[activity.ActorID] = actor
+		}
+
+		docs = append(docs, map[string]interface{}{
+			"_id":         activity.ID,
+			"actor":       actor,
+			"entity_type": activity.EntityType,
+			"entity_id":   activity.EntityID,
+			"project_id":  activity.ProjectID,
+			"action":      activity.Action,
+			"changes":     activity.Changes,
+			"created_at":  activity.CreatedAt,
+		})
+	}
+
+	result := _mongo.MakePaginateResult(docs, total, cq.Page, cq.Limit)
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) findProject(projectID bson.ObjectID) (*repository.Project, error) {
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return project, nil
+}
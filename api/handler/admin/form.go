@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"github.com/asaskevich/govalidator"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	_const "proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/log"
+	"strings"
+)
+
+const (
+	minNameLength     = 1
+	maxNameLength     = 50
+	minEmailLength    = 3
+	maxEmailLength    = 50
+	minPasswordLength = 6
+	maxPasswordLength = 50
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type createUserForm struct {
+	Name     string `form:"name" json:"name"`
+	Email    string `form:"email" json:"email"`
+	Password string `form:"password" json:"password"`
+	Position string `form:"position" json:"position"`
+	Role     string `form:"role" json:"role"`
+}
+
+func newCreateUserForm(c echo.Context) (*createUserForm, error) {
+	form := new(createUserForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	form.Email = strings.ToLower(strings.TrimSpace(form.Email))
+	form.Position = strings.TrimSpace(form.Position)
+	form.Role = strings.ToLower(strings.TrimSpace(form.Role))
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate name
+	if len(form.Name) < minNameLength || len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 50 characters",
+		})
+	}
+
+	// Validate email
+	if len(form.Email) < minEmailLength || len(form.Email) > maxEmailLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "email",
+			Message: "Email must be between 3 and 50 characters",
+		})
+	} else if !govalidator.IsEmail(form.Email) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "email",
+			Message: "Invalid email format",
+		})
+	}
+
+	// Validate password
+	if len(form.Password) < minPasswordLength || len(form.Password) > maxPasswordLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "password",
+			Message: "Password must be between 6 and 50 characters",
+		})
+	}
+
+	// Validate position
+	if len(form.Position) != 0 && !_const.IsValidPosition(form.Position) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "position",
+			Message: "Invalid position",
+		})
+	}
+
+	// Validate role
+	if !_const.IsValidRole(form.Role) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "role",
+			Message: "Invalid role",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type updateRoleForm struct {
+	Role string `form:"role" json:"role"`
+}
+
+func newUpdateRoleForm(c echo.Context) (*updateRoleForm, error) {
+	form := new(updateRoleForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	}
+
+	form.Role = strings.ToLower(strings.TrimSpace(form.Role))
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate role
+	if !_const.IsValidRole(form.Role) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "role",
+			Message: "Invalid role",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
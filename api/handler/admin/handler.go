@@ -0,0 +1,285 @@
+package admin
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/config"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	_mongo "proman-backend/internal/pkg/mongo"
+	"proman-backend/internal/pkg/util"
+	"strings"
+	"time"
+)
+
+type Handler struct {
+	userRepo *repository.UserCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo: repository.NewUserCollRepository(db),
+	}
+
+	admin := e.Group("/api/admin", context.ContextHandler, context.AdminOnly)
+
+	admin.GET("/users", h.userList)
+
+	admin.POST("/user", h.createUser)
+
+	admin.PUT("/user/:id/role", h.updateRole)
+	admin.PUT("/user/:id/deactivate", h.deactivate)
+	admin.PUT("/user/:id/reactivate", h.reactivate)
+
+	return h
+}
+
+// User List
+// @Tags Admin
+// @Summary Get list users with role and status
+// @ID admin-user-list
+// @Router /api/admin/users [get]
+// @Param q query string false "Search by name or email"
+// @Param role query string false "Search by role" Enums(admin, maintainer, developer)
+// @Param sort query string false "Sort" enums(asc,desc)
+// @Param page query int false "Page number pagination"
+// @Param limit query int false "Limit pagination"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) userList(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+
+	limit := cq.Limit
+	page := cq.Page
+
+	users, err := h.userRepo.FindAllUsers(cq)
+	if err != nil {
+		log.Errorf("Error finding user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	for i, user := range users {
+		if _, ok := user["avatar"].(string); ok && user["avatar"] != "" {
+			url := "https://" + config.S3.Bucket
+			if !strings.Contains(config.S3.EndPoint, "https://") {
+				users[i]["avatar"] = url + "." + config.S3.EndPoint + "/" + users[i]["avatar"].(string)
+			} else {
+				users[i]["avatar"] = url + "." + config.S3.EndPoint[8:] + "/" + users[i]["avatar"].(string)
+			}
+		}
+		if role, ok := user["role"].(string); !ok || !_const.IsValidRole(role) {
+			users[i]["role"] = _const.RoleDeveloper
+		}
+	}
+
+	cq.ResetPagination()
+	totalUsers, err := h.userRepo.FindAllUsers(cq)
+	if err != nil {
+		log.Errorf("Error finding user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	result := _mongo.MakePaginateResult(users, int64(len(totalUsers)), page, limit)
+	return c.JSON(http.StatusOK, result)
+}
+
+// Create User
+// @Tags Admin
+// @Summary Create user with role
+// @ID admin-user-create
+// @Router /api/admin/user [post]
+// @Accept json
+// @Param body body createUserForm true "create user json"
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createUser(c echo.Context) error {
+	docForm, err := newCreateUserForm(c)
+	if err != nil {
+		return err
+	}
+
+	u, _ := h.userRepo.FindOneByEmail(docForm.Email)
+	if u != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Email already registered")
+	}
+
+	position := docForm.Position
+	if position == "" {
+		position = _const.PositionOther
+	}
+
+	user := &repository.User{
+		ID:            bson.NewObjectID(),
+		Email:         docForm.Email,
+		Password:      util.CryptPassword(docForm.Password),
+		Name:          docForm.Name,
+		Position:      position,
+		Role:          docForm.Role,
+		Avatar:        "",
+		Phone:         "",
+		CreatedAt:     time.Now(),
+		IsDeactivated: false,
+		IsDeleted:     false,
+	}
+
+	doc, err := h.userRepo.Insert(user)
+	if err != nil {
+		log.Errorf("Error inserting user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+// Update User Role
+// @Tags Admin
+// @Summary Change user role
+// @ID admin-user-role
+// @Router /api/admin/user/{id}/role [put]
+// @Accept json
+// @Param id path string true "User ID"
+// @Param body body updateRoleForm true "update role json"
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateRole(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	docForm, err := newUpdateRoleForm(c)
+	if err != nil {
+		return err
+	}
+
+	if user.GetRole() == _const.RoleAdmin && docForm.Role != _const.RoleAdmin {
+		if err := h.ensureNotLastAdmin(user); err != nil {
+			return err
+		}
+	}
+
+	user.Role = docForm.Role
+
+	doc, err := h.userRepo.Update(user)
+	if err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+// Deactivate User
+// @Tags Admin
+// @Summary Deactivate user
+// @ID admin-user-deactivate
+// @Router /api/admin/user/{id}/deactivate [put]
+// @Accept json
+// @Param id path string true "User ID"
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deactivate(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	if user.ID == uc.Claims.IDAsObjectID {
+		return echo.NewHTTPError(http.StatusBadRequest, "You cannot deactivate your own account")
+	}
+	if user.IsDeactivated {
+		return echo.NewHTTPError(http.StatusBadRequest, "User is already deactivated")
+	}
+	if user.GetRole() == _const.RoleAdmin {
+		if err := h.ensureNotLastAdmin(user); err != nil {
+			return err
+		}
+	}
+
+	user.IsDeactivated = true
+
+	doc, err := h.userRepo.Update(user)
+	if err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+// Reactivate User
+// @Tags Admin
+// @Summary Reactivate user
+// @ID admin-user-reactivate
+// @Router /api/admin/user/{id}/reactivate [put]
+// @Accept json
+// @Param id path string true "User ID"
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) reactivate(c echo.Context) error {
+	user, err := h.findUser(c)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsDeactivated {
+		return echo.NewHTTPError(http.StatusBadRequest, "User is not deactivated")
+	}
+
+	user.IsDeactivated = false
+
+	doc, err := h.userRepo.Update(user)
+	if err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+func (h *Handler) findUser(c echo.Context) (*repository.User, error) {
+	id := c.Param("id")
+	if id == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "User ID cannot be empty.")
+	}
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID.")
+	}
+
+	user, err := h.userRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		log.Errorf("Error finding user: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return user, nil
+}
+
+func (h *Handler) ensureNotLastAdmin(user *repository.User) error {
+	if user.IsDeactivated {
+		return nil
+	}
+
+	count, err := h.userRepo.CountActiveByRole(_const.RoleAdmin)
+	if err != nil {
+		log.Errorf("Error counting admin: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if count <= 1 {
+		return echo.NewHTTPError(http.StatusBadRequest, "At least one active admin is required")
+	}
+	return nil
+}
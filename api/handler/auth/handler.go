@@ -9,19 +9,21 @@ import (
 	"proman-backend/api/repository"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/job"
 	"proman-backend/internal/pkg/log"
-	"proman-backend/internal/pkg/mail"
 	"proman-backend/internal/pkg/util"
 	"time"
 )
 
 type Handler struct {
 	userRepo *repository.UserCollRepository
+	queue    *job.Queue
 }
 
 func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	h := &Handler{
 		userRepo: repository.NewUserCollRepository(db),
+		queue:    job.NewQueue(db),
 	}
 
 	e.POST("/api/login", h.login)
@@ -60,6 +62,10 @@ func (h *Handler) login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Wrong email or password")
 	}
 
+	if u.IsDeactivated {
+		return echo.NewHTTPError(http.StatusForbidden, "Your account has been deactivated")
+	}
+
 	accessToken, err := context.MakeToken(u)
 	if err != nil {
 		log.Errorf("Error creating token: %v", err)
@@ -94,6 +100,7 @@ func (h *Handler) register(c echo.Context) error {
 		Password:  util.CryptPassword(docForm.Password),
 		Name:      docForm.Name,
 		Position:  _const.PositionOther,
+		Role:      _const.RoleDeveloper,
 		Avatar:    "",
 		Phone:     "",
 		CreatedAt: time.Now(),
@@ -105,6 +112,12 @@ func (h *Handler) register(c echo.Context) error {
 		log.Errorf("Error inserting user: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	// The very first account becomes the admin so the instance can be managed, it is only claimed
+	// once the account exists. On error the account stays a developer.
+	if _, err := h.userRepo.ClaimFirstAdmin(doc); err != nil {
+		log.Errorf("Error granting first admin: %v", err)
+	}
 	return c.JSON(http.StatusOK, doc)
 }
 
@@ -133,21 +146,15 @@ func (h *Handler) forgotPassword(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 
-	newPassword := util.RandomString(10)
-	u.Password = util.CryptPassword(newPassword)
+	if u.IsDeactivated {
+		log.Warnf("Forgot password requested for deactivated user: %v", docForm.Email)
+		return c.JSON(http.StatusOK, map[string]string{"message": "New password has been sent to your email"})
+	}
 
-	_, err = h.userRepo.Update(u)
-	if err != nil {
-		log.Errorf("Error updating user: %v", err)
+	// The password is only replaced by the job, so a request that cannot be queued changes nothing
+	if err := h.queue.ResetPassword(u.ID); err != nil {
+		log.Errorf("Error queuing password reset: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
-
-	go func(email string, template string) {
-		err = mail.SendMail(nil, []string{email}, "New Password", template)
-		if err != nil {
-			log.Errorf("Error sending email: %v", err)
-			return
-		}
-	}(u.Email, mail.ForgotTemplate(newPassword))
 	return c.JSON(http.StatusOK, map[string]string{"message": "New password has been sent to your email"})
 }
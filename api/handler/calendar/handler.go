@@ -0,0 +1,273 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/ical"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"strings"
+	"time"
+)
+
+const (
+	feedTokenLength = 32
+	feedPastDays    = 90
+	feedFutureDays  = 365
+)
+
+type Handler struct {
+	userRepo     *repository.UserCollRepository
+	projectRepo  *repository.ProjectCollRepository
+	taskRepo     *repository.TaskCollRepository
+	scheduleRepo *repository.ScheduleCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:     repository.NewUserCollRepository(db),
+		projectRepo:  repository.NewProjectCollRepository(db),
+		taskRepo:     repository.NewTaskCollRepository(db),
+		scheduleRepo: repository.NewScheduleCollRepository(db),
+	}
+
+	calendar := e.Group("/api", context.ContextHandler)
+
+	calendar.POST("/me/calendar/token", h.createToken)
+
+	calendar.DELETE("/me/calendar/token", h.revokeToken)
+
+	// Calendar clients cannot send the Authorization header, feeds are authenticated by the feed token instead.
+	e.GET("/api/me/calendar.ics", h.myCalendar)
+	e.GET("/api/project/:id/calendar.ics", h.projectCalendar)
+
+	return h
+}
+
+// Create Feed Token
+// @Tags Calendar
+// @Summary Create or rotate my calendar feed token
+// @Description The token is only shown once, creating a new one revokes the previous token.
+// @ID calendar-token-create
+// @Router /api/me/calendar/token [post]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createToken(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	user, err := h.userRepo.FindOneByID(uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error finding user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	token := util.SecureRandomString(feedTokenLength)
+	user.FeedToken = util.HashToken(token)
+
+	if _, err := h.userRepo.Update(user); err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	baseURL := c.Scheme() + "://" + c.Request().Host
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"token":       token,
+		"url":         fmt.Sprintf("%v/api/me/calendar.ics?token=%v", baseURL, token),
+		"project_url": fmt.Sprintf("%v/api/project/{id}/calendar.ics?token=%v", baseURL, token),
+	})
+}
+
+// Revoke Feed Token
+// @Tags Calendar
+// @Summary Revoke my calendar feed token
+// @ID calendar-token-revoke
+// @Router /api/me/calendar/token [delete]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) revokeToken(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	user, err := h.userRepo.FindOneByID(uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error finding user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	user.FeedToken = ""
+
+	if _, err := h.userRepo.Update(user); err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, "Calendar feed token revoked")
+}
+
+// My Calendar
+// @Tags Calendar
+// @Summary Get my schedules and task deadlines as an iCalendar feed
+// @ID calendar-me
+// @Router /api/me/calendar.ics [get]
+// @Param token query string true "Calendar feed token"
+// @Produce text/calendar
+// @Success 200
+func (h *Handler) myCalendar(c echo.Context) error {
+	user, err := h.findFeedUser(c)
+	if err != nil {
+		return err
+	}
+
+	cq := feedQuery()
+	cq.UserId = user.ID
+
+	calendar := ical.NewCalendar("Proman - " + user.Name)
+	if err := h.addEvents(calendar, cq); err != nil {
+		return err
+	}
+	return h.render(c, calendar)
+}
+
+// Project Calendar
+// @Tags Calendar
+// @Summary Get project schedules and task deadlines as an iCalendar feed
+// @ID calendar-project
+// @Router /api/project/{id}/calendar.ics [get]
+// @Param id path string true "Project ID"
+// @Param token query string true "Calendar feed token"
+// @Produce text/calendar
+// @Success 200
+func (h *Handler) projectCalendar(c echo.Context) error {
+	user, err := h.findFeedUser(c)
+	if err != nil {
+		return err
+	}
+
+	projectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	claims := &context.UserClaims{IDAsObjectID: user.ID, Role: user.GetRole()}
+	if err := access.RequireViewProject(claims, project); err != nil {
+		return err
+	}
+
+	cq := feedQuery()
+	cq.ProjectId = project.ID
+
+	calendar := ical.NewCalendar("Proman - " + project.Name)
+	if err := h.addEvents(calendar, cq); err != nil {
+		return err
+	}
+	return h.render(c, calendar)
+}
+
+func (h *Handler) findFeedUser(c echo.Context) (*repository.User, error) {
+	token := strings.TrimSpace(c.QueryParam("token"))
+	if token == "" {
+		return nil, echo.ErrUnauthorized
+	}
+
+	user, err := h.userRepo.FindOneByFeedToken(util.HashToken(token))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.ErrUnauthorized
+		}
+		log.Errorf("Error finding user: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return user, nil
+}
+
+// feedQuery limits the feed to a window around today, calendar clients poll the whole feed on every refresh
+func feedQuery() *util.CommonQuery {
+	cq := util.NilCommonQuery()
+	cq.Start = util.StartOfDay(-feedPastDays)
+	cq.End = util.StartOfDay(feedFutureDays)
+	return cq
+}
+
+func (h *Handler) addEvents(calendar *ical.Calendar, cq *util.CommonQuery) error {
+	schedules, err := h.scheduleRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	for _, schedule := range schedules {
+		for _, occurrence := range schedule.Occurrences(cq.Start, cq.End) {
+			// A schedule spanning several days takes place every day within its daily time window.
+			for day := util.DateOf(occurrence.StartDate); !day.After(occurrence.EndDate); day = day.AddDate(0, 0, 1) {
+				calendar.Add(ical.Event{
+					UID:         ical.UID("schedule", schedule.ID.Hex(), day.Format("20060102")),
+					Summary:     occurrence.Name,
+					Description: occurrence.Description,
+					Categories:  []string{occurrence.Type},
+					Start:       atTime(day, occurrence.StartTime),
+					End:         atTime(day, occurrence.EndTime),
+					Created:     schedule.CreatedAt,
+				})
+			}
+		}
+	}
+
+	tasks, err := h.taskRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	for _, task := range tasks {
+		if task.EndDate.Before(cq.Start) || !task.EndDate.Before(cq.End) {
+			continue
+		}
+
+		deadline := util.DateOf(task.EndDate)
+		calendar.Add(ical.Event{
+			UID:         ical.UID("task", task.ID.Hex(), "deadline"),
+			Summary:     "Deadline: " + task.Name,
+			Description: task.Description,
+			Categories:  []string{"deadline", task.Status},
+			Start:       deadline,
+			End:         deadline.AddDate(0, 0, 1),
+			AllDay:      true,
+			Cancelled:   task.Status == _const.TaskCancelled,
+			Created:     task.CreatedAt,
+		})
+	}
+	return nil
+}
+
+func (h *Handler) render(c echo.Context, calendar *ical.Calendar) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="calendar.ics"`)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar.String()))
+}
+
+// atTime returns the given day at a 24-hour HH:MM time in the server timezone
+func atTime(day time.Time, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+}
@@ -0,0 +1,292 @@
+package chart
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"math"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"strings"
+	"time"
+)
+
+const (
+	unitTasks  = "tasks"
+	unitPoints = "points"
+
+	maxDays = 366
+)
+
+type Handler struct {
+	projectRepo *repository.ProjectCollRepository
+	taskRepo    *repository.TaskCollRepository
+	sprintRepo  *repository.SprintCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		projectRepo: repository.NewProjectCollRepository(db),
+		taskRepo:    repository.NewTaskCollRepository(db),
+		sprintRepo:  repository.NewSprintCollRepository(db),
+	}
+
+	chart := e.Group("/api", context.ContextHandler)
+
+	chart.GET("/project/:id/burndown", h.burndown)
+	chart.GET("/project/:id/cumulative-flow", h.cumulativeFlow)
+
+	return h
+}
+
+type burndownDay struct {
+	Date      time.Time `json:"date"`
+	Scope     *float64  `json:"scope"`     // work in the burndown at the end of the day, null for days to come
+	Remaining *float64  `json:"remaining"` // work not completed or cancelled at the end of the day, null for days to come
+	Ideal     float64   `json:"ideal"`
+}
+
+type flowDay struct {
+	Date      time.Time `json:"date"`
+	Active    int       `json:"active"`
+	Testing   int       `json:"testing"`
+	Completed int       `json:"completed"`
+	Cancelled int       `json:"cancelled"`
+}
+
+// Burndown
+// @Tags Chart
+// @Summary Get the remaining work of a project or sprint per day next to an ideal line
+// @Description The window defaults to the sprint dates, or the project dates without a sprint.
+// @Description The ideal line runs from the work at the start of the window to zero at its end.
+// @ID project-burndown
+// @Router /api/project/{id}/burndown [get]
+// @Param id path string true "Project ID"
+// @Param sprintId query string false "Sprint of the project"
+// @Param unit query string false "Count tasks or story points" Enums(tasks, points)
+// @Param start query string false "Window start date"
+// @Param end query string false "Window end date"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) burndown(c echo.Context) error {
+	unit := strings.ToLower(strings.TrimSpace(c.QueryParam("unit")))
+	if unit == "" {
+		unit = unitTasks
+	}
+	if unit != unitTasks && unit != unitPoints {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid unit, use tasks or points.")
+	}
+
+	cq, tasks, err := h.load(c)
+	if err != nil {
+		return err
+	}
+	days := dayStarts(cq.Start, cq.End)
+
+	weight := func(task *repository.Task) float64 {
+		if unit == unitPoints {
+			return float64(task.StoryPoints)
+		}
+		return 1
+	}
+
+	docs := make([]burndownDay, 0, len(days))
+	for _, day := range days {
+		docs = append(docs, burndownDay{Date: day})
+		if day.After(time.Now()) {
+			continue
+		}
+
+		at := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		scope, remaining := 0.0, 0.0
+		for i := range tasks {
+			switch tasks[i].StatusAt(at) {
+			case "", _const.TaskCancelled:
+			case _const.TaskCompleted:
+				scope += weight(&tasks[i])
+			default:
+				scope += weight(&tasks[i])
+				remaining += weight(&tasks[i])
+			}
+		}
+		docs[len(docs)-1].Scope = &scope
+		docs[len(docs)-1].Remaining = &remaining
+	}
+
+	// The ideal line starts at the work remaining when the window opens, or today for a window to come.
+	start := 0.0
+	if len(docs) > 0 && docs[0].Remaining != nil {
+		start = *docs[0].Remaining
+	} else {
+		for i := range tasks {
+			if status := tasks[i].Status; status == _const.TaskActive || status == _const.TaskTesting {
+				start += weight(&tasks[i])
+			}
+		}
+	}
+	for i := range docs {
+		if len(docs) > 1 {
+			docs[i].Ideal = math.Round(start*float64(len(docs)-1-i)/float64(len(docs)-1)*100) / 100
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"start": cq.Start,
+		"end":   cq.End,
+		"unit":  unit,
+		"days":  docs,
+	})
+}
+
+// Cumulative Flow
+// @Tags Chart
+// @Summary Get the number of tasks per status at the end of every day
+// @Description The window defaults to the sprint dates, or the project dates without a sprint. Days to come are left out.
+// @ID project-cumulative-flow
+// @Router /api/project/{id}/cumulative-flow [get]
+// @Param id path string true "Project ID"
+// @Param sprintId query string false "Sprint of the project"
+// @Param start query string false "Window start date"
+// @Param end query string false "Window end date"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) cumulativeFlow(c echo.Context) error {
+	cq, tasks, err := h.load(c)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]flowDay, 0)
+	for _, day := range dayStarts(cq.Start, cq.End) {
+		if day.After(time.Now()) {
+			break
+		}
+
+		at := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		doc := flowDay{Date: day}
+		for i := range tasks {
+			switch tasks[i].StatusAt(at) {
+			case _const.TaskActive:
+				doc.Active++
+			case _const.TaskTesting:
+				doc.Testing++
+			case _const.TaskCompleted:
+				doc.Completed++
+			case _const.TaskCancelled:
+				doc.Cancelled++
+			}
+		}
+		docs = append(docs, doc)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"start": cq.Start,
+		"end":   cq.End,
+		"days":  docs,
+	})
+}
+
+// load reads the project, the optional sprint and the window, and finds the tasks to replay.
+// A sprint includes the tasks it carried over, they have moved on to another sprint since.
+func (h *Handler) load(c echo.Context) (*util.CommonQuery, []repository.Task, error) {
+	projectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return nil, nil, err
+	}
+
+	cq := util.NewCommonQuery(c)
+	start, end := project.StartDate, project.EndDate
+
+	var sprint *repository.Sprint
+	if !cq.SprintId.IsZero() {
+		sprint, err = h.sprintRepo.FindOneByID(cq.SprintId)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Sprint not found")
+			}
+			log.Errorf("Error finding sprint: %v", err)
+			return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		if sprint.ProjectID != project.ID {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Sprint not found")
+		}
+		start, end = sprint.StartDate, sprint.EndDate
+	}
+
+	if len(c.QueryParam("start")) != 0 {
+		start = cq.Start
+	}
+	if len(c.QueryParam("end")) != 0 {
+		end = cq.End
+	}
+	if !end.After(start) {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "End date must be after the start date.")
+	}
+	if end.Sub(start) > maxDays*24*time.Hour {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "The window can be at most 366 days.")
+	}
+
+	// Tasks are replayed over the whole window, the date filter of the task list does not apply.
+	query := util.NilCommonQuery()
+	query.ProjectId = project.ID
+	query.SprintId = cq.SprintId
+	query.TopLevel = cq.TopLevel
+
+	tasks, err := h.taskRepo.FindAll(query)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if sprint != nil && len(sprint.CarriedOverTaskIDs) != 0 {
+		carriedOver, err := h.taskRepo.FindAllByIDs(sprint.CarriedOverTaskIDs)
+		if err != nil {
+			log.Errorf("Error finding task: %v", err)
+			return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		for _, task := range carriedOver {
+			if task.SprintID != sprint.ID && (!cq.TopLevel || task.ParentID.IsZero()) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	cq.Start, cq.End = start, end
+	return cq, tasks, nil
+}
+
+// dayStarts returns the start of every day from the start to the end date in the server timezone
+func dayStarts(start, end time.Time) []time.Time {
+	start = start.Local()
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+
+	days := make([]time.Time, 0)
+	for ; !day.After(end); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"proman-backend/api/repository"
 	"proman-backend/config"
+	"proman-backend/internal/pkg/job"
 	"proman-backend/internal/pkg/log"
 	"proman-backend/internal/pkg/mail"
 	"proman-backend/internal/pkg/util"
@@ -18,12 +19,14 @@ import (
 type Handler struct {
 	userRepo *repository.UserCollRepository
 	codeRepo *repository.CodeCollRepository
+	queue    *job.Queue
 }
 
 func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	h := &Handler{
 		userRepo: repository.NewUserCollRepository(db),
 		codeRepo: repository.NewCodeCollRepository(db),
+		queue:    job.NewQueue(db),
 	}
 
 	code := e.Group("/api", middleware.BasicAuth(
@@ -78,12 +81,8 @@ func (h *Handler) vcode(c echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]interface{}{"message": "Success, please check your email."})
 	}
 
-	go func(email string, template string) {
-		err = mail.SendMail(nil, []string{email}, "Verification Code", template)
-		if err != nil {
-			log.Errorf("Error sending email: %v", err)
-			return
-		}
-	}(user.Email, mail.VerificationCode(codeDoc.Code))
+	if err := h.queue.Mail([]string{user.Email}, "Verification Code", mail.VerificationCode(codeDoc.Code)); err != nil {
+		log.Errorf("Error queuing email: %v", err)
+	}
 	return c.JSON(http.StatusOK, map[string]interface{}{"message": "Success, please check your email."})
 }
@@ -0,0 +1,86 @@
+package comment
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"proman-backend/internal/pkg/log"
+	"strings"
+)
+
+const (
+	minBodyLength = 1
+	maxBodyLength = 10000
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type commentForm struct {
+	Body     string `json:"body" form:"body"`
+	ParentID string `json:"parent_id" form:"parent_id"` // optional, replies to another comment of the task
+}
+
+func newCommentForm(c echo.Context) (*commentForm, error) {
+	form := new(commentForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding comment form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Body = strings.TrimSpace(form.Body)
+	form.ParentID = strings.TrimSpace(form.ParentID)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate body
+	if len(form.Body) < minBodyLength || len(form.Body) > maxBodyLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "body",
+			Message: "Body must be between 1 and 10000 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type updateCommentForm struct {
+	Body              string `json:"body" form:"body"`
+	RemoveAttachments string `json:"remove_attachments" form:"remove_attachments"` // comma separated keys or urls
+}
+
+func newUpdateCommentForm(c echo.Context) (*updateCommentForm, error) {
+	form := new(updateCommentForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding update comment form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Body = strings.TrimSpace(form.Body)
+	form.RemoveAttachments = strings.TrimSpace(form.RemoveAttachments)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate body
+	if len(form.Body) > maxBodyLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "body",
+			Message: "Body must be between 1 and 10000 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
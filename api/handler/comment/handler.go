@@ -0,0 +1,332 @@
+package comment
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/config"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/file"
+	"proman-backend/internal/pkg/log"
+	"time"
+)
+
+type Handler struct {
+	userRepo    *repository.UserCollRepository
+	projectRepo *repository.ProjectCollRepository
+	taskRepo    *repository.TaskCollRepository
+	commentRepo *repository.CommentCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:    repository.NewUserCollRepository(db),
+		projectRepo: repository.NewProjectCollRepository(db),
+		taskRepo:    repository.NewTaskCollRepository(db),
+		commentRepo: repository.NewCommentCollRepository(db),
+	}
+
+	comment := e.Group("/api", context.ContextHandler)
+
+	comment.GET("/task/:id/comments", h.list)
+
+	comment.POST("/task/:id/comments", h.create)
+
+	comment.PUT("/comment/:id", h.update)
+
+	comment.DELETE("/comment/:id", h.delete)
+
+	return h
+}
+
+// List Comment
+// @Tags Comment
+// @Summary Get the comment threads of a task
+// @Description Replies are nested under their parent, deleted comments with replies are kept without their content.
+// @ID list-comment
+// @Router /api/task/{id}/comments [get]
+// @Param id path string true "Task ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) list(c echo.Context) error {
+	task, _, err := h.findTask(c, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	comments, err := h.commentRepo.FindAllByTaskID(task.ID)
+	if err != nil {
+		log.Errorf("Error finding comment: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	children := map[bson.ObjectID][]repository.Comment{}
+	for _, comment := range comments {
+		children[comment.ParentID] = append(children[comment.ParentID], comment)
+	}
+
+	authors := map[bson.ObjectID]map[string]interface{}{}
+	return c.JSON(http.StatusOK, h.thread(bson.NilObjectID, children, authors))
+}
+
+// Create Comment
+// @Tags Comment
+// @Summary Comment on a task
+// @ID create-comment
+// @Router /api/task/{id}/comments [post]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param body formData string true "Markdown body"
+// @Param parent_id formData string false "Comment ID to reply to"
+// @Param attachments formData file false "Comment attachments"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) create(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	task, _, err := h.findTask(c, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	form, err := newCommentForm(c)
+	if err != nil {
+		return err
+	}
+
+	parentOId := bson.NilObjectID
+	if len(form.ParentID) != 0 {
+		parentOId, err = bson.ObjectIDFromHex(form.ParentID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid parent comment ID.")
+		}
+		parent, err := h.commentRepo.FindOneByID(parentOId)
+		if err != nil || parent.TaskID != task.ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "Parent comment not found.")
+		}
+	}
+
+	attachments, err := file.GetFilesThenUpload(c, "attachments", config.AWS.FileDir)
+	if err != nil && !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		log.Errorf("Failed to upload attachments: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if attachments == nil {
+		attachments = make([]string, 0)
+	}
+
+	comment := &repository.Comment{
+		ID:          bson.NewObjectID(),
+		TaskID:      task.ID,
+		ParentID:    parentOId,
+		AuthorID:    uc.Claims.IDAsObjectID,
+		Body:        form.Body,
+		Attachments: attachments,
+		History:     make([]repository.CommentRevision, 0),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		IsDeleted:   false,
+	}
+
+	if err := h.commentRepo.CreateOne(comment); err != nil {
+		log.Errorf("Failed to create comment: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, comment)
+}
+
+// Update Comment
+// @Tags Comment
+// @Summary Edit my comment
+// @Description The previous body is kept in the comment history.
+// @ID update-comment
+// @Router /api/comment/{id} [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param body formData string false "Markdown body"
+// @Param attachments formData file false "Add comment attachments"
+// @Param remove_attachments formData string false "Comma separated attachment keys or urls to remove"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) update(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	comment, err := h.findComment(c)
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != uc.Claims.IDAsObjectID {
+		return echo.NewHTTPError(http.StatusForbidden, "You can only edit your own comments")
+	}
+	if _, _, err := h.findTask(c, comment.TaskID.Hex()); err != nil {
+		return err
+	}
+
+	form, err := newUpdateCommentForm(c)
+	if err != nil {
+		return err
+	}
+
+	keptAttachments, removedFiles, err := file.SplitAttachments(comment.Attachments, form.RemoveAttachments)
+	if err != nil {
+		return err
+	}
+
+	if len(form.Body) != 0 && form.Body != comment.Body {
+		comment.History = append(comment.History, repository.CommentRevision{
+			Body:     comment.Body,
+			EditedAt: comment.UpdatedAt,
+		})
+		comment.Body = form.Body
+	}
+
+	attachments, err := file.GetFilesThenUpload(c, "attachments", config.AWS.FileDir)
+	if err != nil && !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		log.Errorf("Failed to upload attachments: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	comment.Attachments = append(keptAttachments, attachments...)
+	comment.UpdatedAt = time.Now()
+
+	if err := h.commentRepo.UpdateOneByID(comment); err != nil {
+		log.Errorf("Failed to update comment: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	file.DeleteMany(removedFiles)
+	return c.JSON(http.StatusOK, comment)
+}
+
+// Delete Comment
+// @Tags Comment
+// @Summary Delete a comment
+// @Description Authors can delete their own comments, project managers can delete any comment of the project.
+// @ID delete-comment
+// @Router /api/comment/{id} [delete]
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) delete(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	comment, err := h.findComment(c)
+	if err != nil {
+		return err
+	}
+
+	_, project, err := h.findTask(c, comment.TaskID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != uc.Claims.IDAsObjectID && !access.HasProjectRole(uc.Claims, project, _const.ProjectRoleManager) {
+		return echo.NewHTTPError(http.StatusForbidden, "You can only delete your own comments")
+	}
+
+	if err := h.commentRepo.DeleteOneByID(comment.ID); err != nil {
+		log.Errorf("Failed to delete comment: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, "Comment deleted")
+}
+
+// findTask finds the task and its project, the user has to be able to view the project
+func (h *Handler) findTask(c echo.Context, id string) (*repository.Task, *repository.Project, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	task, err := h.taskRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.projectRepo.FindOneByID(task.ProjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return nil, nil, err
+	}
+	return task, project, nil
+}
+
+func (h *Handler) findComment(c echo.Context) (*repository.Comment, error) {
+	id := c.Param("id")
+	if id == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Comment ID cannot be empty.")
+	}
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid comment ID.")
+	}
+
+	comment, err := h.commentRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Comment not found")
+		}
+		log.Errorf("Error finding comment: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return comment, nil
+}
+
+// thread builds the replies of a comment recursively. Deleted comments are only kept,
+// without their content, when one of their replies is still visible.
+func (h *Handler) thread(parentID bson.ObjectID, children map[bson.ObjectID][]repository.Comment, authors map[bson.ObjectID]map[string]interface{}) []map[string]interface{} {
+	response := make([]map[string]interface{}, 0)
+
+	for _, comment := range children[parentID] {
+		replies := h.thread(comment.ID, children, authors)
+		if comment.IsDeleted && len(replies) == 0 {
+			continue
+		}
+
+		author, exists := authors[comment.AuthorID]
+		if !exists {
+			author = map[string]interface{}{"_id": comment.AuthorID, "name": "", "position": ""}
+			if user, err := h.userRepo.FindOneByID(comment.AuthorID); err == nil {
+				author["name"] = user.Name
+				author["position"] = user.Position
+			}
+			authors[comment.AuthorID] = author
+		}
+
+		if comment.IsDeleted {
+			comment.Body = ""
+			comment.Attachments = nil
+			comment.History = make([]repository.CommentRevision, 0)
+		}
+
+		response = append(response, map[string]interface{}{
+			"comment": &comment,
+			"author":  author,
+			"replies": replies,
+		})
+	}
+	return response
+}
@@ -0,0 +1,197 @@
+package digest
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/config"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/digest"
+	"proman-backend/internal/pkg/log"
+	"strings"
+	"time"
+)
+
+type Handler struct {
+	userRepo *repository.UserCollRepository
+	digester *digest.Digester
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo: repository.NewUserCollRepository(db),
+		digester: digest.NewDigester(db),
+	}
+
+	digests := e.Group("/api", context.ContextHandler)
+
+	digests.GET("/me/digest-preferences", h.preferences)
+	digests.PUT("/me/digest-preferences", h.updatePreferences)
+	digests.GET("/me/digest/:type", h.preview)
+
+	admin := e.Group("/api/admin", context.ContextHandler, context.AdminOnly)
+
+	admin.POST("/digest/:type/send", h.send)
+
+	return h
+}
+
+// Digest Preferences
+// @Tags Digest
+// @Summary Get which email digests I receive
+// @ID my-digest-preferences
+// @Router /api/me/digest-preferences [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) preferences(c echo.Context) error {
+	user, err := h.findUser(c.(*context.Context).Claims.IDAsObjectID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, renderPreferences(user))
+}
+
+// Update Digest Preferences
+// @Tags Digest
+// @Summary Turn email digests on or off, types left out keep their setting
+// @ID update-my-digest-preferences
+// @Router /api/me/digest-preferences [put]
+// @Param body body preferencesForm true "Digest type (daily, weekly) to whether it is mailed"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updatePreferences(c echo.Context) error {
+	form, err := newPreferencesForm(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.findUser(c.(*context.Context).Claims.IDAsObjectID)
+	if err != nil {
+		return err
+	}
+
+	muted := make([]string, 0)
+	for _, digestType := range _const.GetAllDigestTypes() {
+		enabled, ok := form.Preferences[digestType]
+		if !ok {
+			enabled = user.WantsDigest(digestType)
+		}
+		if !enabled {
+			muted = append(muted, digestType)
+		}
+	}
+	user.MutedDigests = muted
+
+	if _, err := h.userRepo.Update(user); err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, renderPreferences(user))
+}
+
+// Preview Digest
+// @Tags Digest
+// @Summary Render my daily or weekly digest as it would be mailed now
+// @ID preview-my-digest
+// @Router /api/me/digest/{type} [get]
+// @Param type path string true "Digest type" Enums(daily, weekly)
+// @Produce html
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) preview(c echo.Context) error {
+	digestType, err := parseType(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.findUser(c.(*context.Context).Claims.IDAsObjectID)
+	if err != nil {
+		return err
+	}
+
+	message, err := h.digester.Build(user, digestType, time.Now())
+	if err != nil {
+		log.Errorf("Error building digest: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.HTML(http.StatusOK, message.Body)
+}
+
+// Send Digest
+// @Tags Admin
+// @Summary Queue the daily or weekly digest of a user to be mailed now, regardless of their preferences and of earlier digests
+// @ID admin-send-digest
+// @Router /api/admin/digest/{type}/send [post]
+// @Param type path string true "Digest type" Enums(daily, weekly)
+// @Param userId query string true "User ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) send(c echo.Context) error {
+	digestType, err := parseType(c)
+	if err != nil {
+		return err
+	}
+
+	if !config.Mail.Enable {
+		return echo.NewHTTPError(http.StatusBadRequest, "Mail is disabled.")
+	}
+
+	userOId, err := bson.ObjectIDFromHex(strings.TrimSpace(c.QueryParam("userId")))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID.")
+	}
+
+	user, err := h.findUser(userOId)
+	if err != nil {
+		return err
+	}
+
+	sent, err := h.digester.Send(user, digestType, time.Now())
+	if err != nil {
+		log.Errorf("Error queuing digest: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !sent {
+		return c.JSON(http.StatusOK, map[string]interface{}{"sent": false, "message": "Nothing to report, no digest was sent."})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"sent": true, "message": "Digest queued for " + user.Email + "."})
+}
+
+func parseType(c echo.Context) (string, error) {
+	digestType := strings.ToLower(strings.TrimSpace(c.Param("type")))
+	if !_const.IsValidDigestType(digestType) {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid digest type.")
+	}
+	return digestType, nil
+}
+
+func (h *Handler) findUser(userID bson.ObjectID) (*repository.User, error) {
+	user, err := h.userRepo.FindOneByID(userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		log.Errorf("Error finding user: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return user, nil
+}
+
+// renderPreferences lists every digest type with whether the user receives it
+func renderPreferences(user *repository.User) map[string]bool {
+	preferences := map[string]bool{}
+	for _, digestType := range _const.GetAllDigestTypes() {
+		preferences[digestType] = user.WantsDigest(digestType)
+	}
+	return preferences
+}
@@ -0,0 +1,176 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/realtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxProjects       = 50
+	heartbeatInterval = 30 * time.Second
+	retryMillis       = 5000 // how long browsers wait before reconnecting
+)
+
+type Handler struct {
+	userRepo    *repository.UserCollRepository
+	projectRepo *repository.ProjectCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:    repository.NewUserCollRepository(db),
+		projectRepo: repository.NewProjectCollRepository(db),
+	}
+
+	// The stream authenticates itself, EventSource cannot send the Authorization header.
+	event := e.Group("/api")
+
+	event.GET("/events", h.stream)
+
+	return h
+}
+
+// Event Stream
+// @Tags Event
+// @Summary Stream task, project, schedule and other changes of the given projects and my notifications as Server-Sent Events
+// @Description Every event is named after its entity type, or notification, and carries a JSON object with its action, ids and changes.
+// @Description A client that falls behind is disconnected and should reload what it shows once it reconnects.
+// @ID event-stream
+// @Router /api/events [get]
+// @Param token query string false "Access token, for clients that cannot send the Authorization header"
+// @Param projectId query string false "Comma separated project IDs to receive the changes of"
+// @Produce text/event-stream
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) stream(c echo.Context) error {
+	claims, err := h.authenticate(c)
+	if err != nil {
+		return err
+	}
+
+	projectIDs, err := h.projectIDs(c, claims)
+	if err != nil {
+		return err
+	}
+
+	sub := realtime.Default.Subscribe(claims.IDAsObjectID, projectIDs)
+	defer realtime.Default.Unsubscribe(sub)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", retryMillis); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Errorf("Error encoding event: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: %v\ndata: %s\n\n", event.Type, data); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// authenticate reads the token from the query or else the Authorization header, like ContextHandler
+// it refuses deleted and deactivated users
+func (h *Handler) authenticate(c echo.Context) (*context.UserClaims, error) {
+	var claims *context.UserClaims
+	var err error
+	if token := strings.TrimSpace(c.QueryParam("token")); token != "" {
+		claims, err = context.NewUserClaimsFromString(token)
+	} else {
+		claims, err = context.NewUserClaims(c)
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	user, err := h.userRepo.FindOneByID(claims.IDAsObjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+		}
+		log.Errorf("Error finding user: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if user.IsDeleted || user.IsDeactivated {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+	claims.Role = user.GetRole()
+	return claims, nil
+}
+
+// projectIDs parses the projects to subscribe to, the user must be able to view each of them
+func (h *Handler) projectIDs(c echo.Context, claims *context.UserClaims) ([]bson.ObjectID, error) {
+	projectIDs := make([]bson.ObjectID, 0)
+	for _, param := range c.QueryParams()["projectId"] {
+		for _, id := range strings.Split(param, ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+			oId, err := bson.ObjectIDFromHex(id)
+			if err != nil {
+				return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+			}
+			if !slices.Contains(projectIDs, oId) {
+				projectIDs = append(projectIDs, oId)
+			}
+		}
+	}
+	if len(projectIDs) > maxProjects {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("At most %v projects can be followed at once.", maxProjects))
+	}
+
+	for _, projectID := range projectIDs {
+		project, err := h.projectRepo.FindOneByID(projectID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+			}
+			log.Errorf("Error finding project: %v", err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		if err := access.RequireViewProject(claims, project); err != nil {
+			return nil, err
+		}
+	}
+	return projectIDs, nil
+}
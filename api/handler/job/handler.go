@@ -0,0 +1,143 @@
+package job
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	_mongo "proman-backend/internal/pkg/mongo"
+	"proman-backend/internal/pkg/util"
+	"strings"
+)
+
+const defaultLimit = 50
+
+type Handler struct {
+	jobRepo *repository.JobCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		jobRepo: repository.NewJobCollRepository(db),
+	}
+
+	admin := e.Group("/api/admin", context.ContextHandler, context.AdminOnly)
+
+	admin.GET("/jobs", h.list)
+	admin.GET("/jobs/status", h.status)
+	admin.GET("/job/:id", h.detail)
+	admin.POST("/job/:id/retry", h.retry)
+
+	return h
+}
+
+// List Jobs
+// @Tags Admin
+// @Summary Get the background jobs, newest first
+// @ID admin-jobs
+// @Router /api/admin/jobs [get]
+// @Param status query string false "Job status" Enums(pending, running, done, failed)
+// @Param type query string false "Job type, like mail, due-soon, digest or cleanup"
+// @Param page query int false "Page number pagination"
+// @Param limit query int false "Limit pagination"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) list(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	if strings.TrimSpace(c.QueryParam("limit")) == "" {
+		cq.Limit = defaultLimit
+	}
+
+	jobs, err := h.jobRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding jobs: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	total, err := h.jobRepo.Count(cq)
+	if err != nil {
+		log.Errorf("Error counting jobs: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	result := _mongo.MakePaginateResult(jobs, total, cq.Page, cq.Limit)
+	return c.JSON(http.StatusOK, result)
+}
+
+// Job Status
+// @Tags Admin
+// @Summary Get how many background jobs are pending, running, done and failed
+// @ID admin-jobs-status
+// @Router /api/admin/jobs/status [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) status(c echo.Context) error {
+	counts, err := h.jobRepo.CountByStatus()
+	if err != nil {
+		log.Errorf("Error counting jobs: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, counts)
+}
+
+// Job Detail
+// @Tags Admin
+// @Summary Get a background job
+// @ID admin-job
+// @Router /api/admin/job/{id} [get]
+// @Param id path string true "Job ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) detail(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid job ID.")
+	}
+
+	job, err := h.jobRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Job not found")
+		}
+		log.Errorf("Error finding job: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// Retry Job
+// @Tags Admin
+// @Summary Run a failed background job again from its first attempt
+// @ID admin-retry-job
+// @Router /api/admin/job/{id}/retry [post]
+// @Param id path string true "Job ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) retry(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid job ID.")
+	}
+
+	found, err := h.jobRepo.Requeue(oId)
+	if err != nil {
+		log.Errorf("Error requeuing job: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !found {
+		return echo.NewHTTPError(http.StatusNotFound, "Failed job not found")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Job queued again"})
+}
@@ -8,12 +8,15 @@ import (
 	"net/http"
 	"proman-backend/api/repository"
 	"proman-backend/config"
+	"proman-backend/internal/pkg/audit"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/context"
 	"proman-backend/internal/pkg/file"
 	"proman-backend/internal/pkg/log"
 	_mongo "proman-backend/internal/pkg/mongo"
 	"proman-backend/internal/pkg/util"
+	"sort"
+	"time"
 )
 
 type Handler struct {
@@ -22,6 +25,10 @@ type Handler struct {
 	taskRepo     *repository.TaskCollRepository
 	scheduleRepo *repository.ScheduleCollRepository
 	codeRepo     *repository.CodeCollRepository
+	commentRepo  *repository.CommentCollRepository
+	calendarRepo *repository.WorkingCalendarCollRepository
+	holidayRepo  *repository.HolidayCollRepository
+	recorder     *audit.Recorder
 }
 
 func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
@@ -31,6 +38,10 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 		taskRepo:     repository.NewTaskCollRepository(db),
 		scheduleRepo: repository.NewScheduleCollRepository(db),
 		codeRepo:     repository.NewCodeCollRepository(db),
+		commentRepo:  repository.NewCommentCollRepository(db),
+		calendarRepo: repository.NewWorkingCalendarCollRepository(db),
+		holidayRepo:  repository.NewHolidayCollRepository(db),
+		recorder:     audit.NewRecorder(db),
 	}
 
 	me := e.Group("/api", context.ContextHandler)
@@ -40,6 +51,7 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	me.PUT("/me/password", h.updateMyPassword)
 
 	me.GET("/me/schedules", h.mySchedule)
+	me.GET("/me/schedule/conflicts", h.myScheduleConflicts)
 
 	me.GET("/me/projects", h.myProjects)
 	me.GET("/me/project/count", h.myProjectCount)
@@ -122,6 +134,8 @@ func (h *Handler) updateMyProfile(c echo.Context) error {
 		}
 	}
 
+	before := audit.Snapshot(user)
+
 	avatar, _ := file.GetFileThenUpload(c, "avatar", config.AWS.AvatarDir)
 	if avatar != "" {
 		user.Avatar = avatar
@@ -145,6 +159,8 @@ func (h *Handler) updateMyProfile(c echo.Context) error {
 		log.Errorf("Error updating user: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	h.recorder.Record(c, _const.EntityUser, user.ID, bson.NilObjectID, _const.ActivityUpdate, before, audit.Snapshot(user))
 	return c.JSON(http.StatusOK, doc)
 }
 
@@ -200,6 +216,9 @@ func (h *Handler) updateMyPassword(c echo.Context) error {
 		log.Errorf("Error updating user: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	// Password hashes are never part of the activity, only the fact that it changed.
+	h.recorder.RecordChanges(c, _const.EntityUser, user.ID, bson.NilObjectID, _const.ActivityUpdate, []repository.ActivityChange{{Field: "password"}})
 	return c.JSON(http.StatusOK, doc)
 }
 
@@ -246,18 +265,64 @@ func (h *Handler) mySchedule(c echo.Context) error {
 			}
 		}
 
-		response = append(response, map[string]interface{}{
-			"id":          schedule.ID,
-			"name":        schedule.Name,
-			"description": schedule.Description,
-			"start_date":  schedule.StartDate,
-			"end_date":    schedule.EndDate,
-			"start_time":  schedule.StartTime,
-			"end_time":    schedule.EndTime,
-			"contributor": contributorInList,
-			"type":        schedule.Type,
-			"created_at":  schedule.CreatedAt,
-		})
+		for _, occurrence := range schedule.Occurrences(cq.Start, cq.End) {
+			response = append(response, map[string]interface{}{
+				"id":           schedule.ID,
+				"name":         occurrence.Name,
+				"description":  occurrence.Description,
+				"start_date":   occurrence.StartDate,
+				"end_date":     occurrence.EndDate,
+				"start_time":   occurrence.StartTime,
+				"end_time":     occurrence.EndTime,
+				"contributor":  contributorInList,
+				"type":         schedule.Type,
+				"is_recurring": occurrence.IsRecurring,
+				"is_override":  occurrence.IsOverride,
+				"created_at":   schedule.CreatedAt,
+			})
+		}
+	}
+
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i]["start_date"].(time.Time).Before(response[j]["start_date"].(time.Time))
+	})
+	return c.JSON(http.StatusOK, response)
+}
+
+// My Schedule Conflicts
+// @Tags Me
+// @Summary Get my overlapping schedules
+// @ID my-schedule-conflicts
+// @Router /api/me/schedule/conflicts [get]
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) myScheduleConflicts(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	cq := util.NewCommonQuery(c)
+	cq.UserId = uc.Claims.IDAsObjectID
+
+	schedules, err := h.scheduleRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	response := make([]map[string]interface{}, 0)
+	for i := range schedules {
+		for j := i + 1; j < len(schedules); j++ {
+			if !schedules[i].Overlaps(&schedules[j]) {
+				continue
+			}
+			response = append(response, map[string]interface{}{
+				"schedule":       schedules[i],
+				"conflicts_with": schedules[j],
+			})
+		}
 	}
 	return c.JSON(http.StatusOK, response)
 }
@@ -274,6 +339,7 @@ func (h *Handler) mySchedule(c echo.Context) error {
 // @Param sort query string false "Sort" enums(asc,desc)
 // @Param page query int false "Page number pagination"
 // @Param limit query int false "Limit pagination"
+// @Param topLevel query bool false "Leave subtasks out"
 // @Accept json
 // @Produce json
 // @Success 200
@@ -372,6 +438,8 @@ func (h *Handler) myProjectCountByType(c echo.Context) error {
 // @Param projectId query string false "Search by project"
 // @Param start query string false "Start date"
 // @Param end query string false "End date"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Param sprintId query string false "Search by sprint"
 // @Accept json
 // @Produce json
 // @Success 200
@@ -390,6 +458,13 @@ func (h *Handler) myTasks(c echo.Context) error {
 		log.Errorf("Error finding task: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	if err := h.commentRepo.FillTaskCommentCount(tasks); err != nil {
+		log.Warnf("Error counting task comments: %v", err)
+	}
+	if err := h.calendarRepo.FillTaskWorkingDaysLeft(tasks, h.holidayRepo); err != nil {
+		log.Warnf("Error counting working days: %v", err)
+	}
 	return c.JSON(http.StatusOK, tasks)
 }
 
@@ -398,6 +473,8 @@ func (h *Handler) myTasks(c echo.Context) error {
 // @Summary Get my task count
 // @ID my-task-count
 // @Router /api/me/task/count [get]
+// @Param topLevel query bool false "Leave subtasks out"
+// @Param sprintId query string false "Search by sprint"
 // @Accept json
 // @Produce json
 // @Success 200
@@ -429,36 +506,25 @@ func (h *Handler) myTaskCount(c echo.Context) error {
 // @Summary Get my task overview
 // @ID my-task-overview
 // @Router /api/me/task/overview [get]
+// @Param granularity query string false "Length of a period, default week" Enums(day, week, month, quarter, year)
+// @Param periods query int false "Number of periods up to the current one, default 8, at most 366"
+// @Param projectId query string false "Search by project"
+// @Param sprintId query string false "Search by sprint"
+// @Param topLevel query bool false "Leave subtasks out"
 // @Accept json
 // @Produce json
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) myTaskOverview(c echo.Context) error {
-	doc := []repository.TaskOverview{}
 	uc := c.(*context.Context)
 
 	cq := util.NewCommonQuery(c)
 	cq.UserId = uc.Claims.IDAsObjectID
 
-	for _, v := range []int{-7, -6, -5, -4, -3, -2, -1, 0} {
-		cq.Start = util.StartOfWeek(v)
-		cq.End = util.EndOfWeek(v)
-
-		count, err := h.taskRepo.CountTask(cq)
-		if err != nil || len(count) == 0 {
-			doc = append(doc, repository.TaskOverview{
-				Start: cq.Start.Format("02 Jan"),
-				End:   cq.End.Format("02 Jan"),
-				Count: 0,
-			})
-			continue
-		}
-
-		doc = append(doc, repository.TaskOverview{
-			Start: cq.Start.Format("02 Jan"),
-			End:   cq.End.Format("02 Jan"),
-			Count: count[0].Active + count[0].Testing + count[0].Completed,
-		})
+	doc, err := h.taskRepo.Overview(cq)
+	if err != nil {
+		log.Errorf("Error counting task overview: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 	return c.JSON(http.StatusOK, doc)
 }
@@ -468,6 +534,8 @@ func (h *Handler) myTaskOverview(c echo.Context) error {
 // @Summary Get my task list by status
 // @ID my-task-list-status
 // @Router /api/me/task/status [get]
+// @Param topLevel query bool false "Leave subtasks out"
+// @Param sprintId query string false "Search by sprint"
 // @Accept json
 // @Produce json
 // @Success 200
@@ -478,38 +546,14 @@ func (h *Handler) myTaskStatus(c echo.Context) error {
 	cq := util.NewCommonQuery(c)
 	cq.UserId = uc.Claims.IDAsObjectID
 
-	docs := repository.TaskGroup{}
-
-	cq.Status = _const.TaskActive
-	active, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Active = active
-	} else {
-		log.Warnf("Error finding active task: %v", err)
-	}
-
-	cq.Status = _const.TaskTesting
-	testing, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Testing = testing
-	} else {
-		log.Warnf("Error finding testing task: %v", err)
-	}
-
-	cq.Status = _const.TaskCompleted
-	completed, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Completed = completed
-	} else {
-		log.Warnf("Error finding completed task: %v", err)
+	docs, err := h.taskRepo.FindGroup(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 
-	cq.Status = _const.TaskCancelled
-	cancelled, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Cancelled = cancelled
-	} else {
-		log.Warnf("Error finding cancelled task: %v", err)
+	if err := h.commentRepo.FillTaskGroupCommentCount(docs); err != nil {
+		log.Warnf("Error counting task comments: %v", err)
 	}
 	return c.JSON(http.StatusOK, docs)
 }
@@ -0,0 +1,174 @@
+package milestone
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"net/http"
+	"proman-backend/internal/pkg/log"
+	"strings"
+)
+
+const (
+	minNameLength        = 1
+	maxNameLength        = 100
+	maxDescriptionLength = 1000
+	noneValue            = "none" // clears the linked tasks
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type milestoneForm struct {
+	Name        string `json:"name" form:"name"`
+	Description string `json:"description" form:"description"`
+	DueDate     int64  `json:"due_date" form:"due_date"`
+	TaskIDs     string `json:"task_ids" form:"task_ids"` // comma separated task IDs of the project
+
+	taskIDs []bson.ObjectID
+}
+
+func newMilestoneForm(c echo.Context) (*milestoneForm, error) {
+	form := new(milestoneForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding milestone form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+	form.Description = strings.TrimSpace(form.Description)
+	form.TaskIDs = strings.TrimSpace(form.TaskIDs)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate name
+	if len(form.Name) < minNameLength || len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	// Validate description
+	if len(form.Description) > maxDescriptionLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "description",
+			Message: "Description must be at most 1000 characters.",
+		})
+	}
+
+	// Validate due date
+	if form.DueDate <= 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "due_date",
+			Message: "Invalid due date.",
+		})
+	}
+
+	// Validate task IDs
+	taskIDs, ok := parseTaskIDs(form.TaskIDs)
+	if !ok {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "task_ids",
+			Message: "Invalid task ID.",
+		})
+	}
+	form.taskIDs = taskIDs
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type updateMilestoneForm struct {
+	Name        string `json:"name" form:"name"`
+	Description string `json:"description" form:"description"`
+	DueDate     int64  `json:"due_date" form:"due_date"`
+	TaskIDs     string `json:"task_ids" form:"task_ids"` // comma separated task IDs, replaces the linked tasks, "none" removes them
+
+	taskIDs []bson.ObjectID
+}
+
+func newUpdateMilestoneForm(c echo.Context) (*updateMilestoneForm, error) {
+	form := new(updateMilestoneForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding update milestone form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+	form.Description = strings.TrimSpace(form.Description)
+	form.TaskIDs = strings.TrimSpace(form.TaskIDs)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate name
+	if len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	// Validate description
+	if len(form.Description) > maxDescriptionLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "description",
+			Message: "Description must be at most 1000 characters.",
+		})
+	}
+
+	// Validate due date
+	if form.DueDate < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "due_date",
+			Message: "Invalid due date.",
+		})
+	}
+
+	// Validate task IDs
+	if form.TaskIDs != noneValue {
+		taskIDs, ok := parseTaskIDs(form.TaskIDs)
+		if !ok {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "task_ids",
+				Message: "Invalid task ID.",
+			})
+		}
+		form.taskIDs = taskIDs
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+// parseTaskIDs parses comma separated task IDs without duplicates
+func parseTaskIDs(value string) ([]bson.ObjectID, bool) {
+	taskIDs := make([]bson.ObjectID, 0)
+	if value == "" {
+		return taskIDs, true
+	}
+
+	seen := map[bson.ObjectID]bool{}
+	for _, id := range strings.Split(value, ",") {
+		taskID, err := bson.ObjectIDFromHex(strings.TrimSpace(id))
+		if err != nil {
+			return nil, false
+		}
+		if !seen[taskID] {
+			seen[taskID] = true
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+	return taskIDs, true
+}
@@ -0,0 +1,398 @@
+package milestone
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/audit"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRiskDays      = 14
+	defaultRiskThreshold = 0.5
+)
+
+type Handler struct {
+	projectRepo   *repository.ProjectCollRepository
+	taskRepo      *repository.TaskCollRepository
+	milestoneRepo *repository.MilestoneCollRepository
+	recorder      *audit.Recorder
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		projectRepo:   repository.NewProjectCollRepository(db),
+		taskRepo:      repository.NewTaskCollRepository(db),
+		milestoneRepo: repository.NewMilestoneCollRepository(db),
+		recorder:      audit.NewRecorder(db),
+	}
+
+	milestone := e.Group("/api", context.ContextHandler)
+
+	milestone.GET("/project/:id/milestones", h.list)
+	milestone.GET("/project/:id/milestones/:milestoneId", h.detail)
+	milestone.GET("/milestones/at-risk", h.atRisk)
+
+	milestone.POST("/project/:id/milestones", h.create)
+
+	milestone.PUT("/project/:id/milestones/:milestoneId", h.update)
+
+	milestone.DELETE("/project/:id/milestones/:milestoneId", h.delete)
+
+	return h
+}
+
+// List Milestone
+// @Tags Milestone
+// @Summary Get the milestones of a project with their completion
+// @ID list-milestone
+// @Router /api/project/{id}/milestones [get]
+// @Param id path string true "Project ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) list(c echo.Context) error {
+	project, err := h.findProject(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	milestones, err := h.milestoneRepo.FindAllByProjectID(project.ID)
+	if err != nil {
+		log.Errorf("Error finding milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := h.milestoneRepo.FillCompletion(milestones, h.taskRepo); err != nil {
+		log.Errorf("Error finding milestone tasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, milestones)
+}
+
+// Get Milestone
+// @Tags Milestone
+// @Summary Get a milestone with its linked tasks
+// @ID get-milestone
+// @Router /api/project/{id}/milestones/{milestoneId} [get]
+// @Param id path string true "Project ID"
+// @Param milestoneId path string true "Milestone ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) detail(c echo.Context) error {
+	project, err := h.findProject(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	milestone, err := h.findMilestone(c, project)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.taskRepo.FindAllByIDs(milestone.TaskIDs)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	milestone.CalculateCompletion(repository.IndexTasks(tasks))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"milestone": milestone,
+		"tasks":     tasks,
+	})
+}
+
+// Milestones At Risk
+// @Tags Milestone
+// @Summary Get milestones due soon or overdue with a low completion
+// @ID milestone-at-risk
+// @Router /api/milestones/at-risk [get]
+// @Param projectId query string false "Search by project"
+// @Param days query int false "Due within this many days, default 14"
+// @Param threshold query number false "Completion below this ratio is at risk, default 0.5"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) atRisk(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
+
+	days := defaultRiskDays
+	if param := strings.TrimSpace(c.QueryParam("days")); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid days.")
+		}
+		days = value
+	}
+
+	threshold := defaultRiskThreshold
+	if param := strings.TrimSpace(c.QueryParam("threshold")); param != "" {
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil || value < 0 || value > 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid threshold, use a ratio between 0 and 1.")
+		}
+		threshold = value
+	}
+
+	projectIds := cq.ProjectIds
+	if !cq.ProjectId.IsZero() {
+		projectIds = []bson.ObjectID{cq.ProjectId}
+	}
+
+	milestones, err := h.milestoneRepo.FindAllDueBefore(time.Now().AddDate(0, 0, days), projectIds)
+	if err != nil {
+		log.Errorf("Error finding milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := h.milestoneRepo.FillCompletion(milestones, h.taskRepo); err != nil {
+		log.Errorf("Error finding milestone tasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	docs := make([]map[string]interface{}, 0)
+	for _, milestone := range milestones {
+		if milestone.Completion >= threshold || (milestone.TaskCount > 0 && milestone.CompletedCount == milestone.TaskCount) {
+			continue
+		}
+		docs = append(docs, map[string]interface{}{
+			"milestone":  milestone,
+			"is_overdue": milestone.DueDate.Before(time.Now()),
+			"days_left":  int(time.Until(milestone.DueDate).Hours() / 24),
+		})
+	}
+	return c.JSON(http.StatusOK, docs)
+}
+
+// Create Milestone
+// @Tags Milestone
+// @Summary Create a milestone in a project
+// @ID create-milestone
+// @Router /api/project/{id}/milestones [post]
+// @Param id path string true "Project ID"
+// @Param body body milestoneForm true "Milestone data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) create(c echo.Context) error {
+	project, err := h.findProject(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+
+	form, err := newMilestoneForm(c)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.findTasks(project, form.taskIDs)
+	if err != nil {
+		return err
+	}
+
+	milestone := repository.Milestone{
+		ID:          bson.NewObjectID(),
+		ProjectID:   project.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		DueDate:     time.UnixMilli(form.DueDate),
+		TaskIDs:     form.taskIDs,
+		CreatedAt:   time.Now(),
+		IsDeleted:   false,
+	}
+
+	if err := h.milestoneRepo.CreateOne(&milestone); err != nil {
+		log.Errorf("Failed to create milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	milestone.CalculateCompletion(repository.IndexTasks(tasks))
+
+	h.recorder.Record(c, _const.EntityMilestone, milestone.ID, project.ID, _const.ActivityCreate, nil, audit.Snapshot(&milestone))
+	return c.JSON(http.StatusOK, milestone)
+}
+
+// Update Milestone
+// @Tags Milestone
+// @Summary Update a milestone
+// @ID update-milestone
+// @Router /api/project/{id}/milestones/{milestoneId} [put]
+// @Param id path string true "Project ID"
+// @Param milestoneId path string true "Milestone ID"
+// @Param body body updateMilestoneForm true "Milestone data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) update(c echo.Context) error {
+	project, err := h.findProject(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+
+	milestone, err := h.findMilestone(c, project)
+	if err != nil {
+		return err
+	}
+
+	form, err := newUpdateMilestoneForm(c)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(milestone)
+
+	if len(form.Name) != 0 {
+		milestone.Name = form.Name
+	}
+	if len(form.Description) != 0 {
+		milestone.Description = form.Description
+	}
+	if form.DueDate != 0 {
+		milestone.DueDate = time.UnixMilli(form.DueDate)
+	}
+	if form.TaskIDs == noneValue {
+		milestone.TaskIDs = make([]bson.ObjectID, 0)
+	} else if len(form.TaskIDs) != 0 {
+		if _, err := h.findTasks(project, form.taskIDs); err != nil {
+			return err
+		}
+		milestone.TaskIDs = form.taskIDs
+	}
+
+	tasks, err := h.taskRepo.FindAllByIDs(milestone.TaskIDs)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := h.milestoneRepo.UpdateOneByID(milestone); err != nil {
+		log.Errorf("Failed to update milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	milestone.CalculateCompletion(repository.IndexTasks(tasks))
+
+	h.recorder.Record(c, _const.EntityMilestone, milestone.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(milestone))
+	return c.JSON(http.StatusOK, milestone)
+}
+
+// Delete Milestone
+// @Tags Milestone
+// @Summary Delete a milestone, its tasks are kept
+// @ID delete-milestone
+// @Router /api/project/{id}/milestones/{milestoneId} [delete]
+// @Param id path string true "Project ID"
+// @Param milestoneId path string true "Milestone ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) delete(c echo.Context) error {
+	project, err := h.findProject(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+
+	milestone, err := h.findMilestone(c, project)
+	if err != nil {
+		return err
+	}
+
+	if err := h.milestoneRepo.DeleteOneByID(milestone.ID); err != nil {
+		log.Errorf("Failed to delete milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityMilestone, milestone.ID, project.ID, _const.ActivityDelete, audit.Snapshot(milestone), nil)
+	return c.JSON(http.StatusOK, "Milestone deleted")
+}
+
+func (h *Handler) findProject(c echo.Context) (*repository.Project, error) {
+	projectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return project, nil
+}
+
+func (h *Handler) findMilestone(c echo.Context, project *repository.Project) (*repository.Milestone, error) {
+	milestoneID, err := bson.ObjectIDFromHex(c.Param("milestoneId"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid milestone ID.")
+	}
+
+	milestone, err := h.milestoneRepo.FindOneByID(milestoneID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Milestone not found")
+		}
+		log.Errorf("Error finding milestone: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if milestone.ProjectID != project.ID {
+		return nil, echo.NewHTTPError(http.StatusNotFound, "Milestone not found")
+	}
+	return milestone, nil
+}
+
+// findTasks finds the tasks to link, every task has to belong to the project
+func (h *Handler) findTasks(project *repository.Project, taskIDs []bson.ObjectID) ([]repository.Task, error) {
+	tasks, err := h.taskRepo.FindAllByIDs(taskIDs)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if len(tasks) != len(taskIDs) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Task not found.")
+	}
+	for _, task := range tasks {
+		if task.ProjectID != project.ID {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Task belongs to another project.")
+		}
+	}
+	return tasks, nil
+}
@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/log"
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type preferencesForm struct {
+	Preferences map[string]bool `json:"preferences" form:"preferences"` // notification type to whether it is sent
+}
+
+func newPreferencesForm(c echo.Context) (*preferencesForm, error) {
+	form := new(preferencesForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding preferences form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate preferences
+	if len(form.Preferences) == 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "preferences",
+			Message: "Preferences are required.",
+		})
+	}
+	for notificationType := range form.Preferences {
+		if !_const.IsValidNotificationType(notificationType) {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "preferences",
+				Message: "Invalid notification type " + notificationType + ".",
+			})
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
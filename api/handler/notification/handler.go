@@ -0,0 +1,227 @@
+package notification
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	_mongo "proman-backend/internal/pkg/mongo"
+	"proman-backend/internal/pkg/util"
+	"strings"
+)
+
+const defaultLimit = 50
+
+type Handler struct {
+	userRepo         *repository.UserCollRepository
+	notificationRepo *repository.NotificationCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:         repository.NewUserCollRepository(db),
+		notificationRepo: repository.NewNotificationCollRepository(db),
+	}
+
+	notification := e.Group("/api", context.ContextHandler)
+
+	notification.GET("/me/notifications", h.myNotifications)
+	notification.GET("/me/notifications/unread-count", h.unreadCount)
+	notification.PUT("/me/notifications/read-all", h.markAllRead)
+	notification.PUT("/me/notification/:id/read", h.markRead)
+	notification.GET("/me/notification-preferences", h.preferences)
+	notification.PUT("/me/notification-preferences", h.updatePreferences)
+
+	return h
+}
+
+// My Notifications
+// @Tags Notification
+// @Summary Get my notifications, newest first
+// @ID my-notifications
+// @Router /api/me/notifications [get]
+// @Param status query string false "Read status" Enums(read, unread)
+// @Param type query string false "Notification type" Enums(project_contributor, task_contributor, schedule_contributor, task_status, task_due)
+// @Param page query int false "Page number pagination"
+// @Param limit query int false "Limit pagination"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) myNotifications(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	cq := util.NewCommonQuery(c)
+	if strings.TrimSpace(c.QueryParam("limit")) == "" {
+		cq.Limit = defaultLimit
+	}
+
+	notifications, err := h.notificationRepo.FindAllByUserID(cq, uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error finding notifications: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	total, err := h.notificationRepo.CountByUserID(cq, uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error counting notifications: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	result := _mongo.MakePaginateResult(notifications, total, cq.Page, cq.Limit)
+	return c.JSON(http.StatusOK, result)
+}
+
+// Unread Count
+// @Tags Notification
+// @Summary Get the number of my unread notifications
+// @ID my-notifications-unread-count
+// @Router /api/me/notifications/unread-count [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) unreadCount(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	count, err := h.notificationRepo.CountUnread(uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error counting unread notifications: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"unread": count})
+}
+
+// Mark Read
+// @Tags Notification
+// @Summary Mark one of my notifications as read
+// @ID mark-notification-read
+// @Router /api/me/notification/{id}/read [put]
+// @Param id path string true "Notification ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) markRead(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid notification ID.")
+	}
+
+	found, err := h.notificationRepo.MarkRead(oId, uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error marking notification as read: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !found {
+		return echo.NewHTTPError(http.StatusNotFound, "Notification not found")
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Notification marked as read"})
+}
+
+// Mark All Read
+// @Tags Notification
+// @Summary Mark all my notifications as read
+// @ID mark-all-notifications-read
+// @Router /api/me/notifications/read-all [put]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) markAllRead(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	count, err := h.notificationRepo.MarkAllRead(uc.Claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error marking notifications as read: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, map[string]int64{"marked": count})
+}
+
+// Notification Preferences
+// @Tags Notification
+// @Summary Get which notification types I receive
+// @ID my-notification-preferences
+// @Router /api/me/notification-preferences [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) preferences(c echo.Context) error {
+	user, err := h.findMe(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, renderPreferences(user))
+}
+
+// Update Notification Preferences
+// @Tags Notification
+// @Summary Turn notification types on or off, types left out keep their setting
+// @ID update-my-notification-preferences
+// @Router /api/me/notification-preferences [put]
+// @Param body body preferencesForm true "Notification type to whether it is sent"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updatePreferences(c echo.Context) error {
+	form, err := newPreferencesForm(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.findMe(c)
+	if err != nil {
+		return err
+	}
+
+	muted := make([]string, 0)
+	for _, notificationType := range _const.GetAllNotificationTypes() {
+		enabled, ok := form.Preferences[notificationType]
+		if !ok {
+			enabled = user.WantsNotification(notificationType)
+		}
+		if !enabled {
+			muted = append(muted, notificationType)
+		}
+	}
+	user.MutedNotifications = muted
+
+	if _, err := h.userRepo.Update(user); err != nil {
+		log.Errorf("Error updating user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, renderPreferences(user))
+}
+
+func (h *Handler) findMe(c echo.Context) (*repository.User, error) {
+	uc := c.(*context.Context)
+
+	user, err := h.userRepo.FindOneByID(uc.Claims.IDAsObjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "User not found")
+		}
+		log.Errorf("Error finding user: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return user, nil
+}
+
+// renderPreferences lists every notification type with whether the user receives it
+func renderPreferences(user *repository.User) map[string]bool {
+	preferences := map[string]bool{}
+	for _, notificationType := range _const.GetAllNotificationTypes() {
+		preferences[notificationType] = user.WantsNotification(notificationType)
+	}
+	return preferences
+}
@@ -28,6 +28,8 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 		}))
 
 	option.GET("/option/type/position", h.position)
+	option.GET("/option/type/role", h.role)
+	option.GET("/option/type/project-role", h.projectRole)
 	option.GET("/option/type/project", h.projectType)
 	option.GET("/option/type/schedule", h.scheduleType)
 
@@ -50,6 +52,32 @@ func (h *Handler) position(c echo.Context) error {
 	return c.JSON(http.StatusOK, _const.GetAllPositions())
 }
 
+// Get Role
+// @Tags Option
+// @Summary Get role
+// @ID option-role
+// @Router /api/option/type/role [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security BasicAuth
+func (h *Handler) role(c echo.Context) error {
+	return c.JSON(http.StatusOK, _const.GetAllRoles())
+}
+
+// Get Project Role
+// @Tags Option
+// @Summary Get project role
+// @ID option-project-role
+// @Router /api/option/type/project-role [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security BasicAuth
+func (h *Handler) projectRole(c echo.Context) error {
+	return c.JSON(http.StatusOK, _const.GetAllProjectRoles())
+}
+
 // Get Project Type
 // @Tags Option
 // @Summary Get project type
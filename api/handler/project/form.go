@@ -1,6 +1,7 @@
 package project
 
 import (
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	_const "proman-backend/internal/pkg/const"
@@ -15,6 +16,7 @@ const (
 	maxDescriptionLength = 1000
 	minTypeLength        = 1
 	maxTypeLength        = 50
+	maxColumnNameLength  = 50
 )
 
 type errorDoc struct {
@@ -106,8 +108,20 @@ func newProjectForm(c echo.Context) (*projectForm, error) {
 	return form, nil
 }
 
-func newUpdateProjectForm(c echo.Context) (*projectForm, error) {
-	form := new(projectForm)
+type updateProjectForm struct {
+	Name              string `json:"name" form:"name"`
+	Description       string `json:"description" form:"description"`
+	Contributor       string `json:"contributor" form:"contributor"`
+	Type              string `json:"type" form:"type"`
+	Status            string `json:"status" form:"status"`
+	StartDate         int64  `json:"start_date" form:"start_date"`
+	EndDate           int64  `json:"end_date" form:"end_date"`
+	RemoveLogo        bool   `json:"remove_logo" form:"remove_logo"`
+	RemoveAttachments string `json:"remove_attachments" form:"remove_attachments"`
+}
+
+func newUpdateProjectForm(c echo.Context) (*updateProjectForm, error) {
+	form := new(updateProjectForm)
 	if err := c.Bind(form); err != nil {
 		log.Errorf("Error binding project form: %v", err)
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
@@ -117,6 +131,8 @@ func newUpdateProjectForm(c echo.Context) (*projectForm, error) {
 	form.Description = strings.TrimSpace(form.Description)
 	form.Contributor = strings.TrimSpace(form.Contributor)
 	form.Type = strings.TrimSpace(form.Type)
+	form.Status = strings.ToLower(strings.TrimSpace(form.Status))
+	form.RemoveAttachments = strings.TrimSpace(form.RemoveAttachments)
 
 	validationErrors := make([]errorDoc, 0)
 
@@ -144,6 +160,14 @@ func newUpdateProjectForm(c echo.Context) (*projectForm, error) {
 		})
 	}
 
+	// Validate status
+	if len(form.Status) != 0 && !_const.IsValidProjectStatus(form.Status) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "status",
+			Message: "Invalid project status.",
+		})
+	}
+
 	// Validate start date
 	if form.StartDate < 0 {
 		validationErrors = append(validationErrors, errorDoc{
@@ -158,7 +182,7 @@ func newUpdateProjectForm(c echo.Context) (*projectForm, error) {
 			Field:   "end_date",
 			Message: "Invalid end date.",
 		})
-	} else if form.EndDate < form.StartDate {
+	} else if form.StartDate > 0 && form.EndDate > 0 && form.EndDate <= form.StartDate {
 		validationErrors = append(validationErrors, errorDoc{
 			Field:   "end_date",
 			Message: "End date must be after the start date.",
@@ -172,3 +196,230 @@ func newUpdateProjectForm(c echo.Context) (*projectForm, error) {
 	}
 	return form, nil
 }
+
+type updateMemberForm struct {
+	Role string `json:"role" form:"role"` // owner, manager, member
+}
+
+func newUpdateMemberForm(c echo.Context) (*updateMemberForm, error) {
+	form := new(updateMemberForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding update member form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Role = strings.ToLower(strings.TrimSpace(form.Role))
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate role
+	if !_const.IsValidProjectRole(form.Role) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "role",
+			Message: "Invalid project role.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type workflowForm struct {
+	Transitions []workflowTransitionForm `json:"transitions"` // empty restores the default workflow
+}
+
+type workflowTransitionForm struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Roles     []string `json:"roles"`     // owner, manager, member
+	Positions []string `json:"positions"` // e.g. QA
+}
+
+func newWorkflowForm(c echo.Context) (*workflowForm, error) {
+	form := new(workflowForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding workflow form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	validationErrors := make([]errorDoc, 0)
+
+	seen := map[string]bool{}
+	for i := range form.Transitions {
+		transition := &form.Transitions[i]
+		field := fmt.Sprintf("transitions[%d]", i)
+
+		// Sanitize inputs
+		transition.From = strings.ToLower(strings.TrimSpace(transition.From))
+		transition.To = strings.ToLower(strings.TrimSpace(transition.To))
+		roles := make([]string, 0)
+		for _, role := range transition.Roles {
+			roles = append(roles, strings.ToLower(strings.TrimSpace(role)))
+		}
+		transition.Roles = roles
+		positions := make([]string, 0)
+		for _, position := range transition.Positions {
+			if position = strings.TrimSpace(position); position != "" {
+				positions = append(positions, position)
+			}
+		}
+		transition.Positions = positions
+
+		// Validate statuses
+		if !_const.IsValidTaskStatus(transition.From) || !_const.IsValidTaskStatus(transition.To) {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "Invalid status.",
+			})
+		} else if transition.From == transition.To {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "A transition must change the status.",
+			})
+		} else if seen[transition.From+">"+transition.To] {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "Duplicate transition.",
+			})
+		}
+		seen[transition.From+">"+transition.To] = true
+
+		// Validate roles
+		for _, role := range transition.Roles {
+			if !_const.IsValidProjectRole(role) {
+				validationErrors = append(validationErrors, errorDoc{
+					Field:   field,
+					Message: "Invalid project role.",
+				})
+				break
+			}
+		}
+
+		// Validate positions
+		for _, position := range transition.Positions {
+			if !_const.IsValidPosition(position) {
+				validationErrors = append(validationErrors, errorDoc{
+					Field:   field,
+					Message: "Invalid position.",
+				})
+				break
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type columnsForm struct {
+	Columns []columnForm `json:"columns"` // ordered, empty restores the default columns
+}
+
+type columnForm struct {
+	Key      string `json:"key"` // optional, derived from the name
+	Name     string `json:"name"`
+	Category string `json:"category"` // active, testing, completed, cancelled
+}
+
+func newColumnsForm(c echo.Context) (*columnsForm, error) {
+	form := new(columnsForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding columns form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	validationErrors := make([]errorDoc, 0)
+
+	keys := map[string]bool{}
+	categories := map[string]bool{}
+	for i := range form.Columns {
+		column := &form.Columns[i]
+		field := fmt.Sprintf("columns[%d]", i)
+
+		// Sanitize inputs
+		column.Name = strings.TrimSpace(column.Name)
+		column.Category = strings.ToLower(strings.TrimSpace(column.Category))
+		column.Key = strings.TrimSpace(column.Key)
+		if column.Key == "" {
+			column.Key = column.Name
+		}
+		column.Key = columnKey(column.Key)
+
+		// Validate name
+		if len(column.Name) < minNameLength || len(column.Name) > maxColumnNameLength {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "Name must be between 1 and 50 characters.",
+			})
+		}
+
+		// Validate key
+		if column.Key == "" {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "Invalid key.",
+			})
+		} else if keys[column.Key] {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "Duplicate key.",
+			})
+		}
+		keys[column.Key] = true
+
+		// Validate category
+		if !_const.IsValidTaskStatus(column.Category) {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   field,
+				Message: "Invalid category.",
+			})
+		}
+		categories[column.Category] = true
+	}
+
+	// Every status needs a column, otherwise its tasks would not be on the board.
+	if len(form.Columns) != 0 {
+		for _, status := range []string{_const.TaskActive, _const.TaskTesting, _const.TaskCompleted, _const.TaskCancelled} {
+			if !categories[status] {
+				validationErrors = append(validationErrors, errorDoc{
+					Field:   "columns",
+					Message: "Every status needs at least one column, " + status + " is missing.",
+				})
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+// columnKey turns a column name into a key of lowercase letters, digits and dashes
+func columnKey(name string) string {
+	var key strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && key.Len() > 0 {
+				key.WriteRune('-')
+			}
+			key.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return key.String()
+}
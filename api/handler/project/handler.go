@@ -8,25 +8,43 @@ import (
 	"net/http"
 	"proman-backend/api/repository"
 	"proman-backend/config"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/audit"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/context"
 	"proman-backend/internal/pkg/file"
 	"proman-backend/internal/pkg/log"
 	_mongo "proman-backend/internal/pkg/mongo"
+	"proman-backend/internal/pkg/notify"
 	"proman-backend/internal/pkg/util"
+	"slices"
 	"strings"
 	"time"
 )
 
 type Handler struct {
-	projectRepo *repository.ProjectCollRepository
-	taskRepo    *repository.TaskCollRepository
+	projectRepo   *repository.ProjectCollRepository
+	taskRepo      *repository.TaskCollRepository
+	milestoneRepo *repository.MilestoneCollRepository
+	timeEntryRepo *repository.TimeEntryCollRepository
+	sprintRepo    *repository.SprintCollRepository
+	calendarRepo  *repository.WorkingCalendarCollRepository
+	holidayRepo   *repository.HolidayCollRepository
+	recorder      *audit.Recorder
+	notifier      *notify.Notifier
 }
 
 func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	h := &Handler{
-		projectRepo: repository.NewProjectCollRepository(db),
-		taskRepo:    repository.NewTaskCollRepository(db),
+		projectRepo:   repository.NewProjectCollRepository(db),
+		taskRepo:      repository.NewTaskCollRepository(db),
+		milestoneRepo: repository.NewMilestoneCollRepository(db),
+		timeEntryRepo: repository.NewTimeEntryCollRepository(db),
+		sprintRepo:    repository.NewSprintCollRepository(db),
+		calendarRepo:  repository.NewWorkingCalendarCollRepository(db),
+		holidayRepo:   repository.NewHolidayCollRepository(db),
+		recorder:      audit.NewRecorder(db),
+		notifier:      notify.NewNotifier(db),
 	}
 
 	project := e.Group("/api", context.ContextHandler)
@@ -36,9 +54,16 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 
 	project.GET("/project/count", h.count)
 	project.GET("/project/count/type", h.countByType)
+	project.GET("/project/:id/workflow", h.workflow)
+	project.GET("/project/:id/columns", h.columns)
 
 	project.POST("/project", h.create)
 
+	project.PUT("/project/:id", h.update)
+	project.PUT("/project/:id/member/:userId", h.updateMember)
+	project.PUT("/project/:id/workflow", h.updateWorkflow)
+	project.PUT("/project/:id/columns", h.updateColumns)
+
 	project.DELETE("/project/:id", h.delete)
 
 	return h
@@ -56,12 +81,18 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 // @Param sort query string false "Sort" enums(asc,desc)
 // @Param page query int false "Page number pagination"
 // @Param limit query int false "Limit pagination"
+// @Param topLevel query bool false "Leave subtasks out"
 // @Accept json
 // @Produce json
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) list(c echo.Context) error {
+	uc := c.(*context.Context)
+
 	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(uc.Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
 
 	limit := cq.Limit
 	page := cq.Page
@@ -85,6 +116,10 @@ func (h *Handler) list(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 
+	if err := h.calendarRepo.FillProjectWorkingDaysLeft(projects, h.holidayRepo); err != nil {
+		log.Warnf("Error counting working days: %v", err)
+	}
+
 	result := _mongo.MakePaginateResult(projects, int64(totalProjects.Total), page, limit)
 	return c.JSON(http.StatusOK, result)
 }
@@ -97,6 +132,7 @@ func (h *Handler) list(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Project ID"
+// @Param topLevel query bool false "Leave subtasks out of task_count"
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) detail(c echo.Context) error {
@@ -107,7 +143,7 @@ func (h *Handler) detail(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
 	}
 
-	project, err := h.projectRepo.FindOneByID(oId)
+	project, err := h.projectRepo.FindOneByIDQuery(oId, util.NewCommonQuery(c))
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
@@ -115,7 +151,41 @@ func (h *Handler) detail(c echo.Context) error {
 		log.Errorf("Error finding project: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
-	return c.JSON(http.StatusOK, project)
+
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	project.Milestones, err = h.milestoneRepo.FindAllByProjectID(project.ID)
+	if err != nil {
+		log.Errorf("Error finding milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if err := h.milestoneRepo.FillCompletion(project.Milestones, h.taskRepo); err != nil {
+		log.Errorf("Error finding milestone tasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	cq := util.NilCommonQuery()
+	cq.ProjectId = project.ID
+	tasks, err := h.taskRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	logged, err := h.timeEntryRepo.SumDurationByTask(project.ID)
+	if err != nil {
+		log.Errorf("Error summing time entries: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	effort := repository.CalculateEffort(tasks, logged)
+	project.Effort = &effort
+
+	projects := []repository.Project{*project}
+	if err := h.calendarRepo.FillProjectWorkingDaysLeft(projects, h.holidayRepo); err != nil {
+		log.Warnf("Error counting working days: %v", err)
+	}
+	return c.JSON(http.StatusOK, projects[0])
 }
 
 // Count Project
@@ -128,7 +198,12 @@ func (h *Handler) detail(c echo.Context) error {
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) count(c echo.Context) error {
+	uc := c.(*context.Context)
+
 	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(uc.Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
 	count, err := h.projectRepo.CountProject(cq)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -150,7 +225,12 @@ func (h *Handler) count(c echo.Context) error {
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) countByType(c echo.Context) error {
+	uc := c.(*context.Context)
+
 	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(uc.Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
 	count, err := h.projectRepo.CountProjectTypes(cq)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -222,6 +302,9 @@ func (h *Handler) create(c echo.Context) error {
 		StartDate:   time.UnixMilli(form.StartDate),
 		EndDate:     time.UnixMilli(form.EndDate),
 		Contributor: contributorsOId,
+		Members: []repository.ProjectMember{
+			{UserID: tokenData.Claims.IDAsObjectID, Role: _const.ProjectRoleOwner},
+		},
 		Status:      _const.ProjectActive,
 		Attachments: attachments,
 		Logo:        logo,
@@ -234,9 +317,411 @@ func (h *Handler) create(c echo.Context) error {
 		log.Errorf("Failed to create project: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	h.recorder.Record(c, _const.EntityProject, project.ID, project.ID, _const.ActivityCreate, nil, audit.Snapshot(&project))
+	h.notifier.Contributors(c, _const.EntityProject, project.ID, project.ID, project.Name, nil, project.Contributor)
 	return c.JSON(http.StatusOK, doc)
 }
 
+// Update Project
+// @Tags Project
+// @Summary Update project by id
+// @ID update-project
+// @Router /api/project/{id} [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param name formData string false "Project name"
+// @Param description formData string false "Project description"
+// @Param start_date formData int false "Project start date"
+// @Param end_date formData int false "Project end date"
+// @Param contributor formData string false "Project contributor"
+// @Param type formData string false "Project type" Enums(frontend, backend, mobile, desktop, monitor, tool, etc)
+// @Param status formData string false "Project status" Enums(active, completed, pending, cancelled)
+// @Param logo formData file false "Replace project logo"
+// @Param remove_logo formData bool false "Remove project logo"
+// @Param attachments formData file false "Add project attachments"
+// @Param remove_attachments formData string false "Comma separated attachment keys or urls to remove"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) update(c echo.Context) error {
+	id := c.Param("id")
+
+	oId, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	form, err := newUpdateProjectForm(c)
+	if err != nil {
+		return err
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	before := audit.Snapshot(project)
+	contributorsBefore := project.Contributor
+
+	contributorsOId := make([]bson.ObjectID, 0)
+	if len(form.Contributor) != 0 {
+		for _, user := range strings.Split(form.Contributor, ",") {
+			userOId, err := bson.ObjectIDFromHex(strings.TrimSpace(user))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid contributor.")
+			}
+			contributorsOId = append(contributorsOId, userOId)
+		}
+	}
+
+	// Resolve the attachments to remove before uploading anything, so a bad
+	// request does not leave orphaned objects behind.
+	keptAttachments, removedFiles, err := file.SplitAttachments(project.Attachments, form.RemoveAttachments)
+	if err != nil {
+		return err
+	}
+
+	if len(form.Name) != 0 {
+		project.Name = form.Name
+	}
+	if len(form.Description) != 0 {
+		project.Description = form.Description
+	}
+	if len(form.Type) != 0 {
+		project.Type = form.Type
+	}
+	if len(form.Status) != 0 {
+		project.Status = form.Status
+	}
+	if form.StartDate != 0 {
+		project.StartDate = time.UnixMilli(form.StartDate)
+	}
+	if form.EndDate != 0 {
+		project.EndDate = time.UnixMilli(form.EndDate)
+	}
+	if !project.EndDate.After(project.StartDate) {
+		return echo.NewHTTPError(http.StatusBadRequest, "End date must be after the start date.")
+	}
+	if len(form.Contributor) != 0 {
+		if owner := project.Owner(); owner != bson.NilObjectID && !slices.Contains(contributorsOId, owner) {
+			return echo.NewHTTPError(http.StatusBadRequest, "The project owner cannot be removed from the contributors.")
+		}
+		project.Contributor = contributorsOId
+
+		// Roles of removed contributors are dropped, they start as members when added back.
+		members := make([]repository.ProjectMember, 0)
+		for _, member := range project.Members {
+			if slices.Contains(project.Contributor, member.UserID) {
+				members = append(members, member)
+			}
+		}
+		project.Members = members
+	}
+
+	logo, err := file.GetFileThenUpload(c, "logo", config.AWS.ProjectLogoDir)
+	if err != nil && !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		log.Errorf("Failed to upload logo: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if logo != "" {
+		removedFiles = append(removedFiles, project.Logo)
+		project.Logo = logo
+	} else if form.RemoveLogo {
+		removedFiles = append(removedFiles, project.Logo)
+		project.Logo = ""
+	}
+
+	attachments, err := file.GetFilesThenUpload(c, "attachments", config.AWS.FileDir)
+	if err != nil && !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		log.Errorf("Failed to upload attachments: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	project.Attachments = append(keptAttachments, attachments...)
+
+	err = h.projectRepo.UpdateOneByID(project)
+	if err != nil {
+		log.Errorf("Failed to update project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	file.DeleteMany(removedFiles)
+
+	doc, err := h.projectRepo.FindOneByID(project.ID)
+	if err != nil {
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityProject, project.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(doc))
+	h.notifier.Contributors(c, _const.EntityProject, doc.ID, doc.ID, doc.Name, contributorsBefore, doc.Contributor)
+	return c.JSON(http.StatusOK, doc)
+}
+
+// Update Project Member
+// @Tags Project
+// @Summary Change the project role of a contributor
+// @Description Only the owner can change roles, giving the owner role to another contributor transfers ownership.
+// @ID update-project-member
+// @Router /api/project/{id}/member/{userId} [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param userId path string true "User ID"
+// @Param body body updateMemberForm true "Update member json"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateMember(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	userOId, err := bson.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID.")
+	}
+
+	form, err := newUpdateMemberForm(c)
+	if err != nil {
+		return err
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireProjectRole(uc.Claims, project, _const.ProjectRoleOwner); err != nil {
+		return err
+	}
+
+	if !slices.Contains(project.Contributor, userOId) {
+		return echo.NewHTTPError(http.StatusBadRequest, "User is not a contributor of this project.")
+	}
+	before := audit.Snapshot(project)
+
+	owner := project.Owner()
+	if userOId == owner && form.Role != _const.ProjectRoleOwner {
+		return echo.NewHTTPError(http.StatusBadRequest, "Transfer the ownership to another contributor first.")
+	}
+	if form.Role == _const.ProjectRoleOwner && owner != bson.NilObjectID && owner != userOId {
+		// The previous owner keeps managing the project.
+		project.SetMemberRole(owner, _const.ProjectRoleManager)
+	}
+	project.SetMemberRole(userOId, form.Role)
+
+	if err := h.projectRepo.UpdateMembers(project.ID, project.Members); err != nil {
+		log.Errorf("Failed to update project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityProject, project.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(project))
+	return c.JSON(http.StatusOK, project)
+}
+
+// Project Workflow
+// @Tags Project
+// @Summary Get the task status transitions allowed in the project
+// @ID project-workflow
+// @Router /api/project/{id}/workflow [get]
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) workflow(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"is_default":  len(project.Workflow) == 0,
+		"transitions": project.EffectiveWorkflow(),
+	})
+}
+
+// Update Project Workflow
+// @Tags Project
+// @Summary Replace the task status transitions allowed in the project
+// @Description Transitions without roles and positions are open to every member. Sending no transitions restores the default workflow.
+// @ID update-project-workflow
+// @Router /api/project/{id}/workflow [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param body body workflowForm true "Workflow json"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateWorkflow(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	form, err := newWorkflowForm(c)
+	if err != nil {
+		return err
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	before := audit.Snapshot(project)
+
+	workflow := make([]repository.WorkflowTransition, 0)
+	for _, transition := range form.Transitions {
+		workflow = append(workflow, repository.WorkflowTransition{
+			From:      transition.From,
+			To:        transition.To,
+			Roles:     transition.Roles,
+			Positions: transition.Positions,
+		})
+	}
+	project.Workflow = workflow
+
+	if err := h.projectRepo.UpdateWorkflow(project.ID, project.Workflow); err != nil {
+		log.Errorf("Failed to update project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityProject, project.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(project))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"is_default":  len(project.Workflow) == 0,
+		"transitions": project.EffectiveWorkflow(),
+	})
+}
+
+// Project Columns
+// @Tags Project
+// @Summary Get the board columns of the project
+// @ID project-columns
+// @Router /api/project/{id}/columns [get]
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) columns(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"is_default": len(project.Columns) == 0,
+		"columns":    project.EffectiveColumns(),
+	})
+}
+
+// Update Project Columns
+// @Tags Project
+// @Summary Replace the board columns of the project
+// @Description Columns are ordered and map to a task status, every status needs at least one column.
+// @Description Tasks of a removed column move to the first column of their status. Sending no columns restores the default columns.
+// @ID update-project-columns
+// @Router /api/project/{id}/columns [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Project ID"
+// @Param body body columnsForm true "Columns json"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateColumns(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	form, err := newColumnsForm(c)
+	if err != nil {
+		return err
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	before := audit.Snapshot(project)
+
+	columns := make([]repository.BoardColumn, 0)
+	for _, column := range form.Columns {
+		columns = append(columns, repository.BoardColumn{
+			Key:      column.Key,
+			Name:     column.Name,
+			Category: column.Category,
+		})
+	}
+	project.Columns = columns
+
+	if err := h.projectRepo.UpdateColumns(project.ID, project.Columns); err != nil {
+		log.Errorf("Failed to update project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityProject, project.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(project))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"is_default": len(project.Columns) == 0,
+		"columns":    project.EffectiveColumns(),
+	})
+}
+
 // Delete Project
 // @Tags Project
 // @Summary Delete project by id
@@ -264,6 +749,10 @@ func (h *Handler) delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleOwner); err != nil {
+		return err
+	}
+
 	err = h.projectRepo.DeleteOneByID(project.ID)
 	if err != nil {
 		log.Errorf("Failed to delete project: %v", err)
@@ -275,5 +764,19 @@ func (h *Handler) delete(c echo.Context) error {
 		log.Errorf("Failed to delete project: %v", err)
 		return echo.NewHTTPError(http.StatusBadRequest, "There was an error, please try again")
 	}
+
+	err = h.milestoneRepo.DeleteAllByProjectID(project.ID)
+	if err != nil {
+		log.Errorf("Failed to delete project: %v", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "There was an error, please try again")
+	}
+
+	err = h.sprintRepo.DeleteAllByProjectID(project.ID)
+	if err != nil {
+		log.Errorf("Failed to delete project: %v", err)
+		return echo.NewHTTPError(http.StatusBadRequest, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityProject, project.ID, project.ID, _const.ActivityDelete, audit.Snapshot(project), nil)
 	return c.JSON(http.StatusOK, "Project deleted.")
 }
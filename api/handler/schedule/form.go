@@ -3,8 +3,10 @@ package schedule
 import (
 	"github.com/labstack/echo/v4"
 	"net/http"
+	"proman-backend/api/repository"
 	_const "proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/log"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -30,6 +32,19 @@ type scheduleForm struct {
 	EndTime     string `json:"end_time" form:"end_time"`
 	Contributor string `json:"contributor" form:"contributor"`
 	Type        string `json:"type" form:"type"`
+	ProjectID   string `json:"project_id" form:"project_id"` // optional
+	// IgnoreConflict saves the schedule even when a contributor is already booked
+	IgnoreConflict bool `json:"ignore_conflict" form:"ignore_conflict"`
+	// Recurrence, leave the frequency empty for a one-off schedule
+	RecurrenceFrequency string `json:"recurrence_frequency" form:"recurrence_frequency"` // daily, weekly, monthly
+	RecurrenceInterval  int    `json:"recurrence_interval" form:"recurrence_interval"`
+	RecurrenceWeekdays  string `json:"recurrence_weekdays" form:"recurrence_weekdays"` // comma separated, 0 (Sunday) - 6 (Saturday)
+	RecurrenceUntil     int64  `json:"recurrence_until" form:"recurrence_until"`
+	RecurrenceCount     int    `json:"recurrence_count" form:"recurrence_count"`
+	ExceptionDates      string `json:"exception_dates" form:"exception_dates"` // comma separated unix milli
+
+	recurrence     *repository.ScheduleRecurrence
+	exceptionDates []time.Time
 }
 
 func newScheduleForm(c echo.Context) (*scheduleForm, error) {
@@ -44,8 +59,10 @@ func newScheduleForm(c echo.Context) (*scheduleForm, error) {
 	form.Description = strings.TrimSpace(form.Description)
 	form.Contributor = strings.TrimSpace(form.Contributor)
 	form.Type = strings.TrimSpace(form.Type)
+	form.ProjectID = strings.TrimSpace(form.ProjectID)
 	form.StartTime = strings.TrimSpace(form.StartTime)
 	form.EndTime = strings.TrimSpace(form.EndTime)
+	form.RecurrenceFrequency = strings.ToLower(strings.TrimSpace(form.RecurrenceFrequency))
 
 	validationErrors := make([]errorDoc, 0)
 
@@ -133,6 +150,300 @@ func newScheduleForm(c echo.Context) (*scheduleForm, error) {
 		})
 	}
 
+	// Validate recurrence
+	if len(form.RecurrenceFrequency) != 0 {
+		form.recurrence, validationErrors = parseRecurrence(form.RecurrenceFrequency, form.RecurrenceInterval, form.RecurrenceWeekdays, form.RecurrenceUntil, form.RecurrenceCount, form.StartDate, validationErrors)
+	}
+	form.exceptionDates, validationErrors = parseExceptionDates(form.ExceptionDates, validationErrors)
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type updateScheduleForm struct {
+	Name           string `json:"name" form:"name"`
+	Description    string `json:"description" form:"description"`
+	StartDate      int64  `json:"start_date" form:"start_date"`
+	EndDate        int64  `json:"end_date" form:"end_date"`
+	StartTime      string `json:"start_time" form:"start_time"`
+	EndTime        string `json:"end_time" form:"end_time"`
+	Contributor    string `json:"contributor" form:"contributor"`
+	Type           string `json:"type" form:"type"`
+	ProjectID      string `json:"project_id" form:"project_id"`
+	IgnoreConflict bool   `json:"ignore_conflict" form:"ignore_conflict"`
+	// Recurrence, use "none" as frequency to turn the schedule into a one-off
+	RecurrenceFrequency string `json:"recurrence_frequency" form:"recurrence_frequency"` // daily, weekly, monthly
+	RecurrenceInterval  int    `json:"recurrence_interval" form:"recurrence_interval"`
+	RecurrenceWeekdays  string `json:"recurrence_weekdays" form:"recurrence_weekdays"` // comma separated, 0 (Sunday) - 6 (Saturday)
+	RecurrenceUntil     int64  `json:"recurrence_until" form:"recurrence_until"`
+	RecurrenceCount     int    `json:"recurrence_count" form:"recurrence_count"`
+	ExceptionDates      string `json:"exception_dates" form:"exception_dates"` // comma separated unix milli
+
+	recurrence     *repository.ScheduleRecurrence
+	exceptionDates []time.Time
+}
+
+func newUpdateScheduleForm(c echo.Context) (*updateScheduleForm, error) {
+	form := new(updateScheduleForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding update schedule form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+	form.Description = strings.TrimSpace(form.Description)
+	form.Contributor = strings.TrimSpace(form.Contributor)
+	form.Type = strings.TrimSpace(form.Type)
+	form.ProjectID = strings.TrimSpace(form.ProjectID)
+	form.StartTime = strings.TrimSpace(form.StartTime)
+	form.EndTime = strings.TrimSpace(form.EndTime)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate name
+	if len(form.Name) != 0 && (len(form.Name) < minNameLength || len(form.Name) > maxNameLength) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	// Validate description
+	if len(form.Description) != 0 && (len(form.Description) < minDescriptionLength || len(form.Description) > maxDescriptionLength) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "description",
+			Message: "Description must be between 10 and 1000 characters.",
+		})
+	}
+
+	// Validate type
+	if len(form.Type) != 0 && !_const.IsValidScheduleType(form.Type) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "type",
+			Message: "Invalid schedule type.",
+		})
+	}
+
+	// Validate start date
+	if form.StartDate < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "start_date",
+			Message: "Invalid start date.",
+		})
+	}
+
+	// Validate end date
+	if form.EndDate < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "end_date",
+			Message: "Invalid end date.",
+		})
+	}
+
+	// Validate start time
+	if len(form.StartTime) != 0 {
+		if _, err := time.Parse("15:04", form.StartTime); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "start_time",
+				Message: "Invalid start time format.",
+			})
+		}
+	}
+
+	// Validate end time
+	if len(form.EndTime) != 0 {
+		if _, err := time.Parse("15:04", form.EndTime); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "end_time",
+				Message: "Invalid end time format.",
+			})
+		}
+	}
+
+	// Validate recurrence
+	form.RecurrenceFrequency = strings.ToLower(strings.TrimSpace(form.RecurrenceFrequency))
+	if len(form.RecurrenceFrequency) != 0 && form.RecurrenceFrequency != _const.RecurrenceNone {
+		form.recurrence, validationErrors = parseRecurrence(form.RecurrenceFrequency, form.RecurrenceInterval, form.RecurrenceWeekdays, form.RecurrenceUntil, form.RecurrenceCount, form.StartDate, validationErrors)
+	}
+	form.exceptionDates, validationErrors = parseExceptionDates(form.ExceptionDates, validationErrors)
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+// parseRecurrence validates the recurrence fields shared by the create and update forms.
+// startDate is only used to check the until date and may be zero when it is not changed.
+func parseRecurrence(frequency string, interval int, weekdays string, until int64, count int, startDate int64, validationErrors []errorDoc) (*repository.ScheduleRecurrence, []errorDoc) {
+	recurrence := &repository.ScheduleRecurrence{
+		Frequency: frequency,
+		Interval:  interval,
+		Weekdays:  make([]int, 0),
+		Count:     count,
+	}
+
+	if !_const.IsValidRecurrenceFrequency(frequency) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "recurrence_frequency",
+			Message: "Invalid recurrence frequency.",
+		})
+	}
+
+	if interval == 0 {
+		recurrence.Interval = 1
+	} else if interval < 0 || interval > 365 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "recurrence_interval",
+			Message: "Recurrence interval must be between 1 and 365.",
+		})
+	}
+
+	weekdays = strings.TrimSpace(weekdays)
+	if len(weekdays) != 0 {
+		if frequency != _const.RecurrenceWeekly {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "recurrence_weekdays",
+				Message: "Weekdays can only be used with a weekly recurrence.",
+			})
+		}
+		for _, weekday := range strings.Split(weekdays, ",") {
+			day, err := strconv.Atoi(strings.TrimSpace(weekday))
+			if err != nil || day < 0 || day > 6 {
+				validationErrors = append(validationErrors, errorDoc{
+					Field:   "recurrence_weekdays",
+					Message: "Weekdays must be numbers between 0 (Sunday) and 6 (Saturday).",
+				})
+				break
+			}
+			recurrence.Weekdays = append(recurrence.Weekdays, day)
+		}
+	}
+
+	if until != 0 && count != 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "recurrence_count",
+			Message: "Recurrence count cannot be used together with an until date.",
+		})
+	}
+
+	if until < 0 || (until > 0 && until < startDate) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "recurrence_until",
+			Message: "Recurrence until date must be after the start date.",
+		})
+	} else if until > 0 {
+		t := time.UnixMilli(until)
+		recurrence.Until = &t
+	}
+
+	if count < 0 || count > 1000 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "recurrence_count",
+			Message: "Recurrence count must be between 1 and 1000.",
+		})
+	}
+	return recurrence, validationErrors
+}
+
+func parseExceptionDates(dates string, validationErrors []errorDoc) ([]time.Time, []errorDoc) {
+	exceptionDates := make([]time.Time, 0)
+
+	dates = strings.TrimSpace(dates)
+	if len(dates) == 0 {
+		return exceptionDates, validationErrors
+	}
+
+	for _, date := range strings.Split(dates, ",") {
+		milli, err := strconv.ParseInt(strings.TrimSpace(date), 10, 64)
+		if err != nil || milli <= 0 {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "exception_dates",
+				Message: "Invalid exception date.",
+			})
+			break
+		}
+		exceptionDates = append(exceptionDates, time.UnixMilli(milli))
+	}
+	return exceptionDates, validationErrors
+}
+
+type occurrenceForm struct {
+	Date        int64  `json:"date" form:"date"` // original date of the occurrence
+	Name        string `json:"name" form:"name"`
+	Description string `json:"description" form:"description"`
+	StartTime   string `json:"start_time" form:"start_time"`
+	EndTime     string `json:"end_time" form:"end_time"`
+	// Cancelled removes the occurrence from the series instead of overriding it
+	Cancelled bool `json:"cancelled" form:"cancelled"`
+}
+
+func newOccurrenceForm(c echo.Context) (*occurrenceForm, error) {
+	form := new(occurrenceForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding occurrence form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+	form.Description = strings.TrimSpace(form.Description)
+	form.StartTime = strings.TrimSpace(form.StartTime)
+	form.EndTime = strings.TrimSpace(form.EndTime)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate date
+	if form.Date <= 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "date",
+			Message: "Invalid occurrence date.",
+		})
+	}
+
+	// Validate name
+	if len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	// Validate description
+	if len(form.Description) != 0 && (len(form.Description) < minDescriptionLength || len(form.Description) > maxDescriptionLength) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "description",
+			Message: "Description must be between 10 and 1000 characters.",
+		})
+	}
+
+	// Validate start time
+	if len(form.StartTime) != 0 {
+		if _, err := time.Parse("15:04", form.StartTime); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "start_time",
+				Message: "Invalid start time format.",
+			})
+		}
+	}
+
+	// Validate end time
+	if len(form.EndTime) != 0 {
+		if _, err := time.Parse("15:04", form.EndTime); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "end_time",
+				Message: "Invalid end time format.",
+			})
+		}
+	}
+
 	if len(validationErrors) > 0 {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
 			"errors": validationErrors,
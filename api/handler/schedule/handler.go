@@ -8,30 +8,50 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"net/http"
 	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/audit"
+	_const "proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/context"
 	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/notify"
 	"proman-backend/internal/pkg/util"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type Handler struct {
 	userRepo     *repository.UserCollRepository
+	projectRepo  *repository.ProjectCollRepository
 	scheduleRepo *repository.ScheduleCollRepository
+	recorder     *audit.Recorder
+	notifier     *notify.Notifier
 }
 
 func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	h := &Handler{
 		userRepo:     repository.NewUserCollRepository(db),
+		projectRepo:  repository.NewProjectCollRepository(db),
 		scheduleRepo: repository.NewScheduleCollRepository(db),
+		recorder:     audit.NewRecorder(db),
+		notifier:     notify.NewNotifier(db),
 	}
 
 	schedule := e.Group("/api", context.ContextHandler)
 
 	schedule.GET("/schedules", h.list)
+	schedule.GET("/schedule/:id", h.detail)
 
 	schedule.POST("/schedule", h.create)
 
+	schedule.PUT("/schedule/:id", h.update)
+	schedule.PUT("/schedule/:id/occurrence", h.updateOccurrence)
+
+	schedule.DELETE("/schedule/:id", h.delete)
+	schedule.DELETE("/schedule/:id/occurrence/:date", h.deleteOccurrence)
+
 	return h
 }
 
@@ -42,7 +62,8 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 // @Router /api/schedules [get]
 // @Param q query string false "Search by name"
 // @Param type query string false "Search by type" Enums(all, meeting, discussion, review, presentation, etc)
-// @Param start query string false "Start date"
+// @Param projectId query string false "Search by project"
+// @Param start query string false "Start date, recurring schedules are expanded into occurrences within the window"
 // @Param end query string false "End date"
 // @Accept json
 // @Produce json
@@ -50,6 +71,9 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 // @Security ApiKeyAuth
 func (h *Handler) list(c echo.Context) error {
 	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
 
 	schedules, err := h.scheduleRepo.FindAll(cq)
 	if err != nil {
@@ -75,22 +99,61 @@ func (h *Handler) list(c echo.Context) error {
 			}
 		}
 
-		response = append(response, map[string]interface{}{
-			"id":          schedule.ID,
-			"name":        schedule.Name,
-			"description": schedule.Description,
-			"start_date":  schedule.StartDate,
-			"end_date":    schedule.EndDate,
-			"start_time":  schedule.StartTime,
-			"end_time":    schedule.EndTime,
-			"contributor": contributorInList,
-			"type":        schedule.Type,
-			"created_at":  schedule.CreatedAt,
-		})
+		for _, occurrence := range schedule.Occurrences(cq.Start, cq.End) {
+			response = append(response, map[string]interface{}{
+				"id":           schedule.ID,
+				"name":         occurrence.Name,
+				"description":  occurrence.Description,
+				"start_date":   occurrence.StartDate,
+				"end_date":     occurrence.EndDate,
+				"start_time":   occurrence.StartTime,
+				"end_time":     occurrence.EndTime,
+				"contributor":  contributorInList,
+				"type":         schedule.Type,
+				"is_recurring": occurrence.IsRecurring,
+				"is_override":  occurrence.IsOverride,
+				"created_at":   schedule.CreatedAt,
+			})
+		}
 	}
+
+	// Occurrences of recurring schedules are interleaved with one-off schedules
+	sort.SliceStable(response, func(i, j int) bool {
+		return response[i]["start_date"].(time.Time).Before(response[j]["start_date"].(time.Time))
+	})
 	return c.JSON(http.StatusOK, response)
 }
 
+// Get Schedule
+// @Tags Schedule
+// @Summary Get schedule by id
+// @ID get-schedule
+// @Router /api/schedule/{id} [get]
+// @Param id path string true "Schedule ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) detail(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	schedule, err := h.findSchedule(c)
+	if err != nil {
+		return err
+	}
+
+	if !schedule.ProjectID.IsZero() && !slices.Contains(schedule.Contributor, uc.Claims.IDAsObjectID) {
+		project, err := h.findProject(schedule.ProjectID)
+		if err != nil {
+			return err
+		}
+		if err := access.RequireViewProject(uc.Claims, project); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, schedule)
+}
+
 // Create Schedule
 // @Tags Schedule
 // @Summary Create schedule
@@ -100,6 +163,7 @@ func (h *Handler) list(c echo.Context) error {
 // @Produce json
 // @Param body body scheduleForm true "Schedule data"
 // @Success 200
+// @Failure 409 "A contributor already has an overlapping schedule"
 // @Security ApiKeyAuth
 func (h *Handler) create(c echo.Context) error {
 	form, err := newScheduleForm(c)
@@ -116,23 +180,421 @@ func (h *Handler) create(c echo.Context) error {
 		contributorsOId = append(contributorsOId, userOId)
 	}
 
+	projectOId := bson.NilObjectID
+	if len(form.ProjectID) != 0 {
+		projectOId, err = h.findProjectID(c, form.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+
 	schedule := &repository.Schedule{
-		ID:          bson.NewObjectID(),
-		Name:        form.Name,
-		Description: form.Description,
-		StartDate:   time.UnixMilli(form.StartDate),
-		EndDate:     time.UnixMilli(form.EndDate),
-		StartTime:   form.StartTime,
-		EndTime:     form.EndTime,
-		Contributor: contributorsOId,
-		Type:        form.Type,
-		CreatedAt:   time.Now(),
-		IsDeleted:   false,
+		ID:             bson.NewObjectID(),
+		Name:           form.Name,
+		Description:    form.Description,
+		StartDate:      time.UnixMilli(form.StartDate),
+		EndDate:        time.UnixMilli(form.EndDate),
+		StartTime:      form.StartTime,
+		EndTime:        form.EndTime,
+		Contributor:    contributorsOId,
+		Type:           form.Type,
+		ProjectID:      projectOId,
+		Recurrence:     form.recurrence,
+		ExceptionDates: form.exceptionDates,
+		Overrides:      make([]repository.ScheduleOverride, 0),
+		CreatedAt:      time.Now(),
+		IsDeleted:      false,
+	}
+
+	if !form.IgnoreConflict {
+		if err := h.checkConflicts(schedule); err != nil {
+			return err
+		}
 	}
 
 	if err := h.scheduleRepo.CreateOne(schedule); err != nil {
 		log.Errorf("Failed to create schedule: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	h.recorder.Record(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, _const.ActivityCreate, nil, audit.Snapshot(schedule))
+	h.notifier.Contributors(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, schedule.Name, nil, schedule.Contributor)
 	return c.JSON(http.StatusCreated, schedule)
 }
+
+// Update Schedule
+// @Tags Schedule
+// @Summary Update schedule
+// @ID schedule-update
+// @Router /api/schedule/{id} [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param body body updateScheduleForm true "Update schedule data"
+// @Success 200
+// @Failure 409 "A contributor already has an overlapping schedule"
+// @Security ApiKeyAuth
+func (h *Handler) update(c echo.Context) error {
+	schedule, err := h.findModifiableSchedule(c)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(schedule)
+	contributorsBefore := schedule.Contributor
+
+	form, err := newUpdateScheduleForm(c)
+	if err != nil {
+		return err
+	}
+
+	contributorsOId := make([]bson.ObjectID, 0)
+	if len(form.Contributor) != 0 {
+		for _, user := range strings.Split(form.Contributor, ",") {
+			userOId, err := bson.ObjectIDFromHex(user)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid contributor ID")
+			}
+			contributorsOId = append(contributorsOId, userOId)
+		}
+	}
+
+	if len(form.Name) != 0 {
+		schedule.Name = form.Name
+	}
+	if len(form.Description) != 0 {
+		schedule.Description = form.Description
+	}
+	if len(form.Type) != 0 {
+		schedule.Type = form.Type
+	}
+	if form.StartDate != 0 {
+		schedule.StartDate = time.UnixMilli(form.StartDate)
+	}
+	if form.EndDate != 0 {
+		schedule.EndDate = time.UnixMilli(form.EndDate)
+	}
+	if len(form.StartTime) != 0 {
+		schedule.StartTime = form.StartTime
+	}
+	if len(form.EndTime) != 0 {
+		schedule.EndTime = form.EndTime
+	}
+	if len(form.Contributor) != 0 {
+		schedule.Contributor = contributorsOId
+	}
+	if len(form.ProjectID) != 0 {
+		schedule.ProjectID, err = h.findProjectID(c, form.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+	if form.RecurrenceFrequency == _const.RecurrenceNone {
+		schedule.Recurrence = nil
+		schedule.ExceptionDates = make([]time.Time, 0)
+		schedule.Overrides = make([]repository.ScheduleOverride, 0)
+	} else if form.recurrence != nil {
+		schedule.Recurrence = form.recurrence
+	}
+	if len(form.ExceptionDates) != 0 {
+		schedule.ExceptionDates = form.exceptionDates
+	}
+
+	if !schedule.EndDate.After(schedule.StartDate) {
+		return echo.NewHTTPError(http.StatusBadRequest, "End date must be after the start date.")
+	}
+	if schedule.EndTime <= schedule.StartTime {
+		return echo.NewHTTPError(http.StatusBadRequest, "End time must be after the start time.")
+	}
+	if schedule.Recurrence != nil && schedule.Recurrence.Until != nil && schedule.Recurrence.Until.Before(schedule.StartDate) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Recurrence until date must be after the start date.")
+	}
+
+	if !form.IgnoreConflict {
+		if err := h.checkConflicts(schedule); err != nil {
+			return err
+		}
+	}
+
+	if err := h.scheduleRepo.UpdateOneByID(schedule); err != nil {
+		log.Errorf("Failed to update schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(schedule))
+	h.notifier.Contributors(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, schedule.Name, contributorsBefore, schedule.Contributor)
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// Delete Schedule
+// @Tags Schedule
+// @Summary Delete schedule
+// @ID schedule-delete
+// @Router /api/schedule/{id} [delete]
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) delete(c echo.Context) error {
+	schedule, err := h.findModifiableSchedule(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.scheduleRepo.DeleteOneByID(schedule.ID); err != nil {
+		log.Errorf("Failed to delete schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, _const.ActivityDelete, audit.Snapshot(schedule), nil)
+	return c.JSON(http.StatusOK, "Schedule deleted")
+}
+
+// Update Schedule Occurrence
+// @Tags Schedule
+// @Summary Override or cancel a single occurrence of a recurring schedule
+// @ID schedule-occurrence-update
+// @Router /api/schedule/{id}/occurrence [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param body body occurrenceForm true "Occurrence data"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateOccurrence(c echo.Context) error {
+	schedule, err := h.findRecurringSchedule(c)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(schedule)
+
+	form, err := newOccurrenceForm(c)
+	if err != nil {
+		return err
+	}
+
+	date := time.UnixMilli(form.Date)
+	if !schedule.IsOccurrenceDate(date) {
+		return echo.NewHTTPError(http.StatusBadRequest, "The schedule does not occur on this date.")
+	}
+
+	i, override := schedule.FindOverride(date)
+	if form.Cancelled {
+		if i >= 0 {
+			schedule.Overrides = append(schedule.Overrides[:i], schedule.Overrides[i+1:]...)
+		}
+		if !schedule.IsException(date) {
+			schedule.ExceptionDates = append(schedule.ExceptionDates, date)
+		}
+	} else {
+		if override == nil {
+			schedule.Overrides = append(schedule.Overrides, repository.ScheduleOverride{Date: date})
+			override = &schedule.Overrides[len(schedule.Overrides)-1]
+		}
+		if len(form.Name) != 0 {
+			override.Name = form.Name
+		}
+		if len(form.Description) != 0 {
+			override.Description = form.Description
+		}
+		if len(form.StartTime) != 0 {
+			override.StartTime = form.StartTime
+		}
+		if len(form.EndTime) != 0 {
+			override.EndTime = form.EndTime
+		}
+
+		startTime, endTime := schedule.StartTime, schedule.EndTime
+		if len(override.StartTime) != 0 {
+			startTime = override.StartTime
+		}
+		if len(override.EndTime) != 0 {
+			endTime = override.EndTime
+		}
+		if endTime <= startTime {
+			return echo.NewHTTPError(http.StatusBadRequest, "End time must be after the start time.")
+		}
+	}
+
+	if err := h.scheduleRepo.UpdateOneByID(schedule); err != nil {
+		log.Errorf("Failed to update schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(schedule))
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// Delete Schedule Occurrence
+// @Tags Schedule
+// @Summary Remove a single occurrence from a recurring schedule
+// @ID schedule-occurrence-delete
+// @Router /api/schedule/{id}/occurrence/{date} [delete]
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param date path int true "Occurrence date in unix milli"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deleteOccurrence(c echo.Context) error {
+	schedule, err := h.findRecurringSchedule(c)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(schedule)
+
+	milli, err := strconv.ParseInt(c.Param("date"), 10, 64)
+	if err != nil || milli <= 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid occurrence date.")
+	}
+
+	date := time.UnixMilli(milli)
+	if !schedule.IsOccurrenceDate(date) {
+		return echo.NewHTTPError(http.StatusBadRequest, "The schedule does not occur on this date.")
+	}
+
+	if i, _ := schedule.FindOverride(date); i >= 0 {
+		schedule.Overrides = append(schedule.Overrides[:i], schedule.Overrides[i+1:]...)
+	}
+	if !schedule.IsException(date) {
+		schedule.ExceptionDates = append(schedule.ExceptionDates, date)
+	}
+
+	if err := h.scheduleRepo.UpdateOneByID(schedule); err != nil {
+		log.Errorf("Failed to update schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySchedule, schedule.ID, schedule.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(schedule))
+	return c.JSON(http.StatusOK, "Occurrence deleted")
+}
+
+func (h *Handler) findRecurringSchedule(c echo.Context) (*repository.Schedule, error) {
+	schedule, err := h.findModifiableSchedule(c)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Recurrence == nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Schedule is not recurring.")
+	}
+	return schedule, nil
+}
+
+// findProjectID resolves the project of a schedule, the user has to be a member of it
+func (h *Handler) findProjectID(c echo.Context, id string) (bson.ObjectID, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return bson.NilObjectID, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.findProject(objectID)
+	if err != nil {
+		return bson.NilObjectID, err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return bson.NilObjectID, err
+	}
+	return objectID, nil
+}
+
+func (h *Handler) findProject(projectID bson.ObjectID) (*repository.Project, error) {
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return project, nil
+}
+
+// findModifiableSchedule finds the schedule and checks that the user may change it
+func (h *Handler) findModifiableSchedule(c echo.Context) (*repository.Schedule, error) {
+	uc := c.(*context.Context)
+
+	schedule, err := h.findSchedule(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var project *repository.Project
+	if !schedule.ProjectID.IsZero() {
+		// Schedules of a deleted project remain editable by their contributors.
+		project, err = h.projectRepo.FindOneByID(schedule.ProjectID)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Errorf("Error finding project: %v", err)
+			return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+	}
+
+	if !access.CanModifySchedule(uc.Claims, schedule, project) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "You do not have permission to modify this schedule")
+	}
+	return schedule, nil
+}
+
+func (h *Handler) findSchedule(c echo.Context) (*repository.Schedule, error) {
+	id := c.Param("id")
+	if id == "" {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Schedule ID cannot be empty.")
+	}
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid schedule ID.")
+	}
+
+	schedule, err := h.scheduleRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Schedule not found")
+		}
+		log.Errorf("Error finding schedule: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return schedule, nil
+}
+
+// checkConflicts rejects the schedule with 409 when one of its contributors is already booked at the same time
+func (h *Handler) checkConflicts(schedule *repository.Schedule) error {
+	conflicts, err := h.scheduleRepo.FindConflicts(schedule)
+	if err != nil {
+		log.Errorf("Error finding schedule conflicts: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	response := make([]map[string]interface{}, 0)
+	contributors := map[bson.ObjectID]string{}
+
+	for _, conflict := range conflicts {
+		contributorInList := make([]string, 0)
+
+		for _, contributor := range schedule.SharedContributors(&conflict) {
+			if name, exists := contributors[contributor]; exists {
+				contributorInList = append(contributorInList, name)
+			} else if user, err := h.userRepo.FindOneByID(contributor); err == nil {
+				contributors[contributor] = user.Name
+				contributorInList = append(contributorInList, user.Name)
+			}
+		}
+
+		response = append(response, map[string]interface{}{
+			"id":          conflict.ID,
+			"name":        conflict.Name,
+			"start_date":  conflict.StartDate,
+			"end_date":    conflict.EndDate,
+			"start_time":  conflict.StartTime,
+			"end_time":    conflict.EndTime,
+			"contributor": contributorInList,
+		})
+	}
+
+	return echo.NewHTTPError(http.StatusConflict, map[string]interface{}{
+		"message":   "Some contributors already have a schedule at this time",
+		"conflicts": response,
+	})
+}
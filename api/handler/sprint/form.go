@@ -0,0 +1,207 @@
+package sprint
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"net/http"
+	"proman-backend/internal/pkg/log"
+	"strings"
+)
+
+const (
+	minNameLength = 1
+	maxNameLength = 100
+	maxGoalLength = 1000
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type sprintForm struct {
+	Name      string `json:"name" form:"name"`
+	Goal      string `json:"goal" form:"goal"`
+	StartDate int64  `json:"start_date" form:"start_date"`
+	EndDate   int64  `json:"end_date" form:"end_date"`
+}
+
+func newSprintForm(c echo.Context) (*sprintForm, error) {
+	form := new(sprintForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding sprint form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+	form.Goal = strings.TrimSpace(form.Goal)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate name
+	if len(form.Name) < minNameLength || len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	// Validate goal
+	if len(form.Goal) > maxGoalLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "goal",
+			Message: "Goal must be at most 1000 characters.",
+		})
+	}
+
+	// Validate start date
+	if form.StartDate <= 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "start_date",
+			Message: "Invalid start date.",
+		})
+	}
+
+	// Validate end date
+	if form.EndDate <= 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "end_date",
+			Message: "Invalid end date.",
+		})
+	} else if form.EndDate <= form.StartDate {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "end_date",
+			Message: "End date must be after the start date.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type updateSprintForm struct {
+	Name      string `json:"name" form:"name"`
+	Goal      string `json:"goal" form:"goal"`
+	StartDate int64  `json:"start_date" form:"start_date"`
+	EndDate   int64  `json:"end_date" form:"end_date"`
+}
+
+func newUpdateSprintForm(c echo.Context) (*updateSprintForm, error) {
+	form := new(updateSprintForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding update sprint form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+	form.Goal = strings.TrimSpace(form.Goal)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate name
+	if len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	// Validate goal
+	if len(form.Goal) > maxGoalLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "goal",
+			Message: "Goal must be at most 1000 characters.",
+		})
+	}
+
+	// Validate start date
+	if form.StartDate < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "start_date",
+			Message: "Invalid start date.",
+		})
+	}
+
+	// Validate end date
+	if form.EndDate < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "end_date",
+			Message: "Invalid end date.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type sprintTasksForm struct {
+	TaskIDs string `json:"task_ids" form:"task_ids"` // comma separated task IDs of the project
+
+	taskIDs []bson.ObjectID
+}
+
+func newSprintTasksForm(c echo.Context) (*sprintTasksForm, error) {
+	form := new(sprintTasksForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding sprint tasks form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.TaskIDs = strings.TrimSpace(form.TaskIDs)
+
+	// Validate task IDs
+	seen := map[bson.ObjectID]bool{}
+	for _, id := range strings.Split(form.TaskIDs, ",") {
+		taskID, err := bson.ObjectIDFromHex(strings.TrimSpace(id))
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+				"errors": []errorDoc{{Field: "task_ids", Message: "Invalid task ID."}},
+			})
+		}
+		if !seen[taskID] {
+			seen[taskID] = true
+			form.taskIDs = append(form.taskIDs, taskID)
+		}
+	}
+	return form, nil
+}
+
+type completeSprintForm struct {
+	NextSprintID string `json:"next_sprint_id" form:"next_sprint_id"` // optional, unfinished tasks go to the backlog without it
+
+	nextSprintID bson.ObjectID
+}
+
+func newCompleteSprintForm(c echo.Context) (*completeSprintForm, error) {
+	form := new(completeSprintForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding complete sprint form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.NextSprintID = strings.TrimSpace(form.NextSprintID)
+
+	// Validate next sprint ID
+	if form.NextSprintID != "" {
+		nextSprintID, err := bson.ObjectIDFromHex(form.NextSprintID)
+		if err != nil {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+				"errors": []errorDoc{{Field: "next_sprint_id", Message: "Invalid sprint ID."}},
+			})
+		}
+		form.nextSprintID = nextSprintID
+	}
+	return form, nil
+}
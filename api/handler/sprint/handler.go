@@ -0,0 +1,617 @@
+package sprint
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"math"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/audit"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+type Handler struct {
+	projectRepo *repository.ProjectCollRepository
+	taskRepo    *repository.TaskCollRepository
+	sprintRepo  *repository.SprintCollRepository
+	recorder    *audit.Recorder
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		projectRepo: repository.NewProjectCollRepository(db),
+		taskRepo:    repository.NewTaskCollRepository(db),
+		sprintRepo:  repository.NewSprintCollRepository(db),
+		recorder:    audit.NewRecorder(db),
+	}
+
+	sprint := e.Group("/api", context.ContextHandler)
+
+	sprint.GET("/project/:id/sprints", h.list)
+	sprint.GET("/sprint/:id", h.detail)
+	sprint.GET("/sprint/:id/report", h.report)
+
+	sprint.POST("/project/:id/sprints", h.create)
+	sprint.POST("/sprint/:id/tasks", h.addTasks)
+	sprint.POST("/sprint/:id/start", h.start)
+	sprint.POST("/sprint/:id/complete", h.complete)
+
+	sprint.PUT("/sprint/:id", h.update)
+
+	sprint.DELETE("/sprint/:id", h.delete)
+
+	return h
+}
+
+// List Sprint
+// @Tags Sprint
+// @Summary Get the sprints of a project
+// @ID list-sprint
+// @Router /api/project/{id}/sprints [get]
+// @Param id path string true "Project ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) list(c echo.Context) error {
+	project, err := h.findProject(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	sprints, err := h.sprintRepo.FindAllByProjectID(project.ID)
+	if err != nil {
+		log.Errorf("Error finding sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, sprints)
+}
+
+// Get Sprint
+// @Tags Sprint
+// @Summary Get a sprint with its tasks
+// @ID get-sprint
+// @Router /api/sprint/{id} [get]
+// @Param id path string true "Sprint ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) detail(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	tasks, err := h.sprintTasks(sprint.ID)
+	if err != nil {
+		return err
+	}
+	repository.SortTasksByRank(tasks)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sprint": sprint,
+		"tasks":  tasks,
+	})
+}
+
+// Sprint Report
+// @Tags Sprint
+// @Summary Get the committed, completed, carried-over and added tasks of a sprint
+// @Description Committed tasks are the tasks in the sprint when it started. Until the sprint completes,
+// @Description carried-over lists the unfinished tasks that would move on.
+// @ID sprint-report
+// @Router /api/sprint/{id}/report [get]
+// @Param id path string true "Sprint ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) report(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	current, err := h.sprintTasks(sprint.ID)
+	if err != nil {
+		return err
+	}
+
+	committedIDs := sprint.CommittedTaskIDs
+	if sprint.Status == _const.SprintPlanned {
+		committedIDs = taskIDs(current)
+	}
+
+	var completed, carriedOver []repository.Task
+	if sprint.Status == _const.SprintCompleted {
+		if completed, err = h.findTasks(sprint.CompletedTaskIDs); err != nil {
+			return err
+		}
+		if carriedOver, err = h.findTasks(sprint.CarriedOverTaskIDs); err != nil {
+			return err
+		}
+	} else {
+		completed, carriedOver = splitFinished(current)
+	}
+
+	committed, err := h.findTasks(committedIDs)
+	if err != nil {
+		return err
+	}
+
+	isCommitted := map[bson.ObjectID]bool{}
+	for _, id := range committedIDs {
+		isCommitted[id] = true
+	}
+	added := make([]repository.Task, 0)
+	for _, task := range append(append([]repository.Task{}, completed...), carriedOver...) {
+		if !isCommitted[task.ID] {
+			added = append(added, task)
+		}
+	}
+
+	committedPoints, completedPoints := points(committed), points(completed)
+	completion := 0.0
+	if committedPoints > 0 {
+		completion = math.Round(float64(points(filter(completed, isCommitted)))/float64(committedPoints)*100) / 100
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"sprint":           sprint,
+		"committed":        committed,
+		"completed":        completed,
+		"carried_over":     carriedOver,
+		"added":            added,
+		"committed_points": committedPoints,
+		"completed_points": completedPoints,
+		"completion":       completion, // ratio of committed points completed
+	})
+}
+
+// Create Sprint
+// @Tags Sprint
+// @Summary Create a sprint in a project
+// @ID create-sprint
+// @Router /api/project/{id}/sprints [post]
+// @Param id path string true "Project ID"
+// @Param body body sprintForm true "Sprint data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) create(c echo.Context) error {
+	project, err := h.findProject(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+
+	form, err := newSprintForm(c)
+	if err != nil {
+		return err
+	}
+
+	sprint := repository.Sprint{
+		ID:                 bson.NewObjectID(),
+		ProjectID:          project.ID,
+		Name:               form.Name,
+		Goal:               form.Goal,
+		StartDate:          time.UnixMilli(form.StartDate),
+		EndDate:            time.UnixMilli(form.EndDate),
+		Status:             _const.SprintPlanned,
+		CreatedAt:          time.Now(),
+		IsDeleted:          false,
+		CommittedTaskIDs:   make([]bson.ObjectID, 0),
+		CompletedTaskIDs:   make([]bson.ObjectID, 0),
+		CarriedOverTaskIDs: make([]bson.ObjectID, 0),
+	}
+
+	if err := h.sprintRepo.CreateOne(&sprint); err != nil {
+		log.Errorf("Failed to create sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySprint, sprint.ID, project.ID, _const.ActivityCreate, nil, audit.Snapshot(&sprint))
+	return c.JSON(http.StatusOK, sprint)
+}
+
+// Add Sprint Tasks
+// @Tags Sprint
+// @Summary Move tasks of the project into a sprint
+// @ID add-sprint-tasks
+// @Router /api/sprint/{id}/tasks [post]
+// @Param id path string true "Sprint ID"
+// @Param body body sprintTasksForm true "Tasks"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) addTasks(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+	if sprint.Status == _const.SprintCompleted {
+		return echo.NewHTTPError(http.StatusBadRequest, "The sprint is already completed.")
+	}
+
+	form, err := newSprintTasksForm(c)
+	if err != nil {
+		return err
+	}
+
+	tasks, err := h.findTasks(form.taskIDs)
+	if err != nil {
+		return err
+	}
+	if len(tasks) != len(form.taskIDs) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task not found.")
+	}
+	for _, task := range tasks {
+		if task.ProjectID != project.ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "Task belongs to another project.")
+		}
+	}
+
+	if err := h.taskRepo.SetSprint(form.taskIDs, sprint.ID); err != nil {
+		log.Errorf("Failed to move tasks into sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	for _, task := range tasks {
+		if task.SprintID != sprint.ID {
+			h.recorder.RecordChanges(c, _const.EntityTask, task.ID, project.ID, _const.ActivityUpdate, []repository.ActivityChange{
+				{Field: "sprint_id", Old: task.SprintID.Hex(), New: sprint.ID.Hex()},
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, "Tasks moved into the sprint")
+}
+
+// Start Sprint
+// @Tags Sprint
+// @Summary Start a planned sprint, its current tasks become the committed tasks
+// @ID start-sprint
+// @Router /api/sprint/{id}/start [post]
+// @Param id path string true "Sprint ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) start(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	if sprint.Status != _const.SprintPlanned {
+		return echo.NewHTTPError(http.StatusBadRequest, "Only a planned sprint can be started.")
+	}
+
+	if _, err := h.sprintRepo.FindActiveByProjectID(project.ID); err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "Another sprint of the project is running, complete it first.")
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Errorf("Error finding sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	tasks, err := h.sprintTasks(sprint.ID)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(sprint)
+
+	sprint.Status = _const.SprintActive
+	sprint.StartedAt = time.Now()
+	sprint.CommittedTaskIDs = taskIDs(tasks)
+
+	// the unique index on running sprints rejects a sprint started concurrently with this one
+	saved, err := h.sprintRepo.UpdateStatus(sprint, _const.SprintPlanned)
+	if mongo.IsDuplicateKeyError(err) {
+		return echo.NewHTTPError(http.StatusConflict, "Another sprint of the project is running, complete it first.")
+	}
+	if err != nil {
+		log.Errorf("Failed to start sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !saved {
+		return echo.NewHTTPError(http.StatusConflict, "The sprint was changed in the meantime, please try again.")
+	}
+
+	h.recorder.Record(c, _const.EntitySprint, sprint.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(sprint))
+	return c.JSON(http.StatusOK, sprint)
+}
+
+// Complete Sprint
+// @Tags Sprint
+// @Summary Complete the running sprint and move its unfinished tasks into the next sprint or the backlog
+// @Description Completing a completed sprint again retries moving its unfinished tasks.
+// @ID complete-sprint
+// @Router /api/sprint/{id}/complete [post]
+// @Param id path string true "Sprint ID"
+// @Param body body completeSprintForm false "Next sprint"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) complete(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	// completing again finishes carrying over the tasks when a previous attempt failed halfway
+	if sprint.Status == _const.SprintCompleted {
+		if err := h.sprintRepo.CarryOver(sprint); err != nil {
+			log.Errorf("Failed to carry over sprint tasks: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		return c.JSON(http.StatusOK, sprint)
+	}
+	if sprint.Status != _const.SprintActive {
+		return echo.NewHTTPError(http.StatusBadRequest, "Only a running sprint can be completed.")
+	}
+
+	form, err := newCompleteSprintForm(c)
+	if err != nil {
+		return err
+	}
+
+	if !form.nextSprintID.IsZero() {
+		next, err := h.sprintRepo.FindOneByID(form.nextSprintID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return echo.NewHTTPError(http.StatusBadRequest, "Next sprint not found.")
+			}
+			log.Errorf("Error finding sprint: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		if next.ID == sprint.ID || next.ProjectID != project.ID || next.Status == _const.SprintCompleted {
+			return echo.NewHTTPError(http.StatusBadRequest, "The next sprint has to be another open sprint of the project.")
+		}
+	}
+
+	tasks, err := h.sprintTasks(sprint.ID)
+	if err != nil {
+		return err
+	}
+	completed, unfinished := splitFinished(tasks)
+	before := audit.Snapshot(sprint)
+
+	sprint.Status = _const.SprintCompleted
+	sprint.CompletedAt = time.Now()
+	sprint.CompletedTaskIDs = taskIDs(completed)
+	sprint.CarriedOverTaskIDs = taskIDs(unfinished)
+	sprint.NextSprintID = form.nextSprintID
+
+	saved, err := h.sprintRepo.Complete(sprint)
+	if err != nil {
+		log.Errorf("Failed to complete sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !saved {
+		return echo.NewHTTPError(http.StatusConflict, "The sprint was changed in the meantime, please try again.")
+	}
+
+	h.recorder.Record(c, _const.EntitySprint, sprint.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(sprint))
+	return c.JSON(http.StatusOK, sprint)
+}
+
+// Update Sprint
+// @Tags Sprint
+// @Summary Update a sprint that is not completed
+// @ID update-sprint
+// @Router /api/sprint/{id} [put]
+// @Param id path string true "Sprint ID"
+// @Param body body updateSprintForm true "Sprint data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) update(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	if sprint.Status == _const.SprintCompleted {
+		return echo.NewHTTPError(http.StatusBadRequest, "The sprint is already completed.")
+	}
+
+	form, err := newUpdateSprintForm(c)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(sprint)
+
+	if len(form.Name) != 0 {
+		sprint.Name = form.Name
+	}
+	if len(form.Goal) != 0 {
+		sprint.Goal = form.Goal
+	}
+	if form.StartDate != 0 {
+		sprint.StartDate = time.UnixMilli(form.StartDate)
+	}
+	if form.EndDate != 0 {
+		sprint.EndDate = time.UnixMilli(form.EndDate)
+	}
+	if !sprint.EndDate.After(sprint.StartDate) {
+		return echo.NewHTTPError(http.StatusBadRequest, "End date must be after the start date.")
+	}
+
+	if err := h.sprintRepo.UpdateOneByID(sprint); err != nil {
+		log.Errorf("Failed to update sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySprint, sprint.ID, project.ID, _const.ActivityUpdate, before, audit.Snapshot(sprint))
+	return c.JSON(http.StatusOK, sprint)
+}
+
+// Delete Sprint
+// @Tags Sprint
+// @Summary Delete a sprint that is not running, its tasks go back to the backlog
+// @ID delete-sprint
+// @Router /api/sprint/{id} [delete]
+// @Param id path string true "Sprint ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) delete(c echo.Context) error {
+	sprint, project, err := h.findSprint(c)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+	if sprint.Status == _const.SprintActive {
+		return echo.NewHTTPError(http.StatusBadRequest, "Complete the sprint before deleting it.")
+	}
+
+	if err := h.sprintRepo.DeleteOneByID(sprint.ID); err != nil {
+		log.Errorf("Failed to delete sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := h.taskRepo.ReleaseSprint(sprint.ID); err != nil {
+		log.Errorf("Failed to release sprint tasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntitySprint, sprint.ID, project.ID, _const.ActivityDelete, audit.Snapshot(sprint), nil)
+	return c.JSON(http.StatusOK, "Sprint deleted")
+}
+
+func (h *Handler) findProject(id string) (*repository.Project, error) {
+	projectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+	return h.findProjectByID(projectID)
+}
+
+func (h *Handler) findProjectByID(projectID bson.ObjectID) (*repository.Project, error) {
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return project, nil
+}
+
+func (h *Handler) findSprint(c echo.Context) (*repository.Sprint, *repository.Project, error) {
+	sprintID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid sprint ID.")
+	}
+
+	sprint, err := h.sprintRepo.FindOneByID(sprintID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Sprint not found")
+		}
+		log.Errorf("Error finding sprint: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.findProjectByID(sprint.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sprint, project, nil
+}
+
+func (h *Handler) sprintTasks(sprintID bson.ObjectID) ([]repository.Task, error) {
+	cq := util.NilCommonQuery()
+	cq.SprintId = sprintID
+
+	tasks, err := h.taskRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return tasks, nil
+}
+
+func (h *Handler) findTasks(ids []bson.ObjectID) ([]repository.Task, error) {
+	tasks, err := h.taskRepo.FindAllByIDs(ids)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return tasks, nil
+}
+
+// splitFinished splits the tasks into completed and unfinished tasks, cancelled tasks are left out
+func splitFinished(tasks []repository.Task) ([]repository.Task, []repository.Task) {
+	completed, unfinished := make([]repository.Task, 0), make([]repository.Task, 0)
+	for _, task := range tasks {
+		switch task.Status {
+		case _const.TaskCompleted:
+			completed = append(completed, task)
+		case _const.TaskActive, _const.TaskTesting:
+			unfinished = append(unfinished, task)
+		}
+	}
+	return completed, unfinished
+}
+
+func taskIDs(tasks []repository.Task) []bson.ObjectID {
+	ids := make([]bson.ObjectID, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func filter(tasks []repository.Task, keep map[bson.ObjectID]bool) []repository.Task {
+	result := make([]repository.Task, 0)
+	for _, task := range tasks {
+		if keep[task.ID] {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+func points(tasks []repository.Task) int {
+	total := 0
+	for _, task := range tasks {
+		total += task.StoryPoints
+	}
+	return total
+}
@@ -14,6 +14,13 @@ const (
 	maxNameLength        = 100
 	minDescriptionLength = 10
 	maxDescriptionLength = 1000
+	maxReasonLength      = 1000
+	maxChecklistLength   = 500
+	maxEstimatedHours    = 10000
+	maxStoryPoints       = 100
+	defaultVelocityWeeks = 6
+	maxVelocityWeeks     = 26
+	noneValue            = "none" // clears an optional reference
 )
 
 type errorDoc struct {
@@ -26,8 +33,13 @@ type taskForm struct {
 	Description string `json:"description" form:"description"`
 	Contributor string `json:"contributor" form:"contributor"`
 	ProjectID   string `json:"project_id" form:"project_id"`
+	ParentID    string `json:"parent_id" form:"parent_id"` // optional, makes the task a subtask
+	SprintID    string `json:"sprint_id" form:"sprint_id"` // optional, the task goes to the backlog without it
 	StartDate   int64  `json:"start_date" form:"start_date"`
 	EndDate     int64  `json:"end_date" form:"end_date"`
+	// optional
+	StoryPoints    int     `json:"story_points" form:"story_points"`
+	EstimatedHours float64 `json:"estimated_hours" form:"estimated_hours"`
 }
 
 func newTaskForm(c echo.Context) (*taskForm, error) {
@@ -42,6 +54,8 @@ func newTaskForm(c echo.Context) (*taskForm, error) {
 	form.Description = strings.TrimSpace(form.Description)
 	form.Contributor = strings.TrimSpace(form.Contributor)
 	form.ProjectID = strings.TrimSpace(form.ProjectID)
+	form.ParentID = strings.TrimSpace(form.ParentID)
+	form.SprintID = strings.TrimSpace(form.SprintID)
 
 	validationErrors := make([]errorDoc, 0)
 
@@ -103,6 +117,42 @@ func newTaskForm(c echo.Context) (*taskForm, error) {
 		})
 	}
 
+	// Validate parent ID
+	if form.ParentID != "" {
+		if _, err := bson.ObjectIDFromHex(form.ParentID); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "parent_id",
+				Message: "Invalid parent ID.",
+			})
+		}
+	}
+
+	// Validate sprint ID
+	if form.SprintID != "" {
+		if _, err := bson.ObjectIDFromHex(form.SprintID); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "sprint_id",
+				Message: "Invalid sprint ID.",
+			})
+		}
+	}
+
+	// Validate story points
+	if form.StoryPoints < 0 || form.StoryPoints > maxStoryPoints {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "story_points",
+			Message: "Story points must be between 0 and 100.",
+		})
+	}
+
+	// Validate estimated hours
+	if form.EstimatedHours < 0 || form.EstimatedHours > maxEstimatedHours {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "estimated_hours",
+			Message: "Estimated hours must be between 0 and 10000.",
+		})
+	}
+
 	if len(validationErrors) > 0 {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
 			"errors": validationErrors,
@@ -118,6 +168,11 @@ type updateTaskForm struct {
 	StartDate   int64  `json:"start_date" form:"start_date"`
 	EndDate     int64  `json:"end_date" form:"end_date"`
 	Status      string `json:"status" form:"status"`
+	ParentID    string `json:"parent_id" form:"parent_id"` // "none" makes the task top-level again
+	SprintID    string `json:"sprint_id" form:"sprint_id"` // "none" moves the task to the backlog
+	// 0 clears the estimate
+	StoryPoints    *int     `json:"story_points" form:"story_points"`
+	EstimatedHours *float64 `json:"estimated_hours" form:"estimated_hours"`
 }
 
 func newUpdateTaskForm(c echo.Context) (*updateTaskForm, error) {
@@ -132,6 +187,8 @@ func newUpdateTaskForm(c echo.Context) (*updateTaskForm, error) {
 	form.Description = strings.TrimSpace(form.Description)
 	form.Contributor = strings.TrimSpace(form.Contributor)
 	form.Status = strings.TrimSpace(form.Status)
+	form.ParentID = strings.TrimSpace(form.ParentID)
+	form.SprintID = strings.TrimSpace(form.SprintID)
 
 	validationErrors := make([]errorDoc, 0)
 
@@ -180,6 +237,213 @@ func newUpdateTaskForm(c echo.Context) (*updateTaskForm, error) {
 		})
 	}
 
+	// Validate parent ID
+	if form.ParentID != "" && form.ParentID != noneValue {
+		if _, err := bson.ObjectIDFromHex(form.ParentID); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "parent_id",
+				Message: "Invalid parent ID.",
+			})
+		}
+	}
+
+	// Validate sprint ID
+	if form.SprintID != "" && form.SprintID != noneValue {
+		if _, err := bson.ObjectIDFromHex(form.SprintID); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "sprint_id",
+				Message: "Invalid sprint ID.",
+			})
+		}
+	}
+
+	// Validate story points
+	if form.StoryPoints != nil && (*form.StoryPoints < 0 || *form.StoryPoints > maxStoryPoints) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "story_points",
+			Message: "Story points must be between 0 and 100.",
+		})
+	}
+
+	// Validate estimated hours
+	if form.EstimatedHours != nil && (*form.EstimatedHours < 0 || *form.EstimatedHours > maxEstimatedHours) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "estimated_hours",
+			Message: "Estimated hours must be between 0 and 10000.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type transitionForm struct {
+	Status string `json:"status" form:"status"`
+	Reason string `json:"reason" form:"reason"`
+}
+
+func newTransitionForm(c echo.Context) (*transitionForm, error) {
+	form := new(transitionForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding transition form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Status = strings.ToLower(strings.TrimSpace(form.Status))
+	form.Reason = strings.TrimSpace(form.Reason)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate status
+	if !_const.IsValidTaskStatus(form.Status) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "status",
+			Message: "Invalid status.",
+		})
+	}
+
+	// Validate reason
+	if len(form.Reason) > maxReasonLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "reason",
+			Message: "Reason must be at most 1000 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type moveForm struct {
+	Column   string `json:"column" form:"column"`     // board column key
+	Position int    `json:"position" form:"position"` // zero based position within the column
+	Reason   string `json:"reason" form:"reason"`     // optional, recorded when the move changes the status
+}
+
+func newMoveForm(c echo.Context) (*moveForm, error) {
+	form := new(moveForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding move form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Column = strings.TrimSpace(form.Column)
+	form.Reason = strings.TrimSpace(form.Reason)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate column
+	if form.Column == "" {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "column",
+			Message: "Column cannot be empty.",
+		})
+	}
+
+	// Validate position
+	if form.Position < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "position",
+			Message: "Invalid position.",
+		})
+	}
+
+	// Validate reason
+	if len(form.Reason) > maxReasonLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "reason",
+			Message: "Reason must be at most 1000 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type checklistItemForm struct {
+	Text       string `json:"text" form:"text"`
+	Done       *bool  `json:"done" form:"done"`
+	AssigneeID string `json:"assignee_id" form:"assignee_id"` // optional, "none" removes the assignee
+}
+
+// newChecklistItemForm binds a checklist item, the text is only required when creating one
+func newChecklistItemForm(c echo.Context, create bool) (*checklistItemForm, error) {
+	form := new(checklistItemForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding checklist item form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Text = strings.TrimSpace(form.Text)
+	form.AssigneeID = strings.TrimSpace(form.AssigneeID)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate text
+	if (create || len(form.Text) != 0) && (len(form.Text) < minNameLength || len(form.Text) > maxChecklistLength) {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "text",
+			Message: "Text must be between 1 and 500 characters.",
+		})
+	}
+
+	// Validate assignee ID
+	if form.AssigneeID != "" && form.AssigneeID != noneValue {
+		if _, err := bson.ObjectIDFromHex(form.AssigneeID); err != nil {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "assignee_id",
+				Message: "Invalid assignee ID.",
+			})
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type dependencyForm struct {
+	BlockedBy string `json:"blocked_by" form:"blocked_by"` // ID of the task to depend on
+}
+
+func newDependencyForm(c echo.Context) (*dependencyForm, error) {
+	form := new(dependencyForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding dependency form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.BlockedBy = strings.TrimSpace(form.BlockedBy)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate blocked by
+	if _, err := bson.ObjectIDFromHex(form.BlockedBy); err != nil {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "blocked_by",
+			Message: "Invalid task ID.",
+		})
+	}
+
 	if len(validationErrors) > 0 {
 		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
 			"errors": validationErrors,
@@ -5,23 +5,47 @@ import (
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"math"
 	"net/http"
 	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/audit"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/context"
 	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/notify"
 	"proman-backend/internal/pkg/util"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
+const rankStep = 1024
+
 type Handler struct {
-	taskRepo *repository.TaskCollRepository
+	userRepo     *repository.UserCollRepository
+	taskRepo     *repository.TaskCollRepository
+	projectRepo  *repository.ProjectCollRepository
+	commentRepo  *repository.CommentCollRepository
+	sprintRepo   *repository.SprintCollRepository
+	calendarRepo *repository.WorkingCalendarCollRepository
+	holidayRepo  *repository.HolidayCollRepository
+	recorder     *audit.Recorder
+	notifier     *notify.Notifier
 }
 
 func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	h := &Handler{
-		taskRepo: repository.NewTaskCollRepository(db),
+		userRepo:     repository.NewUserCollRepository(db),
+		taskRepo:     repository.NewTaskCollRepository(db),
+		projectRepo:  repository.NewProjectCollRepository(db),
+		commentRepo:  repository.NewCommentCollRepository(db),
+		sprintRepo:   repository.NewSprintCollRepository(db),
+		calendarRepo: repository.NewWorkingCalendarCollRepository(db),
+		holidayRepo:  repository.NewHolidayCollRepository(db),
+		recorder:     audit.NewRecorder(db),
+		notifier:     notify.NewNotifier(db),
 	}
 
 	task := e.Group("/api", context.ContextHandler)
@@ -30,11 +54,23 @@ func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
 	task.GET("/tasks", h.tasks)
 	task.GET("/task/count", h.count)
 	task.GET("/task/overview", h.overview)
+	task.GET("/task/velocity", h.velocity)
 	task.GET("/task/status", h.status)
+	task.GET("/project/:id/board", h.board)
+	task.GET("/project/:id/critical-path", h.criticalPath)
 
 	task.POST("/task", h.create)
 
 	task.PUT("/task/:id", h.update)
+	task.POST("/task/:id/transition", h.transition)
+	task.POST("/task/:id/move", h.move)
+
+	task.POST("/task/:id/checklist", h.createChecklistItem)
+	task.PUT("/task/:id/checklist/:itemId", h.updateChecklistItem)
+	task.DELETE("/task/:id/checklist/:itemId", h.deleteChecklistItem)
+
+	task.POST("/task/:id/dependency", h.createDependency)
+	task.DELETE("/task/:id/dependency/:blockerId", h.deleteDependency)
 
 	task.DELETE("/task/:id", h.delete)
 
@@ -70,7 +106,38 @@ func (h *Handler) task(c echo.Context) error {
 		log.Errorf("Error finding task: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
-	return c.JSON(http.StatusOK, task)
+
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	subtasks, err := h.taskRepo.FindAllByParentID(task.ID)
+	if err != nil {
+		log.Errorf("Error finding subtasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	task.CalculateProgress(subtasks)
+	for i := range subtasks {
+		subtasks[i].CalculateProgress(nil)
+	}
+	task.Subtasks = subtasks
+
+	if err := h.fillWarnings(task); err != nil {
+		return err
+	}
+
+	tasks := []repository.Task{*task}
+	if err := h.commentRepo.FillTaskCommentCount(tasks); err != nil {
+		log.Warnf("Error counting task comments: %v", err)
+	}
+	if err := h.calendarRepo.FillTaskWorkingDaysLeft(tasks, h.holidayRepo); err != nil {
+		log.Warnf("Error counting working days: %v", err)
+	}
+	return c.JSON(http.StatusOK, tasks[0])
 }
 
 // Tasks
@@ -84,12 +151,17 @@ func (h *Handler) task(c echo.Context) error {
 // @Param projectId query string false "Search by project"
 // @Param start query string false "Start date"
 // @Param end query string false "End date"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Param sprintId query string false "Search by sprint"
 // @Accept json
 // @Produce json
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) tasks(c echo.Context) error {
 	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
 	tasks, err := h.taskRepo.FindAll(cq)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -98,6 +170,13 @@ func (h *Handler) tasks(c echo.Context) error {
 		log.Errorf("Error finding task: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	if err := h.commentRepo.FillTaskCommentCount(tasks); err != nil {
+		log.Warnf("Error counting task comments: %v", err)
+	}
+	if err := h.calendarRepo.FillTaskWorkingDaysLeft(tasks, h.holidayRepo); err != nil {
+		log.Warnf("Error counting working days: %v", err)
+	}
 	return c.JSON(http.StatusOK, tasks)
 }
 
@@ -106,12 +185,17 @@ func (h *Handler) tasks(c echo.Context) error {
 // @Summary Get task count
 // @ID task-count
 // @Router /api/task/count [get]
+// @Param topLevel query bool false "Leave subtasks out"
+// @Param sprintId query string false "Search by sprint"
 // @Accept json
 // @Produce json
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) count(c echo.Context) error {
 	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
 	count, err := h.taskRepo.CountTask(cq)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
@@ -133,34 +217,84 @@ func (h *Handler) count(c echo.Context) error {
 // @Summary Get task overview
 // @ID task-overview
 // @Router /api/task/overview [get]
+// @Param granularity query string false "Length of a period, default week" Enums(day, week, month, quarter, year)
+// @Param periods query int false "Number of periods up to the current one, default 8, at most 366"
+// @Param userId query string false "Search by contributor"
+// @Param projectId query string false "Search by project"
+// @Param sprintId query string false "Search by sprint"
+// @Param topLevel query bool false "Leave subtasks out"
 // @Accept json
 // @Produce json
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) overview(c echo.Context) error {
-	doc := []repository.TaskOverview{}
 	cq := util.NewCommonQuery(c)
-	for _, v := range []int{-7, -6, -5, -4, -3, -2, -1, 0} {
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
+
+	doc, err := h.taskRepo.Overview(cq)
+	if err != nil {
+		log.Errorf("Error counting task overview: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+// Task Velocity
+// @Tags Task
+// @Summary Get the story points completed per week and their average
+// @Description Only full weeks are counted, the current week is left out.
+// @ID task-velocity
+// @Router /api/task/velocity [get]
+// @Param weeks query int false "Number of past weeks, default 6, at most 26"
+// @Param userId query string false "Search by contributor"
+// @Param projectId query string false "Search by project"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) velocity(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
+	}
+
+	weeks := defaultVelocityWeeks
+	if param := strings.TrimSpace(c.QueryParam("weeks")); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 1 || value > maxVelocityWeeks {
+			return echo.NewHTTPError(http.StatusBadRequest, "Weeks must be between 1 and 26.")
+		}
+		weeks = value
+	}
+
+	doc := []repository.TaskOverview{}
+	total := 0
+	for v := -weeks; v < 0; v++ {
 		cq.Start = util.StartOfWeek(v)
-		cq.End = util.EndOfWeek(v)
-
-		count, err := h.taskRepo.CountTask(cq)
-		if err != nil || len(count) == 0 {
-			doc = append(doc, repository.TaskOverview{
-				Start: cq.Start.Format("02 Jan"),
-				End:   cq.End.Format("02 Jan"),
-				Count: 0,
-			})
-			continue
+		cq.End = util.StartOfWeek(v + 1)
+
+		points, err := h.taskRepo.SumCompletedPoints(cq)
+		if err != nil {
+			log.Errorf("Error summing completed points: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 		}
 
 		doc = append(doc, repository.TaskOverview{
-			Start: cq.Start.Format("02 Jan"),
-			End:   cq.End.Format("02 Jan"),
-			Count: count[0].Active + count[0].Testing + count[0].Completed,
+			Start:  cq.Start.Format("02 Jan"),
+			End:    util.EndOfWeek(v).Format("02 Jan"),
+			Points: points,
 		})
+		total += points
 	}
-	return c.JSON(http.StatusOK, doc)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"weeks":   doc,
+		"total":   total,
+		"average": math.Round(float64(total)/float64(weeks)*100) / 100,
+	})
 }
 
 // Task By Status
@@ -168,45 +302,26 @@ func (h *Handler) overview(c echo.Context) error {
 // @Summary Get task list by status
 // @ID task-list-status
 // @Router /api/task/status [get]
+// @Param topLevel query bool false "Leave subtasks out"
+// @Param sprintId query string false "Search by sprint"
 // @Accept json
 // @Produce json
 // @Success 200
 // @Security ApiKeyAuth
 func (h *Handler) status(c echo.Context) error {
 	cq := util.NewCommonQuery(c)
-
-	docs := repository.TaskGroup{}
-
-	cq.Status = _const.TaskActive
-	active, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Active = active
-	} else {
-		log.Warnf("Error finding active task: %v", err)
-	}
-
-	cq.Status = _const.TaskTesting
-	testing, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Testing = testing
-	} else {
-		log.Warnf("Error finding testing task: %v", err)
+	if err := access.ScopeQuery(c.(*context.Context).Claims, h.projectRepo, cq); err != nil {
+		return err
 	}
 
-	cq.Status = _const.TaskCompleted
-	completed, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Completed = completed
-	} else {
-		log.Warnf("Error finding completed task: %v", err)
+	docs, err := h.taskRepo.FindGroup(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 
-	cq.Status = _const.TaskCancelled
-	cancelled, err := h.taskRepo.FindAll(cq)
-	if err == nil {
-		docs.Cancelled = cancelled
-	} else {
-		log.Warnf("Error finding cancelled task: %v", err)
+	if err := h.commentRepo.FillTaskGroupCommentCount(docs); err != nil {
+		log.Warnf("Error counting task comments: %v", err)
 	}
 	return c.JSON(http.StatusOK, docs)
 }
@@ -241,24 +356,67 @@ func (h *Handler) create(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
 	}
 
+	project, err := h.findProject(projectOId)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+
+	parentOId := bson.NilObjectID
+	if form.ParentID != "" {
+		parentOId, _ = bson.ObjectIDFromHex(form.ParentID)
+		if _, err := h.findParent(parentOId, projectOId); err != nil {
+			return err
+		}
+	}
+
+	sprintOId := bson.NilObjectID
+	if form.SprintID != "" {
+		sprintOId, _ = bson.ObjectIDFromHex(form.SprintID)
+		if err := h.checkSprint(sprintOId, projectOId); err != nil {
+			return err
+		}
+	}
+
 	task := repository.Task{
-		ID:          bson.NewObjectID(),
-		Name:        form.Name,
-		Description: form.Description,
-		StartDate:   time.UnixMilli(form.StartDate),
-		EndDate:     time.UnixMilli(form.EndDate),
-		Contributor: contributorsOId,
-		Status:      _const.TaskActive,
-		ProjectID:   projectOId,
-		CreatedAt:   time.Now(),
-		IsDeleted:   false,
+		ID:             bson.NewObjectID(),
+		Name:           form.Name,
+		Description:    form.Description,
+		StartDate:      time.UnixMilli(form.StartDate),
+		EndDate:        time.UnixMilli(form.EndDate),
+		Contributor:    contributorsOId,
+		Status:         _const.TaskActive,
+		StoryPoints:    form.StoryPoints,
+		EstimatedHours: form.EstimatedHours,
+		ProjectID:      projectOId,
+		ParentID:       parentOId,
+		SprintID:       sprintOId,
+		Checklist:      make([]repository.ChecklistItem, 0),
+		StatusHistory: []repository.TaskStatusChange{
+			{To: _const.TaskActive, ActorID: c.(*context.Context).Claims.IDAsObjectID, CreatedAt: time.Now()},
+		},
+		CreatedAt: time.Now(),
+		IsDeleted: false,
+	}
+
+	// New tasks go to the bottom of their column
+	tasks, err := h.projectTasks(project.ID)
+	if err != nil {
+		return err
 	}
+	siblings := columnSiblings(project, tasks, &task)
+	task.Rank, _ = rankAt(siblings, len(siblings))
 
 	err = h.taskRepo.CreateOne(&task)
 	if err != nil {
 		log.Errorf("Failed to create task: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityCreate, nil, audit.Snapshot(&task))
+	h.notifier.Contributors(c, _const.EntityTask, task.ID, task.ProjectID, task.Name, nil, task.Contributor)
 	return c.JSON(http.StatusOK, task)
 }
 
@@ -309,6 +467,16 @@ func (h *Handler) update(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
 
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+	before := audit.Snapshot(task)
+	contributorsBefore, status := task.Contributor, task.Status
+
 	if len(form.Name) != 0 {
 		task.Name = form.Name
 	}
@@ -321,11 +489,48 @@ func (h *Handler) update(c echo.Context) error {
 	if form.EndDate != 0 {
 		task.EndDate = time.UnixMilli(form.EndDate)
 	}
+	if form.StoryPoints != nil {
+		task.StoryPoints = *form.StoryPoints
+	}
+	if form.EstimatedHours != nil {
+		task.EstimatedHours = *form.EstimatedHours
+	}
 	if len(form.Contributor) != 0 {
 		task.Contributor = contributorsOId
 	}
-	if len(form.Status) != 0 {
-		task.Status = form.Status
+	if form.ParentID == noneValue {
+		task.ParentID = bson.NilObjectID
+	} else if form.ParentID != "" {
+		parentOId, _ := bson.ObjectIDFromHex(form.ParentID)
+		if parentOId == task.ID {
+			return echo.NewHTTPError(http.StatusBadRequest, "A task cannot be its own parent.")
+		}
+		subtasks, err := h.taskRepo.FindAllByParentID(task.ID)
+		if err != nil {
+			log.Errorf("Error finding subtasks: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		if len(subtasks) != 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "A task with subtasks cannot become a subtask.")
+		}
+		if _, err := h.findParent(parentOId, task.ProjectID); err != nil {
+			return err
+		}
+		task.ParentID = parentOId
+	}
+	if form.SprintID == noneValue {
+		task.SprintID = bson.NilObjectID
+	} else if form.SprintID != "" {
+		sprintOId, _ := bson.ObjectIDFromHex(form.SprintID)
+		if err := h.checkSprint(sprintOId, task.ProjectID); err != nil {
+			return err
+		}
+		task.SprintID = sprintOId
+	}
+	if len(form.Status) != 0 && form.Status != task.Status {
+		if err := h.changeStatus(c, project, task, form.Status, ""); err != nil {
+			return err
+		}
 	}
 
 	err = h.taskRepo.UpdateOneByID(task)
@@ -333,6 +538,15 @@ func (h *Handler) update(c echo.Context) error {
 		log.Errorf("Failed to update task: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+	h.notifier.Contributors(c, _const.EntityTask, task.ID, task.ProjectID, task.Name, contributorsBefore, task.Contributor)
+	h.notifier.TaskStatus(c, task, status)
+
+	// Moved dates are saved anyway, the warnings tell the user what the new dates conflict with.
+	if err := h.fillWarnings(task); err != nil {
+		return err
+	}
 	return c.JSON(http.StatusOK, task)
 }
 
@@ -357,10 +571,730 @@ func (h *Handler) delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
 	}
 
-	err = h.taskRepo.DeleteOneByID(objectID)
+	task, err := h.taskRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleManager); err != nil {
+		return err
+	}
+
+	err = h.taskRepo.DeleteOneByID(task.ID)
 	if err != nil {
 		log.Errorf("Failed to delete task: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
 	}
+
+	// Subtasks go together with their parent.
+	err = h.taskRepo.DeleteAllByParentID(task.ID)
+	if err != nil {
+		log.Errorf("Failed to delete subtasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	err = h.taskRepo.PullBlockedBy(task.ID)
+	if err != nil {
+		log.Errorf("Failed to delete task dependencies: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityDelete, audit.Snapshot(task), nil)
 	return c.JSON(http.StatusOK, "Task deleted")
 }
+
+// Transition Task
+// @Tags Task
+// @Summary Move a task to another status following the project workflow
+// @ID task-transition
+// @Router /api/task/{id}/transition [post]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param body body transitionForm true "Transition data"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) transition(c echo.Context) error {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	form, err := newTransitionForm(c)
+	if err != nil {
+		return err
+	}
+
+	task, err := h.taskRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+
+	if form.Status == task.Status {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task is already "+task.Status+".")
+	}
+	before := audit.Snapshot(task)
+	status := task.Status
+
+	if err := h.changeStatus(c, project, task, form.Status, form.Reason); err != nil {
+		return err
+	}
+
+	if err := h.taskRepo.UpdateOneByID(task); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+	h.notifier.TaskStatus(c, task, status)
+	return c.JSON(http.StatusOK, task)
+}
+
+// Project Board
+// @Tags Task
+// @Summary Get the tasks of a project grouped by the project board columns
+// @ID task-board
+// @Router /api/project/{id}/board [get]
+// @Param id path string true "Project ID"
+// @Param q query string false "Search by name or description"
+// @Param userId query string false "Search by contributor"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) board(c echo.Context) error {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.findProject(objectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	cq := util.NewCommonQuery(c)
+	cq.Status = ""
+	cq.ProjectId = project.ID
+
+	tasks, err := h.taskRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if err := h.commentRepo.FillTaskCommentCount(tasks); err != nil {
+		log.Warnf("Error counting task comments: %v", err)
+	}
+	repository.SortTasksByRank(tasks)
+
+	columns := make([]map[string]interface{}, 0)
+	for _, column := range project.EffectiveColumns() {
+		columnTasks := make([]repository.Task, 0)
+		for _, task := range tasks {
+			if project.ColumnOf(&task) == column.Key {
+				columnTasks = append(columnTasks, task)
+			}
+		}
+		columns = append(columns, map[string]interface{}{
+			"key":      column.Key,
+			"name":     column.Name,
+			"category": column.Category,
+			"tasks":    columnTasks,
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"project_id": project.ID,
+		"columns":    columns,
+	})
+}
+
+// Move Task
+// @Tags Task
+// @Summary Move a task to a position in a board column
+// @Description Moving to a column of another status category changes the task status following the project workflow.
+// @ID task-move
+// @Router /api/task/{id}/move [post]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param body body moveForm true "Move data"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) move(c echo.Context) error {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	form, err := newMoveForm(c)
+	if err != nil {
+		return err
+	}
+
+	task, err := h.taskRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+
+	column := project.FindColumn(form.Column)
+	if column == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Column not found.")
+	}
+	before := audit.Snapshot(task)
+	status := task.Status
+
+	if column.Category != task.Status {
+		if err := h.changeStatus(c, project, task, column.Category, form.Reason); err != nil {
+			return err
+		}
+	}
+	task.Column = column.Key
+
+	tasks, err := h.projectTasks(project.ID)
+	if err != nil {
+		return err
+	}
+	siblings := columnSiblings(project, tasks, task)
+
+	rank, ok := rankAt(siblings, form.Position)
+	ranks := map[bson.ObjectID]float64{}
+	if !ok {
+		// The neighbours are too close to fit in between, spread the column out again first.
+		for i := range siblings {
+			siblings[i].Rank = float64((i + 1) * rankStep)
+			ranks[siblings[i].ID] = siblings[i].Rank
+		}
+		rank, _ = rankAt(siblings, form.Position)
+	}
+	task.Rank = rank
+
+	// The spread out ranks and the moved task are written in one bulk write, so a failure cannot leave
+	// the column half rebalanced with the task in its old place.
+	if err := h.taskRepo.UpdateOneWithRanks(task, ranks); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+	h.notifier.TaskStatus(c, task, status)
+	return c.JSON(http.StatusOK, task)
+}
+
+// columnSiblings returns the other tasks shown in the column of the task, ordered by rank
+func columnSiblings(project *repository.Project, tasks []repository.Task, task *repository.Task) []repository.Task {
+	repository.SortTasksByRank(tasks)
+
+	column := project.ColumnOf(task)
+	siblings := make([]repository.Task, 0)
+	for _, sibling := range tasks {
+		if sibling.ID != task.ID && project.ColumnOf(&sibling) == column {
+			siblings = append(siblings, sibling)
+		}
+	}
+	return siblings
+}
+
+// rankAt returns the rank placing a task at the position among the ranked siblings,
+// ok is false when the neighbours are too close to place a task strictly between them
+func rankAt(siblings []repository.Task, position int) (float64, bool) {
+	if len(siblings) == 0 {
+		return rankStep, true
+	}
+	if position <= 0 {
+		return siblings[0].Rank - rankStep, true
+	}
+	if position >= len(siblings) {
+		return siblings[len(siblings)-1].Rank + rankStep, true
+	}
+
+	prev, next := siblings[position-1].Rank, siblings[position].Rank
+	mid := prev + (next-prev)/2
+	if mid <= prev || mid >= next {
+		return 0, false
+	}
+	return mid, true
+}
+
+// Create Checklist Item
+// @Tags Task
+// @Summary Add an item to the task checklist
+// @ID task-checklist-create
+// @Router /api/task/{id}/checklist [post]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param body body checklistItemForm true "Checklist item data"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createChecklistItem(c echo.Context) error {
+	task, project, err := h.findMemberTask(c)
+	if err != nil {
+		return err
+	}
+
+	form, err := newChecklistItemForm(c, true)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(task)
+
+	item := repository.ChecklistItem{
+		ID:         bson.NewObjectID(),
+		Text:       form.Text,
+		Done:       form.Done != nil && *form.Done,
+		AssigneeID: bson.NilObjectID,
+	}
+	if err := applyAssignee(&item, form.AssigneeID, project); err != nil {
+		return err
+	}
+	task.Checklist = append(task.Checklist, item)
+
+	if err := h.taskRepo.UpdateOneByID(task); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+	return c.JSON(http.StatusOK, task)
+}
+
+// Update Checklist Item
+// @Tags Task
+// @Summary Update an item of the task checklist
+// @ID task-checklist-update
+// @Router /api/task/{id}/checklist/{itemId} [put]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param itemId path string true "Checklist item ID"
+// @Param body body checklistItemForm true "Checklist item data"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateChecklistItem(c echo.Context) error {
+	task, project, err := h.findMemberTask(c)
+	if err != nil {
+		return err
+	}
+
+	form, err := newChecklistItemForm(c, false)
+	if err != nil {
+		return err
+	}
+
+	i, err := findChecklistItem(c, task)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(task)
+
+	item := &task.Checklist[i]
+	if len(form.Text) != 0 {
+		item.Text = form.Text
+	}
+	if form.Done != nil {
+		item.Done = *form.Done
+	}
+	if err := applyAssignee(item, form.AssigneeID, project); err != nil {
+		return err
+	}
+
+	if err := h.taskRepo.UpdateOneByID(task); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+	return c.JSON(http.StatusOK, task)
+}
+
+// Delete Checklist Item
+// @Tags Task
+// @Summary Remove an item from the task checklist
+// @ID task-checklist-delete
+// @Router /api/task/{id}/checklist/{itemId} [delete]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param itemId path string true "Checklist item ID"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deleteChecklistItem(c echo.Context) error {
+	task, _, err := h.findMemberTask(c)
+	if err != nil {
+		return err
+	}
+
+	i, err := findChecklistItem(c, task)
+	if err != nil {
+		return err
+	}
+	before := audit.Snapshot(task)
+
+	task.Checklist = append(task.Checklist[:i:i], task.Checklist[i+1:]...)
+
+	if err := h.taskRepo.UpdateOneByID(task); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+	return c.JSON(http.StatusOK, task)
+}
+
+// Create Dependency
+// @Tags Task
+// @Summary Make the task depend on another task of the project
+// @Description Dependencies that would make a task depend on itself are rejected.
+// @ID task-dependency-create
+// @Router /api/task/{id}/dependency [post]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param body body dependencyForm true "Dependency data"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createDependency(c echo.Context) error {
+	task, _, err := h.findMemberTask(c)
+	if err != nil {
+		return err
+	}
+
+	form, err := newDependencyForm(c)
+	if err != nil {
+		return err
+	}
+
+	blockerOId, _ := bson.ObjectIDFromHex(form.BlockedBy)
+	if blockerOId == task.ID {
+		return echo.NewHTTPError(http.StatusBadRequest, "A task cannot depend on itself.")
+	}
+	if slices.Contains(task.BlockedBy, blockerOId) {
+		return echo.NewHTTPError(http.StatusBadRequest, "The task already depends on this task.")
+	}
+
+	tasks, err := h.projectTasks(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	index := repository.IndexTasks(tasks)
+
+	if _, ok := index[blockerOId]; !ok {
+		return echo.NewHTTPError(http.StatusBadRequest, "Task to depend on not found in this project.")
+	}
+	if repository.DependsOn(index, blockerOId, task.ID) {
+		return echo.NewHTTPError(http.StatusBadRequest, "The dependency would create a cycle, the other task already depends on this task.")
+	}
+	before := audit.Snapshot(task)
+
+	task.BlockedBy = append(task.BlockedBy, blockerOId)
+
+	if err := h.taskRepo.UpdateOneByID(task); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+
+	task.Warnings = repository.DependencyWarnings(index, task)
+	return c.JSON(http.StatusOK, task)
+}
+
+// Delete Dependency
+// @Tags Task
+// @Summary Remove a dependency of the task
+// @ID task-dependency-delete
+// @Router /api/task/{id}/dependency/{blockerId} [delete]
+// @Accept json
+// @Produce json
+// @Param id path string true "Task ID"
+// @Param blockerId path string true "ID of the task depended on"
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deleteDependency(c echo.Context) error {
+	task, _, err := h.findMemberTask(c)
+	if err != nil {
+		return err
+	}
+
+	blockerOId, err := bson.ObjectIDFromHex(c.Param("blockerId"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	i := slices.Index(task.BlockedBy, blockerOId)
+	if i < 0 {
+		return echo.NewHTTPError(http.StatusNotFound, "Dependency not found")
+	}
+	before := audit.Snapshot(task)
+
+	task.BlockedBy = slices.Delete(slices.Clone(task.BlockedBy), i, i+1)
+
+	if err := h.taskRepo.UpdateOneByID(task); err != nil {
+		log.Errorf("Failed to update task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	h.recorder.Record(c, _const.EntityTask, task.ID, task.ProjectID, _const.ActivityUpdate, before, audit.Snapshot(task))
+
+	if err := h.fillWarnings(task); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, task)
+}
+
+// Critical Path
+// @Tags Task
+// @Summary Get the longest chain of dependent tasks of the project
+// @Description The chain length is the sum of the task durations. Cancelled tasks are left out.
+// @ID task-critical-path
+// @Router /api/project/{id}/critical-path [get]
+// @Param id path string true "Project ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) criticalPath(c echo.Context) error {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.findProject(objectID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	tasks, err := h.projectTasks(project.ID)
+	if err != nil {
+		return err
+	}
+
+	index := repository.IndexTasks(tasks)
+	warnings := make([]repository.DependencyWarning, 0)
+	for i := range tasks {
+		if tasks[i].Status == _const.TaskCancelled {
+			continue
+		}
+		warnings = append(warnings, repository.DependencyWarnings(index, &tasks[i])...)
+	}
+
+	path := repository.CriticalPath(tasks)
+	duration := time.Duration(0)
+	for _, task := range path {
+		duration += task.EndDate.Sub(task.StartDate)
+	}
+
+	doc := map[string]interface{}{
+		"tasks":          path,
+		"duration_hours": duration.Hours(),
+		"start_date":     nil,
+		"end_date":       nil,
+		"warnings":       warnings,
+	}
+	if len(path) > 0 {
+		doc["start_date"] = path[0].StartDate
+		doc["end_date"] = path[len(path)-1].EndDate
+	}
+	return c.JSON(http.StatusOK, doc)
+}
+
+// projectTasks returns every task of the project
+func (h *Handler) projectTasks(projectID bson.ObjectID) ([]repository.Task, error) {
+	cq := util.NilCommonQuery()
+	cq.ProjectId = projectID
+
+	tasks, err := h.taskRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return tasks, nil
+}
+
+// fillWarnings sets the dependency warnings of the task
+func (h *Handler) fillWarnings(task *repository.Task) error {
+	if len(task.BlockedBy) == 0 {
+		return nil
+	}
+
+	tasks, err := h.projectTasks(task.ProjectID)
+	if err != nil {
+		return err
+	}
+	task.Warnings = repository.DependencyWarnings(repository.IndexTasks(tasks), task)
+	return nil
+}
+
+// findMemberTask finds the task of the id param and checks that the user is a member of its project
+func (h *Handler) findMemberTask(c echo.Context) (*repository.Task, *repository.Project, error) {
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	task, err := h.taskRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.findProject(task.ProjectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := access.RequireProjectRole(c.(*context.Context).Claims, project, _const.ProjectRoleMember); err != nil {
+		return nil, nil, err
+	}
+	return task, project, nil
+}
+
+// findParent finds the task that becomes the parent. Subtasks are one level deep and stay within their project.
+func (h *Handler) findParent(parentID, projectID bson.ObjectID) (*repository.Task, error) {
+	parent, err := h.taskRepo.FindOneByID(parentID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusBadRequest, "Parent task not found.")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if parent.ProjectID != projectID {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Parent task belongs to another project.")
+	}
+	if !parent.ParentID.IsZero() {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "A subtask cannot have subtasks.")
+	}
+	return parent, nil
+}
+
+// checkSprint checks that tasks can be moved into the sprint, it has to be an open sprint of the project
+func (h *Handler) checkSprint(sprintID, projectID bson.ObjectID) error {
+	sprint, err := h.sprintRepo.FindOneByID(sprintID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusBadRequest, "Sprint not found.")
+		}
+		log.Errorf("Error finding sprint: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if sprint.ProjectID != projectID {
+		return echo.NewHTTPError(http.StatusBadRequest, "Sprint belongs to another project.")
+	}
+	if sprint.Status == _const.SprintCompleted {
+		return echo.NewHTTPError(http.StatusBadRequest, "The sprint is already completed.")
+	}
+	return nil
+}
+
+func findChecklistItem(c echo.Context, task *repository.Task) (int, error) {
+	itemID, err := bson.ObjectIDFromHex(c.Param("itemId"))
+	if err != nil {
+		return -1, echo.NewHTTPError(http.StatusBadRequest, "Invalid checklist item ID.")
+	}
+
+	i := task.FindChecklistItem(itemID)
+	if i < 0 {
+		return -1, echo.NewHTTPError(http.StatusNotFound, "Checklist item not found")
+	}
+	return i, nil
+}
+
+// applyAssignee sets the assignee of the checklist item, assignees have to contribute to the project
+func applyAssignee(item *repository.ChecklistItem, assigneeID string, project *repository.Project) error {
+	switch assigneeID {
+	case "":
+		return nil
+	case noneValue:
+		item.AssigneeID = bson.NilObjectID
+		return nil
+	}
+
+	assigneeOId, _ := bson.ObjectIDFromHex(assigneeID)
+	if !slices.Contains(project.Contributor, assigneeOId) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Assignee is not a contributor of this project.")
+	}
+	item.AssigneeID = assigneeOId
+	return nil
+}
+
+// changeStatus moves the task to the status if the project workflow allows the user to do so
+func (h *Handler) changeStatus(c echo.Context, project *repository.Project, task *repository.Task, status, reason string) error {
+	uc := c.(*context.Context)
+
+	transition := project.FindTransition(task.Status, status)
+	if transition == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "The project workflow does not allow moving a task from "+task.Status+" to "+status+".")
+	}
+
+	position := ""
+	if len(transition.Positions) != 0 {
+		user, err := h.userRepo.FindOneByID(uc.Claims.IDAsObjectID)
+		if err != nil {
+			log.Errorf("Error finding user: %v", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+		}
+		position = user.Position
+	}
+
+	if !access.CanTransition(uc.Claims, position, project, transition) {
+		return echo.NewHTTPError(http.StatusForbidden, "You are not allowed to move a task from "+task.Status+" to "+status+".")
+	}
+
+	task.SetStatus(status, reason, uc.Claims.IDAsObjectID)
+	return nil
+}
+
+func (h *Handler) findProject(projectID bson.ObjectID) (*repository.Project, error) {
+	project, err := h.projectRepo.FindOneByID(projectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return project, nil
+}
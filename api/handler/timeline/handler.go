@@ -0,0 +1,347 @@
+package timeline
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"math"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"strings"
+	"time"
+)
+
+const (
+	groupByStatus      = "status"
+	groupByContributor = "contributor"
+
+	barTask     = "task"
+	barSchedule = "schedule"
+
+	unassignedKey = "unassigned"
+	schedulesKey  = "schedules"
+)
+
+type Handler struct {
+	userRepo      *repository.UserCollRepository
+	projectRepo   *repository.ProjectCollRepository
+	taskRepo      *repository.TaskCollRepository
+	scheduleRepo  *repository.ScheduleCollRepository
+	milestoneRepo *repository.MilestoneCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:      repository.NewUserCollRepository(db),
+		projectRepo:   repository.NewProjectCollRepository(db),
+		taskRepo:      repository.NewTaskCollRepository(db),
+		scheduleRepo:  repository.NewScheduleCollRepository(db),
+		milestoneRepo: repository.NewMilestoneCollRepository(db),
+	}
+
+	timeline := e.Group("/api", context.ContextHandler)
+
+	timeline.GET("/project/:id/timeline", h.timeline)
+
+	return h
+}
+
+type bar struct {
+	ID           string          `json:"id"`
+	Kind         string          `json:"kind"` // task, schedule
+	RefID        bson.ObjectID   `json:"ref_id"`
+	Name         string          `json:"name"`
+	Status       string          `json:"status,omitempty"`
+	Type         string          `json:"type,omitempty"`
+	StartDate    time.Time       `json:"start_date"`
+	EndDate      time.Time       `json:"end_date"`
+	Contributor  []bson.ObjectID `json:"contributor"`
+	Left         float64         `json:"left"`  // percent of the window before the bar starts
+	Width        float64         `json:"width"` // percent of the window covered by the bar
+	ClippedStart bool            `json:"clipped_start"`
+	ClippedEnd   bool            `json:"clipped_end"`
+}
+
+type group struct {
+	Key    string   `json:"key"`
+	Name   string   `json:"name"`
+	BarIDs []string `json:"bar_ids"`
+}
+
+type marker struct {
+	ID         bson.ObjectID `json:"_id"`
+	Name       string        `json:"name"`
+	DueDate    time.Time     `json:"due_date"`
+	Completion float64       `json:"completion"`
+	Left       float64       `json:"left"` // percent of the window before the due date
+}
+
+type dependency struct {
+	From    string `json:"from"` // bar of the task depended on
+	To      string `json:"to"`
+	Warning bool   `json:"warning"` // the dependent task starts before the other one ends
+}
+
+// Project Timeline
+// @Tags Timeline
+// @Summary Get the tasks, dependencies, schedules and milestones of a project positioned on a timeline
+// @Description Bars are listed once and referenced by the groups. Left and width are percentages of the start to end window,
+// @Description which defaults to the project dates. Tasks appear in the group of every contributor when grouping by contributor.
+// @ID project-timeline
+// @Router /api/project/{id}/timeline [get]
+// @Param id path string true "Project ID"
+// @Param groupBy query string false "Group bars by" Enums(status, contributor)
+// @Param start query string false "Window start date"
+// @Param end query string false "Window end date"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) timeline(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid project ID.")
+	}
+
+	project, err := h.projectRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	groupBy := strings.ToLower(strings.TrimSpace(c.QueryParam("groupBy")))
+	if groupBy == "" {
+		groupBy = groupByStatus
+	}
+	if groupBy != groupByStatus && groupBy != groupByContributor {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid group by, use status or contributor.")
+	}
+
+	cq := util.NewCommonQuery(c)
+	cq.Q = ""
+	cq.Status = ""
+	cq.Type = ""
+	cq.UserId = bson.NilObjectID
+	cq.ProjectId = project.ID
+	if len(c.QueryParam("start")) == 0 {
+		cq.Start = project.StartDate
+	}
+	if len(c.QueryParam("end")) == 0 {
+		cq.End = project.EndDate
+	}
+	if !cq.End.After(cq.Start) {
+		return echo.NewHTTPError(http.StatusBadRequest, "End date must be after the start date.")
+	}
+
+	tasks, err := h.taskRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	repository.SortTasksByRank(tasks)
+
+	schedules, err := h.scheduleRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	bars := make([]bar, 0)
+	for _, task := range tasks {
+		bars = append(bars, newBar(task.ID.Hex(), barTask, task.ID, task.Name, task.StartDate, task.EndDate, task.Contributor, cq))
+		bars[len(bars)-1].Status = task.Status
+	}
+	for _, schedule := range schedules {
+		for _, occurrence := range schedule.Occurrences(cq.Start, cq.End) {
+			id := schedule.ID.Hex() + "-" + occurrence.StartDate.Format("20060102")
+			start := atTime(occurrence.StartDate, occurrence.StartTime)
+			end := atTime(occurrence.EndDate, occurrence.EndTime)
+			bars = append(bars, newBar(id, barSchedule, schedule.ID, occurrence.Name, start, end, occurrence.Contributor, cq))
+			bars[len(bars)-1].Type = occurrence.Type
+		}
+	}
+
+	var groups []group
+	if groupBy == groupByContributor {
+		groups = h.groupByContributor(project, bars)
+	} else {
+		groups = groupByStatusCategory(bars)
+	}
+
+	index := repository.IndexTasks(tasks)
+	dependencies := make([]dependency, 0)
+	for i := range tasks {
+		warned := map[bson.ObjectID]bool{}
+		for _, warning := range repository.DependencyWarnings(index, &tasks[i]) {
+			warned[warning.BlockedByID] = true
+		}
+		for _, blockerID := range tasks[i].BlockedBy {
+			if _, ok := index[blockerID]; !ok {
+				continue
+			}
+			dependencies = append(dependencies, dependency{
+				From:    blockerID.Hex(),
+				To:      tasks[i].ID.Hex(),
+				Warning: warned[blockerID],
+			})
+		}
+	}
+
+	milestones, err := h.milestoneRepo.FindAllByProjectID(project.ID)
+	if err != nil {
+		log.Errorf("Error finding milestone: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if err := h.milestoneRepo.FillCompletion(milestones, h.taskRepo); err != nil {
+		log.Errorf("Error finding milestone tasks: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	markers := make([]marker, 0)
+	for _, milestone := range milestones {
+		if milestone.DueDate.Before(cq.Start) || milestone.DueDate.After(cq.End) {
+			continue
+		}
+		markers = append(markers, marker{
+			ID:         milestone.ID,
+			Name:       milestone.Name,
+			DueDate:    milestone.DueDate,
+			Completion: milestone.Completion,
+			Left:       percent(float64(milestone.DueDate.Sub(cq.Start)) / float64(cq.End.Sub(cq.Start))),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"project_id":   project.ID,
+		"start":        cq.Start,
+		"end":          cq.End,
+		"group_by":     groupBy,
+		"groups":       groups,
+		"bars":         bars,
+		"dependencies": dependencies,
+		"milestones":   markers,
+	})
+}
+
+// groupByContributor makes a group per project contributor in project order, followed by anyone else on a bar
+// and a group for bars without contributors
+func (h *Handler) groupByContributor(project *repository.Project, bars []bar) []group {
+	order := make([]bson.ObjectID, 0)
+	groups := map[bson.ObjectID]*group{}
+	add := func(userID bson.ObjectID) *group {
+		if g, ok := groups[userID]; ok {
+			return g
+		}
+		g := &group{Key: userID.Hex(), BarIDs: make([]string, 0)}
+		if user, err := h.userRepo.FindOneByID(userID); err == nil {
+			g.Name = user.Name
+		}
+		groups[userID] = g
+		order = append(order, userID)
+		return g
+	}
+
+	for _, userID := range project.Contributor {
+		add(userID)
+	}
+	unassigned := group{Key: unassignedKey, Name: "Unassigned", BarIDs: make([]string, 0)}
+	for _, b := range bars {
+		if len(b.Contributor) == 0 {
+			unassigned.BarIDs = append(unassigned.BarIDs, b.ID)
+		}
+		for _, userID := range b.Contributor {
+			g := add(userID)
+			g.BarIDs = append(g.BarIDs, b.ID)
+		}
+	}
+
+	result := make([]group, 0)
+	for _, userID := range order {
+		result = append(result, *groups[userID])
+	}
+	return append(result, unassigned)
+}
+
+// groupByStatusCategory makes a group per task status followed by a group for schedules
+func groupByStatusCategory(bars []bar) []group {
+	result := []group{
+		{Key: _const.TaskActive, Name: "Active", BarIDs: make([]string, 0)},
+		{Key: _const.TaskTesting, Name: "Testing", BarIDs: make([]string, 0)},
+		{Key: _const.TaskCompleted, Name: "Completed", BarIDs: make([]string, 0)},
+		{Key: _const.TaskCancelled, Name: "Cancelled", BarIDs: make([]string, 0)},
+		{Key: schedulesKey, Name: "Schedules", BarIDs: make([]string, 0)},
+	}
+
+	for _, b := range bars {
+		key := schedulesKey
+		if b.Kind == barTask {
+			key = b.Status
+		}
+		for i := range result {
+			if result[i].Key == key {
+				result[i].BarIDs = append(result[i].BarIDs, b.ID)
+			}
+		}
+	}
+	return result
+}
+
+// newBar positions a bar in the window of the query, bars reaching outside the window are clipped to it
+func newBar(id, kind string, refID bson.ObjectID, name string, start, end time.Time, contributor []bson.ObjectID, cq *util.CommonQuery) bar {
+	b := bar{
+		ID:          id,
+		Kind:        kind,
+		RefID:       refID,
+		Name:        name,
+		StartDate:   start,
+		EndDate:     end,
+		Contributor: contributor,
+	}
+
+	from, to := start, end
+	if from.Before(cq.Start) {
+		from = cq.Start
+		b.ClippedStart = true
+	}
+	if to.After(cq.End) {
+		to = cq.End
+		b.ClippedEnd = true
+	}
+	if to.Before(from) {
+		to = from
+	}
+
+	window := float64(cq.End.Sub(cq.Start))
+	b.Left = percent(float64(from.Sub(cq.Start)) / window)
+	b.Width = percent(float64(to.Sub(from)) / window)
+	return b
+}
+
+// percent turns a ratio into a percentage rounded to two decimals
+func percent(ratio float64) float64 {
+	return math.Round(ratio*10000) / 100
+}
+
+// atTime returns the day at a 24-hour HH:MM time in the server timezone
+func atTime(day time.Time, hhmm string) time.Time {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return day
+	}
+	day = day.Local()
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+}
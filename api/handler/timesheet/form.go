@@ -0,0 +1,158 @@
+package timesheet
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"net/http"
+	"proman-backend/internal/pkg/log"
+	"strings"
+)
+
+const (
+	maxNoteLength  = 1000
+	maxDuration    = 24 * 60 // minutes, a single entry covers at most a day
+	maxWeeks       = 12
+	defaultWeeks   = 1
+	millisInMinute = 60 * 1000
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type timeEntryForm struct {
+	// either a start and end date, or a duration
+	StartDate int64  `json:"start_date" form:"start_date"`
+	EndDate   int64  `json:"end_date" form:"end_date"`
+	Duration  int64  `json:"duration" form:"duration"` // minutes, starts at start_date or now
+	Note      string `json:"note" form:"note"`
+}
+
+func newTimeEntryForm(c echo.Context, create bool) (*timeEntryForm, error) {
+	form := new(timeEntryForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding time entry form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Note = strings.TrimSpace(form.Note)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate start date
+	if form.StartDate < 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "start_date",
+			Message: "Invalid start date.",
+		})
+	}
+
+	// Validate end date and duration
+	if form.EndDate != 0 && form.Duration != 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "duration",
+			Message: "Use either an end date or a duration.",
+		})
+	} else if form.EndDate != 0 {
+		if form.StartDate == 0 || form.EndDate <= form.StartDate {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "end_date",
+				Message: "End date must be after the start date.",
+			})
+		} else if form.EndDate-form.StartDate > maxDuration*millisInMinute {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "end_date",
+				Message: "A time entry can be at most 24 hours.",
+			})
+		}
+	} else if form.Duration != 0 || create {
+		if form.Duration <= 0 || form.Duration > maxDuration {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "duration",
+				Message: "Duration must be between 1 and 1440 minutes.",
+			})
+		}
+	}
+
+	// Validate note
+	if len(form.Note) > maxNoteLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "note",
+			Message: "Note must be at most 1000 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type timerForm struct {
+	TaskID string `json:"task_id" form:"task_id"`
+	Note   string `json:"note" form:"note"`
+}
+
+func newTimerForm(c echo.Context) (*timerForm, error) {
+	form := new(timerForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding timer form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.TaskID = strings.TrimSpace(form.TaskID)
+	form.Note = strings.TrimSpace(form.Note)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate task ID
+	if _, err := bson.ObjectIDFromHex(form.TaskID); err != nil {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "task_id",
+			Message: "Invalid task ID.",
+		})
+	}
+
+	// Validate note
+	if len(form.Note) > maxNoteLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "note",
+			Message: "Note must be at most 1000 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type stopTimerForm struct {
+	Note string `json:"note" form:"note"` // optional, replaces the note given on start
+}
+
+func newStopTimerForm(c echo.Context) (*stopTimerForm, error) {
+	form := new(stopTimerForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding stop timer form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Note = strings.TrimSpace(form.Note)
+
+	// Validate note
+	if len(form.Note) > maxNoteLength {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": []errorDoc{{Field: "note", Message: "Note must be at most 1000 characters."}},
+		})
+	}
+	return form, nil
+}
@@ -0,0 +1,617 @@
+package timesheet
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/access"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Handler struct {
+	userRepo      *repository.UserCollRepository
+	projectRepo   *repository.ProjectCollRepository
+	taskRepo      *repository.TaskCollRepository
+	timeEntryRepo *repository.TimeEntryCollRepository
+	calendarRepo  *repository.WorkingCalendarCollRepository
+	holidayRepo   *repository.HolidayCollRepository
+	leaveRepo     *repository.LeaveCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:      repository.NewUserCollRepository(db),
+		projectRepo:   repository.NewProjectCollRepository(db),
+		taskRepo:      repository.NewTaskCollRepository(db),
+		timeEntryRepo: repository.NewTimeEntryCollRepository(db),
+		calendarRepo:  repository.NewWorkingCalendarCollRepository(db),
+		holidayRepo:   repository.NewHolidayCollRepository(db),
+		leaveRepo:     repository.NewLeaveCollRepository(db),
+	}
+
+	timesheet := e.Group("/api", context.ContextHandler)
+
+	timesheet.GET("/task/:id/time-entries", h.taskEntries)
+	timesheet.GET("/me/timer", h.myTimer)
+	timesheet.GET("/me/timesheet", h.myTimesheet)
+	timesheet.GET("/timesheets", h.timesheets, context.AdminOnly)
+
+	timesheet.POST("/task/:id/time-entries", h.createEntry)
+	timesheet.POST("/me/timer/start", h.startTimer)
+	timesheet.POST("/me/timer/stop", h.stopTimer)
+
+	timesheet.PUT("/time-entry/:id", h.updateEntry)
+
+	timesheet.DELETE("/time-entry/:id", h.deleteEntry)
+
+	return h
+}
+
+type week struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	WorkingDays int       `json:"working_days"` // of the organization calendar, without weekends and holidays
+	Hours       float64   `json:"hours"`
+	OffDayHours float64   `json:"off_day_hours"` // part of the hours logged on weekends, holidays or leave days
+}
+
+// Hours logged on a day off still count as worked, they are reported apart so overtime stands out
+// against the capacity, which only covers working days.
+type person struct {
+	User        map[string]interface{} `json:"user"`
+	Hours       float64                `json:"hours"`
+	OffDayHours float64                `json:"off_day_hours"` // part of the hours logged on weekends, holidays or leave days
+	Capacity    float64                `json:"capacity"`      // hours per day over the working days of the user, leaves left out
+}
+
+type row struct {
+	User    map[string]interface{} `json:"user"`
+	Project map[string]interface{} `json:"project"`
+	Hours   []float64              `json:"hours"` // per week, in the order of the weeks
+	Total   float64                `json:"total"`
+}
+
+// Task Time Entries
+// @Tags Timesheet
+// @Summary Get the time entries of a task
+// @ID task-time-entries
+// @Router /api/task/{id}/time-entries [get]
+// @Param id path string true "Task ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) taskEntries(c echo.Context) error {
+	task, project, err := h.findTask(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if err := access.RequireViewProject(c.(*context.Context).Claims, project); err != nil {
+		return err
+	}
+
+	entries, err := h.timeEntryRepo.FindAllByTaskID(task.ID)
+	if err != nil {
+		log.Errorf("Error finding time entry: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	var total float64
+	for i := range entries {
+		total += entries[i].Hours()
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"entries":         entries,
+		"logged_hours":    util.Round(total),
+		"estimated_hours": task.EstimatedHours,
+	})
+}
+
+// My Timer
+// @Tags Timesheet
+// @Summary Get the running timer of the logged-in user
+// @ID my-timer
+// @Router /api/me/timer [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) myTimer(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	entry, err := h.timeEntryRepo.FindRunningByUserID(uc.Claims.IDAsObjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "No timer is running")
+		}
+		log.Errorf("Error finding timer: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// My Timesheet
+// @Tags Timesheet
+// @Summary Get the hours of the logged-in user per project and week
+// @Description Weeks count their working days, users their capacity over the working days without their leaves.
+// @ID my-timesheet
+// @Router /api/me/timesheet [get]
+// @Param week query int false "Week offset of the last week, 0 is the current week"
+// @Param weeks query int false "Number of weeks, default 1, at most 12"
+// @Param projectId query string false "Search by project"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) myTimesheet(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	cq.ProjectIds = nil
+	cq.UserId = c.(*context.Context).Claims.IDAsObjectID
+	return h.render(c, cq)
+}
+
+// Timesheets
+// @Tags Timesheet
+// @Summary Get the hours of every user per project and week
+// @Description Weeks count their working days, users their capacity over the working days without their leaves.
+// @ID timesheets
+// @Router /api/timesheets [get]
+// @Param week query int false "Week offset of the last week, 0 is the current week"
+// @Param weeks query int false "Number of weeks, default 1, at most 12"
+// @Param userId query string false "Search by user"
+// @Param projectId query string false "Search by project"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) timesheets(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	cq.ProjectIds = nil
+	return h.render(c, cq)
+}
+
+// Create Time Entry
+// @Tags Timesheet
+// @Summary Log time on a task
+// @ID create-time-entry
+// @Router /api/task/{id}/time-entries [post]
+// @Param id path string true "Task ID"
+// @Param body body timeEntryForm true "Time entry data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createEntry(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	task, project, err := h.findTask(c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(uc.Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+
+	form, err := newTimeEntryForm(c, true)
+	if err != nil {
+		return err
+	}
+
+	entry := repository.TimeEntry{
+		ID:        bson.NewObjectID(),
+		UserID:    uc.Claims.IDAsObjectID,
+		TaskID:    task.ID,
+		ProjectID: task.ProjectID,
+		StartDate: time.Now(),
+		Note:      form.Note,
+		IsRunning: false,
+		CreatedAt: time.Now(),
+		IsDeleted: false,
+	}
+	applyPeriod(&entry, form)
+
+	if err := h.timeEntryRepo.CreateOne(&entry); err != nil {
+		log.Errorf("Failed to create time entry: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// Start Timer
+// @Tags Timesheet
+// @Summary Start a timer on a task, a user can run one timer at a time
+// @ID start-timer
+// @Router /api/me/timer/start [post]
+// @Param body body timerForm true "Timer data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) startTimer(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	form, err := newTimerForm(c)
+	if err != nil {
+		return err
+	}
+
+	task, project, err := h.findTask(form.TaskID)
+	if err != nil {
+		return err
+	}
+	if err := access.RequireProjectRole(uc.Claims, project, _const.ProjectRoleMember); err != nil {
+		return err
+	}
+
+	entry := repository.TimeEntry{
+		ID:        bson.NewObjectID(),
+		UserID:    uc.Claims.IDAsObjectID,
+		TaskID:    task.ID,
+		ProjectID: task.ProjectID,
+		StartDate: time.Now(),
+		Note:      form.Note,
+		IsRunning: true,
+		CreatedAt: time.Now(),
+		IsDeleted: false,
+	}
+
+	started, err := h.timeEntryRepo.StartTimer(&entry)
+	if err != nil {
+		log.Errorf("Failed to start timer: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !started {
+		return echo.NewHTTPError(http.StatusConflict, "A timer is already running, stop it first.")
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// Stop Timer
+// @Tags Timesheet
+// @Summary Stop the running timer and log its time
+// @ID stop-timer
+// @Router /api/me/timer/stop [post]
+// @Param body body stopTimerForm false "Timer data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) stopTimer(c echo.Context) error {
+	uc := c.(*context.Context)
+
+	form, err := newStopTimerForm(c)
+	if err != nil {
+		return err
+	}
+
+	entry, err := h.timeEntryRepo.FindRunningByUserID(uc.Claims.IDAsObjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "No timer is running")
+		}
+		log.Errorf("Error finding timer: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	entry.EndDate = time.Now()
+	entry.Duration = int64(entry.EndDate.Sub(entry.StartDate).Seconds())
+	entry.IsRunning = false
+	if len(form.Note) != 0 {
+		entry.Note = form.Note
+	}
+
+	stopped, err := h.timeEntryRepo.StopTimer(entry)
+	if err != nil {
+		log.Errorf("Failed to stop timer: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	if !stopped {
+		return echo.NewHTTPError(http.StatusNotFound, "No timer is running")
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// Update Time Entry
+// @Tags Timesheet
+// @Summary Update a logged time entry
+// @ID update-time-entry
+// @Router /api/time-entry/{id} [put]
+// @Param id path string true "Time entry ID"
+// @Param body body timeEntryForm true "Time entry data"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateEntry(c echo.Context) error {
+	entry, err := h.findOwnEntry(c)
+	if err != nil {
+		return err
+	}
+	if entry.IsRunning {
+		return echo.NewHTTPError(http.StatusBadRequest, "Stop the timer before changing it.")
+	}
+
+	form, err := newTimeEntryForm(c, false)
+	if err != nil {
+		return err
+	}
+
+	if form.StartDate != 0 && form.EndDate == 0 && form.Duration == 0 {
+		// moving the entry keeps its duration to the second
+		entry.StartDate = time.UnixMilli(form.StartDate)
+		entry.EndDate = entry.StartDate.Add(time.Duration(entry.Duration) * time.Second)
+	} else {
+		if form.StartDate == 0 && form.Duration != 0 {
+			form.StartDate = entry.StartDate.UnixMilli()
+		}
+		applyPeriod(entry, form)
+	}
+	if entry.Duration <= 0 || entry.Duration > maxDuration*60 {
+		return echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": []errorDoc{{Field: "duration", Message: "A time entry must be between 1 second and 24 hours."}},
+		})
+	}
+	if len(form.Note) != 0 {
+		entry.Note = form.Note
+	}
+
+	if err := h.timeEntryRepo.UpdateOneByID(entry); err != nil {
+		log.Errorf("Failed to update time entry: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, entry)
+}
+
+// Delete Time Entry
+// @Tags Timesheet
+// @Summary Delete a time entry, a running timer is discarded
+// @ID delete-time-entry
+// @Router /api/time-entry/{id} [delete]
+// @Param id path string true "Time entry ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deleteEntry(c echo.Context) error {
+	entry, err := h.findOwnEntry(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.timeEntryRepo.DeleteOneByID(entry.ID); err != nil {
+		log.Errorf("Failed to delete time entry: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, "Time entry deleted")
+}
+
+// render aggregates the stopped entries of the query into hours per user, project and week
+func (h *Handler) render(c echo.Context, cq *util.CommonQuery) error {
+	offset, weekCount, err := weekParams(c)
+	if err != nil {
+		return err
+	}
+
+	weeks := make([]week, weekCount)
+	for i := range weeks {
+		weeks[i] = week{
+			Start: util.StartOfWeek(offset - weekCount + 1 + i),
+			End:   util.EndOfWeek(offset - weekCount + 1 + i),
+		}
+	}
+	cq.Start = weeks[0].Start
+	cq.End = util.StartOfWeek(offset + 1)
+
+	calendar, err := h.calendarRepo.Load(cq.Start, cq.End, h.holidayRepo, h.leaveRepo)
+	if err != nil {
+		log.Errorf("Error loading working calendar: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	for i := range weeks {
+		weeks[i].WorkingDays = calendar.CountWorkingDays(weeks[i].Start, weeks[i].End, bson.NilObjectID)
+	}
+
+	entries, err := h.timeEntryRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding time entry: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	rows := make([]*row, 0)
+	index := map[[2]bson.ObjectID]*row{}
+	people := make([]*person, 0)
+	peopleIndex := map[bson.ObjectID]*person{}
+	users := map[bson.ObjectID]map[string]interface{}{}
+	projects := map[bson.ObjectID]map[string]interface{}{}
+	var total float64
+	for i := range entries {
+		entry := &entries[i]
+		w := -1
+		for j := range weeks {
+			if !entry.StartDate.Before(weeks[j].Start) && entry.StartDate.Before(weeks[j].End.Add(time.Second)) {
+				w = j
+				break
+			}
+		}
+		if w < 0 {
+			continue
+		}
+
+		key := [2]bson.ObjectID{entry.UserID, entry.ProjectID}
+		r, ok := index[key]
+		if !ok {
+			r = &row{
+				User:    h.userRef(users, entry.UserID),
+				Project: h.projectRef(projects, entry.ProjectID),
+				Hours:   make([]float64, len(weeks)),
+			}
+			index[key] = r
+			rows = append(rows, r)
+		}
+
+		p, ok := peopleIndex[entry.UserID]
+		if !ok {
+			p = &person{
+				User:     r.User,
+				Capacity: float64(calendar.CountWorkingDays(cq.Start, weeks[len(weeks)-1].End, entry.UserID)) * calendar.HoursPerDay,
+			}
+			peopleIndex[entry.UserID] = p
+			people = append(people, p)
+		}
+
+		hours := entry.Hours()
+		p.Hours += hours
+		r.Hours[w] += hours
+		r.Total += hours
+		weeks[w].Hours += hours
+		total += hours
+		if !calendar.IsWorkingDay(entry.StartDate, entry.UserID) {
+			p.OffDayHours += hours
+			weeks[w].OffDayHours += hours
+		}
+	}
+
+	for _, r := range rows {
+		for i := range r.Hours {
+			r.Hours[i] = util.Round(r.Hours[i])
+		}
+		r.Total = util.Round(r.Total)
+	}
+	for i := range weeks {
+		weeks[i].Hours = util.Round(weeks[i].Hours)
+		weeks[i].OffDayHours = util.Round(weeks[i].OffDayHours)
+	}
+	for _, p := range people {
+		p.Hours = util.Round(p.Hours)
+		p.OffDayHours = util.Round(p.OffDayHours)
+		p.Capacity = util.Round(p.Capacity)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"start": cq.Start,
+		"end":   cq.End,
+		"weeks": weeks,
+		"rows":  rows,
+		"users": people,
+		"total": util.Round(total),
+	})
+}
+
+func (h *Handler) userRef(cache map[bson.ObjectID]map[string]interface{}, userID bson.ObjectID) map[string]interface{} {
+	if ref, ok := cache[userID]; ok {
+		return ref
+	}
+	ref := map[string]interface{}{"_id": userID}
+	if user, err := h.userRepo.FindOneByID(userID); err == nil {
+		ref["name"] = user.Name
+	}
+	cache[userID] = ref
+	return ref
+}
+
+func (h *Handler) projectRef(cache map[bson.ObjectID]map[string]interface{}, projectID bson.ObjectID) map[string]interface{} {
+	if ref, ok := cache[projectID]; ok {
+		return ref
+	}
+	ref := map[string]interface{}{"_id": projectID}
+	if project, err := h.projectRepo.FindOneByID(projectID); err == nil {
+		ref["name"] = project.Name
+	}
+	cache[projectID] = ref
+	return ref
+}
+
+func (h *Handler) findTask(id string) (*repository.Task, *repository.Project, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid task ID.")
+	}
+
+	task, err := h.taskRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		}
+		log.Errorf("Error finding task: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	project, err := h.projectRepo.FindOneByID(task.ProjectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, echo.NewHTTPError(http.StatusNotFound, "Project not found")
+		}
+		log.Errorf("Error finding project: %v", err)
+		return nil, nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return task, project, nil
+}
+
+// findOwnEntry finds the time entry of the path, only its user and admins may change it
+func (h *Handler) findOwnEntry(c echo.Context) (*repository.TimeEntry, error) {
+	claims := c.(*context.Context).Claims
+
+	objectID, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid time entry ID.")
+	}
+
+	entry, err := h.timeEntryRepo.FindOneByID(objectID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, echo.NewHTTPError(http.StatusNotFound, "Time entry not found")
+		}
+		log.Errorf("Error finding time entry: %v", err)
+		return nil, echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if entry.UserID != claims.IDAsObjectID && !claims.IsAdmin() {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "You can only change your own time entries.")
+	}
+	return entry, nil
+}
+
+// applyPeriod sets the start, end and duration of the entry from the form
+func applyPeriod(entry *repository.TimeEntry, form *timeEntryForm) {
+	if form.StartDate != 0 {
+		entry.StartDate = time.UnixMilli(form.StartDate)
+	}
+	if form.EndDate != 0 {
+		entry.EndDate = time.UnixMilli(form.EndDate)
+	} else if form.Duration != 0 {
+		entry.EndDate = entry.StartDate.Add(time.Duration(form.Duration) * time.Minute)
+	}
+	entry.Duration = int64(entry.EndDate.Sub(entry.StartDate).Seconds())
+}
+
+// weekParams reads the offset of the last week and the number of weeks
+func weekParams(c echo.Context) (int, int, error) {
+	offset := 0
+	if param := strings.TrimSpace(c.QueryParam("week")); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil {
+			return 0, 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid week.")
+		}
+		offset = value
+	}
+
+	weeks := defaultWeeks
+	if param := strings.TrimSpace(c.QueryParam("weeks")); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 1 || value > maxWeeks {
+			return 0, 0, echo.NewHTTPError(http.StatusBadRequest, "Weeks must be between 1 and 12.")
+		}
+		weeks = value
+	}
+	return offset, weeks, nil
+}
@@ -0,0 +1,175 @@
+package workday
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"proman-backend/internal/pkg/log"
+	"strings"
+)
+
+const (
+	maxHoursPerDay    = 24
+	maxNameLength     = 100
+	maxReasonLength   = 500
+	maxLeaveDays      = 366
+	maxImportSize     = 1_048_576 // 1MB
+	maxHolidayLength  = 31        // days of one imported event
+	maxWindowYears    = 10
+	millisecondsOfDay = 24 * 60 * 60 * 1000
+)
+
+type errorDoc struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type calendarForm struct {
+	WorkingDays []int   `json:"working_days" form:"working_days"` // weekdays, 0 is Sunday
+	HoursPerDay float64 `json:"hours_per_day" form:"hours_per_day"`
+}
+
+func newCalendarForm(c echo.Context) (*calendarForm, error) {
+	form := new(calendarForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding calendar form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	seen := map[int]bool{}
+	days := make([]int, 0)
+	for _, day := range form.WorkingDays {
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	form.WorkingDays = days
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate working days
+	for _, day := range form.WorkingDays {
+		if day < 0 || day > 6 {
+			validationErrors = append(validationErrors, errorDoc{
+				Field:   "working_days",
+				Message: "Working days must be weekdays from 0 (Sunday) to 6 (Saturday).",
+			})
+			break
+		}
+	}
+
+	// Validate hours per day
+	if form.HoursPerDay <= 0 || form.HoursPerDay > maxHoursPerDay {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "hours_per_day",
+			Message: "Hours per day must be more than 0 and at most 24.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type holidayForm struct {
+	Date int64  `json:"date" form:"date"`
+	Name string `json:"name" form:"name"`
+}
+
+func newHolidayForm(c echo.Context) (*holidayForm, error) {
+	form := new(holidayForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding holiday form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Name = strings.TrimSpace(form.Name)
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate date
+	if form.Date <= 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "date",
+			Message: "Invalid date.",
+		})
+	}
+
+	// Validate name
+	if len(form.Name) == 0 || len(form.Name) > maxNameLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "name",
+			Message: "Name must be between 1 and 100 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
+
+type leaveForm struct {
+	StartDate int64  `json:"start_date" form:"start_date"` // first day off
+	EndDate   int64  `json:"end_date" form:"end_date"`     // last day off, the start date for a single day
+	Reason    string `json:"reason" form:"reason"`
+}
+
+func newLeaveForm(c echo.Context) (*leaveForm, error) {
+	form := new(leaveForm)
+	if err := c.Bind(form); err != nil {
+		log.Errorf("Error binding leave form: %v", err)
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid data format.")
+	}
+
+	// Sanitize inputs
+	form.Reason = strings.TrimSpace(form.Reason)
+	if form.EndDate == 0 {
+		form.EndDate = form.StartDate
+	}
+
+	validationErrors := make([]errorDoc, 0)
+
+	// Validate start date
+	if form.StartDate <= 0 {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "start_date",
+			Message: "Invalid start date.",
+		})
+	}
+
+	// Validate end date
+	if form.EndDate < form.StartDate {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "end_date",
+			Message: "End date must not be before the start date.",
+		})
+	} else if form.EndDate-form.StartDate >= maxLeaveDays*millisecondsOfDay {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "end_date",
+			Message: "A leave can be at most 366 days.",
+		})
+	}
+
+	// Validate reason
+	if len(form.Reason) > maxReasonLength {
+		validationErrors = append(validationErrors, errorDoc{
+			Field:   "reason",
+			Message: "Reason must be at most 500 characters.",
+		})
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
+			"errors": validationErrors,
+		})
+	}
+	return form, nil
+}
@@ -0,0 +1,467 @@
+package workday
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/ical"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Handler struct {
+	userRepo     *repository.UserCollRepository
+	calendarRepo *repository.WorkingCalendarCollRepository
+	holidayRepo  *repository.HolidayCollRepository
+	leaveRepo    *repository.LeaveCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:     repository.NewUserCollRepository(db),
+		calendarRepo: repository.NewWorkingCalendarCollRepository(db),
+		holidayRepo:  repository.NewHolidayCollRepository(db),
+		leaveRepo:    repository.NewLeaveCollRepository(db),
+	}
+
+	workday := e.Group("/api", context.ContextHandler)
+
+	workday.GET("/working-calendar", h.calendar)
+	workday.GET("/working-days", h.workingDays)
+	workday.GET("/holidays", h.holidays)
+	workday.GET("/me/leaves", h.myLeaves)
+
+	workday.POST("/me/leaves", h.createLeave)
+
+	workday.DELETE("/leave/:id", h.deleteLeave)
+
+	admin := e.Group("/api/admin", context.ContextHandler, context.AdminOnly)
+
+	admin.GET("/leaves", h.leaves)
+
+	admin.POST("/holidays", h.createHoliday)
+	admin.POST("/holidays/import", h.importHolidays)
+
+	admin.PUT("/working-calendar", h.updateCalendar)
+
+	admin.DELETE("/holiday/:id", h.deleteHoliday)
+
+	return h
+}
+
+// Working Calendar
+// @Tags Working Calendar
+// @Summary Get the working weekdays and hours of the organization
+// @ID working-calendar
+// @Router /api/working-calendar [get]
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) calendar(c echo.Context) error {
+	calendar, err := h.calendarRepo.Find()
+	if err != nil {
+		log.Errorf("Error finding working calendar: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, calendar)
+}
+
+// Update Working Calendar
+// @Tags Admin
+// @Summary Update the working weekdays and hours of the organization
+// @ID admin-working-calendar-update
+// @Router /api/admin/working-calendar [put]
+// @Param body body calendarForm true "Working calendar"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) updateCalendar(c echo.Context) error {
+	form, err := newCalendarForm(c)
+	if err != nil {
+		return err
+	}
+
+	calendar, err := h.calendarRepo.Find()
+	if err != nil {
+		log.Errorf("Error finding working calendar: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	sort.Ints(form.WorkingDays)
+	calendar.WorkingDays = form.WorkingDays
+	calendar.HoursPerDay = form.HoursPerDay
+	calendar.UpdatedAt = time.Now()
+
+	if err := h.calendarRepo.Save(calendar); err != nil {
+		log.Errorf("Error saving working calendar: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, calendar)
+}
+
+// Working Days
+// @Tags Working Calendar
+// @Summary Count the working days between two dates
+// @Description Both days are included, leaves are left out when a user is given.
+// @ID working-days
+// @Router /api/working-days [get]
+// @Param start query string true "Start date"
+// @Param end query string true "End date"
+// @Param userId query string false "User whose leaves are left out"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) workingDays(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	if len(c.QueryParam("start")) == 0 || len(c.QueryParam("end")) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Start and end dates are required.")
+	}
+	if cq.End.Before(cq.Start) {
+		return echo.NewHTTPError(http.StatusBadRequest, "End date must not be before the start date.")
+	}
+	if cq.End.After(cq.Start.AddDate(maxWindowYears, 0, 0)) {
+		return echo.NewHTTPError(http.StatusBadRequest, "The window can be at most 10 years.")
+	}
+
+	calendar, err := h.calendarRepo.Load(cq.Start, cq.End, h.holidayRepo, h.leaveRepo)
+	if err != nil {
+		log.Errorf("Error loading working calendar: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	days := calendar.CountWorkingDays(cq.Start, cq.End, cq.UserId)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"start":        cq.Start,
+		"end":          cq.End,
+		"working_days": days,
+		"hours":        float64(days) * calendar.HoursPerDay,
+	})
+}
+
+// Holidays
+// @Tags Working Calendar
+// @Summary Get the holidays by date
+// @Description Without dates the holidays of the current year are returned.
+// @ID holidays
+// @Router /api/holidays [get]
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) holidays(c echo.Context) error {
+	holidays, err := h.holidayRepo.FindAll(newYearQuery(c))
+	if err != nil {
+		log.Errorf("Error finding holiday: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, holidays)
+}
+
+// Create Holiday
+// @Tags Admin
+// @Summary Add a holiday, replacing the name of a holiday already on that day
+// @ID admin-holiday-create
+// @Router /api/admin/holidays [post]
+// @Param body body holidayForm true "Holiday"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createHoliday(c echo.Context) error {
+	form, err := newHolidayForm(c)
+	if err != nil {
+		return err
+	}
+
+	holiday := &repository.Holiday{
+		ID:        bson.NewObjectID(),
+		Date:      util.DateOf(time.UnixMilli(form.Date)),
+		Name:      form.Name,
+		CreatedAt: time.Now(),
+	}
+	if _, err := h.holidayRepo.UpsertByDate(holiday); err != nil {
+		log.Errorf("Error saving holiday: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, holiday)
+}
+
+// Import Holidays
+// @Tags Admin
+// @Summary Import the holidays of an iCalendar file
+// @Description Every day of an all-day event becomes a holiday, timed events mark the day they start.
+// @Description Cancelled events are skipped and holidays already on a day are renamed.
+// @ID admin-holiday-import
+// @Router /api/admin/holidays/import [post]
+// @Param file formData file true "iCalendar (.ics) file"
+// @Accept multipart/form-data
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) importHolidays(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "An iCalendar file is required.")
+	}
+	if file.Size > maxImportSize {
+		return echo.NewHTTPError(http.StatusBadRequest, "Maximum file size is 1MB")
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Errorf("Error opening holiday file: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	defer src.Close()
+
+	calendar, err := ical.Parse(src)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid iCalendar file: "+err.Error())
+	}
+
+	created, updated, skipped := 0, 0, 0
+	for _, event := range calendar.Events {
+		name := strings.TrimSpace(event.Summary)
+		if event.Cancelled || name == "" {
+			skipped++
+			continue
+		}
+		if len(name) > maxNameLength {
+			name = name[:maxNameLength]
+		}
+
+		for _, day := range eventDays(event) {
+			isNew, err := h.holidayRepo.UpsertByDate(&repository.Holiday{
+				ID:        bson.NewObjectID(),
+				Date:      day,
+				Name:      name,
+				UID:       event.UID,
+				CreatedAt: time.Now(),
+			})
+			if err != nil {
+				log.Errorf("Error saving holiday: %v", err)
+				return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+			}
+			if isNew {
+				created++
+			} else {
+				updated++
+			}
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"created": created,
+		"updated": updated,
+		"skipped": skipped,
+	})
+}
+
+// Delete Holiday
+// @Tags Admin
+// @Summary Delete a holiday
+// @ID admin-holiday-delete
+// @Router /api/admin/holiday/{id} [delete]
+// @Param id path string true "Holiday ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deleteHoliday(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid holiday ID.")
+	}
+
+	if _, err := h.holidayRepo.FindOneByID(oId); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Holiday not found")
+		}
+		log.Errorf("Error finding holiday: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if err := h.holidayRepo.DeleteOneByID(oId); err != nil {
+		log.Errorf("Error deleting holiday: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, "Holiday deleted")
+}
+
+// My Leaves
+// @Tags Working Calendar
+// @Summary Get my leaves
+// @Description Without dates the leaves of the current year are returned.
+// @ID my-leaves
+// @Router /api/me/leaves [get]
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) myLeaves(c echo.Context) error {
+	cq := newYearQuery(c)
+	cq.UserId = c.(*context.Context).Claims.IDAsObjectID
+
+	leaves, err := h.leaveRepo.FindAll(cq)
+	if err != nil {
+		log.Errorf("Error finding leave: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, leaves)
+}
+
+// Leaves
+// @Tags Admin
+// @Summary Get the leaves of every user
+// @Description Without dates the leaves of the current year are returned.
+// @ID admin-leaves
+// @Router /api/admin/leaves [get]
+// @Param userId query string false "Search by user"
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) leaves(c echo.Context) error {
+	leaves, err := h.leaveRepo.FindAll(newYearQuery(c))
+	if err != nil {
+		log.Errorf("Error finding leave: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	users := map[bson.ObjectID]map[string]interface{}{}
+	docs := make([]map[string]interface{}, 0)
+	for _, leave := range leaves {
+		user, exists := users[leave.UserID]
+		if !exists {
+			user = map[string]interface{}{"_id": leave.UserID, "name": ""}
+			if u, err := h.userRepo.FindOneByID(leave.UserID); err == nil {
+				user["name"] = u.Name
+			}
+			users[leave.UserID] = user
+		}
+
+		docs = append(docs, map[string]interface{}{
+			"_id":        leave.ID,
+			"user":       user,
+			"start_date": leave.StartDate,
+			"end_date":   leave.EndDate,
+			"reason":     leave.Reason,
+			"created_at": leave.CreatedAt,
+		})
+	}
+	return c.JSON(http.StatusOK, docs)
+}
+
+// Create Leave
+// @Tags Working Calendar
+// @Summary Take days off, they are not working days of the logged-in user
+// @ID leave-create
+// @Router /api/me/leaves [post]
+// @Param body body leaveForm true "Leave"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) createLeave(c echo.Context) error {
+	form, err := newLeaveForm(c)
+	if err != nil {
+		return err
+	}
+
+	leave := &repository.Leave{
+		ID:        bson.NewObjectID(),
+		UserID:    c.(*context.Context).Claims.IDAsObjectID,
+		StartDate: util.DateOf(time.UnixMilli(form.StartDate)),
+		EndDate:   util.DateOf(time.UnixMilli(form.EndDate)),
+		Reason:    form.Reason,
+		CreatedAt: time.Now(),
+	}
+	if err := h.leaveRepo.CreateOne(leave); err != nil {
+		log.Errorf("Error creating leave: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, leave)
+}
+
+// Delete Leave
+// @Tags Working Calendar
+// @Summary Delete a leave, only its user and admins can delete it
+// @ID leave-delete
+// @Router /api/leave/{id} [delete]
+// @Param id path string true "Leave ID"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) deleteLeave(c echo.Context) error {
+	oId, err := bson.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid leave ID.")
+	}
+
+	leave, err := h.leaveRepo.FindOneByID(oId)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return echo.NewHTTPError(http.StatusNotFound, "Leave not found")
+		}
+		log.Errorf("Error finding leave: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	claims := c.(*context.Context).Claims
+	if leave.UserID != claims.IDAsObjectID && !claims.IsAdmin() {
+		return echo.NewHTTPError(http.StatusForbidden, "You can only delete your own leaves.")
+	}
+
+	if err := h.leaveRepo.DeleteOneByID(leave.ID); err != nil {
+		log.Errorf("Error deleting leave: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+	return c.JSON(http.StatusOK, "Leave deleted")
+}
+
+// newYearQuery reads the common query, the window defaults to the current year
+func newYearQuery(c echo.Context) *util.CommonQuery {
+	cq := util.NewCommonQuery(c)
+	if len(c.QueryParam("start")) == 0 {
+		cq.Start = util.StartOfYear(0)
+	}
+	if len(c.QueryParam("end")) == 0 {
+		cq.End = util.StartOfYear(1)
+	}
+	return cq
+}
+
+// eventDays returns the days of an imported event, every day of an all-day event up to its exclusive end
+// or the day a timed event starts
+func eventDays(event ical.Event) []time.Time {
+	start := util.DateOf(event.Start)
+	if !event.AllDay {
+		return []time.Time{start}
+	}
+
+	days := make([]time.Time, 0)
+	for day := start; day.Before(event.End) && len(days) < maxHolidayLength; day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		days = append(days, start)
+	}
+	return days
+}
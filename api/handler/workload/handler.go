@@ -0,0 +1,334 @@
+package workload
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWeeks = 4
+	maxDays      = 366
+)
+
+type Handler struct {
+	userRepo     *repository.UserCollRepository
+	taskRepo     *repository.TaskCollRepository
+	scheduleRepo *repository.ScheduleCollRepository
+	calendarRepo *repository.WorkingCalendarCollRepository
+	holidayRepo  *repository.HolidayCollRepository
+	leaveRepo    *repository.LeaveCollRepository
+}
+
+func NewHandler(e *echo.Echo, db *mongo.Database) *Handler {
+	h := &Handler{
+		userRepo:     repository.NewUserCollRepository(db),
+		taskRepo:     repository.NewTaskCollRepository(db),
+		scheduleRepo: repository.NewScheduleCollRepository(db),
+		calendarRepo: repository.NewWorkingCalendarCollRepository(db),
+		holidayRepo:  repository.NewHolidayCollRepository(db),
+		leaveRepo:    repository.NewLeaveCollRepository(db),
+	}
+
+	workload := e.Group("/api", context.ContextHandler, context.AdminOrMaintainerOnly)
+
+	workload.GET("/workload", h.workload)
+
+	return h
+}
+
+type week struct {
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"` // exclusive
+	EstimatedHours float64   `json:"estimated_hours"`
+	ScheduleHours  float64   `json:"schedule_hours"`
+	CapacityHours  float64   `json:"capacity_hours"`
+	Load           float64   `json:"load"` // estimated and schedule hours as a percentage of the capacity
+}
+
+type userLoad struct {
+	User           map[string]interface{} `json:"user"`
+	Active         int                    `json:"active"`
+	Testing        int                    `json:"testing"`
+	Overdue        int                    `json:"overdue"` // active and testing tasks past their end date
+	EstimatedHours float64                `json:"estimated_hours"`
+	ScheduleHours  float64                `json:"schedule_hours"`
+	CapacityHours  float64                `json:"capacity_hours"`
+	Load           float64                `json:"load"`
+	Overloaded     bool                   `json:"overloaded"` // more hours than capacity in at least one week
+	Weeks          []week                 `json:"weeks"`
+}
+
+// Workload
+// @Tags Workload
+// @Summary Get the task and schedule hours of every user against their capacity per week
+// @Description Estimated hours of active and testing tasks are shared by their contributors and spread over the working
+// @Description days of the task. Capacity is the working hours of the working days of the user, holidays and leaves left out.
+// @Description The window defaults to the current and next 3 weeks, users are sorted by load.
+// @ID workload
+// @Router /api/workload [get]
+// @Param start query string false "Start date"
+// @Param end query string false "End date"
+// @Param position query string false "Search by position"
+// @Param userId query string false "Search by user"
+// @Param projectId query string false "Search by project"
+// @Param topLevel query bool false "Leave subtasks out"
+// @Accept json
+// @Produce json
+// @Success 200
+// @Security ApiKeyAuth
+func (h *Handler) workload(c echo.Context) error {
+	cq := util.NewCommonQuery(c)
+	if len(c.QueryParam("start")) == 0 {
+		cq.Start = util.StartOfWeek(0)
+	}
+	if len(c.QueryParam("end")) == 0 {
+		cq.End = util.StartOfWeek(defaultWeeks)
+	}
+	if !cq.End.After(cq.Start) {
+		return echo.NewHTTPError(http.StatusBadRequest, "End date must be after the start date.")
+	}
+	if cq.End.Sub(cq.Start) > maxDays*24*time.Hour {
+		return echo.NewHTTPError(http.StatusBadRequest, "The window can be at most 366 days.")
+	}
+
+	position := strings.TrimSpace(c.QueryParam("position"))
+	if position != "" && !_const.IsValidPosition(position) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid position.")
+	}
+
+	users, err := h.userRepo.FindAllActive(position)
+	if err != nil {
+		log.Errorf("Error finding user: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	tasks, err := h.taskRepo.FindAllUnfinished(cq)
+	if err != nil {
+		log.Errorf("Error finding task: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	scheduleQuery := util.NilCommonQuery()
+	scheduleQuery.Start, scheduleQuery.End = cq.Start, cq.End
+	scheduleQuery.UserId = cq.UserId
+	scheduleQuery.ProjectId = cq.ProjectId
+	schedules, err := h.scheduleRepo.FindAll(scheduleQuery)
+	if err != nil {
+		log.Errorf("Error finding schedule: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	// Tasks are spread over their whole span, the calendar covers it as well as the window.
+	first, last := cq.Start, cq.End
+	for _, task := range tasks {
+		if task.StartDate.Before(first) {
+			first = task.StartDate
+		}
+		if task.EndDate.After(last) {
+			last = task.EndDate
+		}
+	}
+	calendar, err := h.calendarRepo.Load(first, last, h.holidayRepo, h.leaveRepo)
+	if err != nil {
+		log.Errorf("Error loading working calendar: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	weeks := splitWeeks(cq.Start, cq.End)
+	docs := make([]*userLoad, 0)
+	index := map[bson.ObjectID]*userLoad{}
+	for _, user := range users {
+		if !cq.UserId.IsZero() && user.ID != cq.UserId {
+			continue
+		}
+
+		doc := &userLoad{
+			User: map[string]interface{}{
+				"_id":      user.ID,
+				"name":     user.Name,
+				"position": user.Position,
+			},
+			Weeks: make([]week, len(weeks)),
+		}
+		copy(doc.Weeks, weeks)
+		for i := range doc.Weeks {
+			days := calendar.CountWorkingDays(doc.Weeks[i].Start, doc.Weeks[i].End.Add(-time.Nanosecond), user.ID)
+			doc.Weeks[i].CapacityHours = float64(days) * calendar.HoursPerDay
+		}
+		index[user.ID] = doc
+		docs = append(docs, doc)
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		if len(task.Contributor) == 0 {
+			continue
+		}
+		inWindow := task.EndDate.IsZero() || !task.EndDate.Before(cq.Start)
+		days, total := taskDays(calendar, &task, cq.Start, cq.End)
+		share := task.EstimatedHours / float64(len(task.Contributor)) / float64(total)
+
+		for _, userID := range task.Contributor {
+			doc, ok := index[userID]
+			if !ok {
+				continue
+			}
+
+			if !task.EndDate.IsZero() && task.EndDate.Before(now) {
+				doc.Overdue++
+			}
+			if inWindow && task.Status == _const.TaskActive {
+				doc.Active++
+			}
+			if inWindow && task.Status == _const.TaskTesting {
+				doc.Testing++
+			}
+
+			for _, day := range days {
+				if i := weekOf(weeks, day); i >= 0 {
+					doc.Weeks[i].EstimatedHours += share
+				}
+			}
+		}
+	}
+
+	for i := range schedules {
+		for _, occurrence := range schedules[i].Occurrences(cq.Start, cq.End) {
+			hours := dailyHours(occurrence.StartTime, occurrence.EndTime)
+			if hours == 0 {
+				continue
+			}
+
+			for day := util.DateOf(occurrence.StartDate); !day.After(occurrence.EndDate); day = day.AddDate(0, 0, 1) {
+				w := weekOf(weeks, day)
+				if w < 0 {
+					continue
+				}
+				for _, userID := range occurrence.Contributor {
+					if doc, ok := index[userID]; ok && calendar.IsWorkingDay(day, userID) {
+						doc.Weeks[w].ScheduleHours += hours
+					}
+				}
+			}
+		}
+	}
+
+	for _, doc := range docs {
+		for i := range doc.Weeks {
+			w := &doc.Weeks[i]
+			doc.EstimatedHours += w.EstimatedHours
+			doc.ScheduleHours += w.ScheduleHours
+			doc.CapacityHours += w.CapacityHours
+			if w.EstimatedHours+w.ScheduleHours > w.CapacityHours {
+				doc.Overloaded = true
+			}
+
+			w.Load = load(w.EstimatedHours+w.ScheduleHours, w.CapacityHours)
+			w.EstimatedHours = util.Round(w.EstimatedHours)
+			w.ScheduleHours = util.Round(w.ScheduleHours)
+			w.CapacityHours = util.Round(w.CapacityHours)
+		}
+
+		doc.Load = load(doc.EstimatedHours+doc.ScheduleHours, doc.CapacityHours)
+		doc.EstimatedHours = util.Round(doc.EstimatedHours)
+		doc.ScheduleHours = util.Round(doc.ScheduleHours)
+		doc.CapacityHours = util.Round(doc.CapacityHours)
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		if docs[i].Overloaded != docs[j].Overloaded {
+			return docs[i].Overloaded
+		}
+		return docs[i].Load > docs[j].Load
+	})
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"start":         cq.Start,
+		"end":           cq.End,
+		"hours_per_day": calendar.HoursPerDay,
+		"weeks":         weeks,
+		"users":         docs,
+	})
+}
+
+// splitWeeks cuts the window into weeks starting on Monday, the first and last weeks may be shorter
+func splitWeeks(start, end time.Time) []week {
+	weeks := make([]week, 0)
+	for from := start; from.Before(end); {
+		day := util.DateOf(from)
+		to := day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+		if to.After(end) {
+			to = end
+		}
+		weeks = append(weeks, week{Start: from, End: to})
+		from = to
+	}
+	return weeks
+}
+
+// weekOf returns the index of the week containing the day, -1 outside the window
+func weekOf(weeks []week, day time.Time) int {
+	for i := range weeks {
+		if !day.Before(util.DateOf(weeks[i].Start)) && day.Before(weeks[i].End) {
+			return i
+		}
+	}
+	return -1
+}
+
+// taskDays returns the organization working days of the task within the window and the number of
+// working days over its whole span, the start day alone counts when there are none. Only the days in
+// the window are walked, so a task spanning years costs no more than the window.
+func taskDays(calendar *repository.WorkingCalendar, task *repository.Task, start, end time.Time) ([]time.Time, int) {
+	first := util.DateOf(task.StartDate)
+	days := make([]time.Time, 0)
+
+	total := calendar.CountWorkingDays(task.StartDate, task.EndDate, bson.NilObjectID)
+	if total == 0 {
+		if !first.Before(util.DateOf(start)) && first.Before(end) {
+			days = append(days, first)
+		}
+		return days, 1
+	}
+
+	if first.Before(util.DateOf(start)) {
+		first = util.DateOf(start)
+	}
+	for day := first; !day.After(task.EndDate) && day.Before(end); day = day.AddDate(0, 0, 1) {
+		if calendar.IsWorkingDay(day, bson.NilObjectID) {
+			days = append(days, day)
+		}
+	}
+	return days, total
+}
+
+// dailyHours returns the hours between two 24-hour HH:MM times, zero when they are missing or reversed
+func dailyHours(startTime, endTime string) float64 {
+	start, err := time.Parse("15:04", startTime)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse("15:04", endTime)
+	if err != nil || !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// load returns the hours as a percentage of the capacity, zero without capacity
+func load(hours, capacity float64) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return util.Round(hours / capacity * 100)
+}
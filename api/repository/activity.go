@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+type Activity struct {
+	ID         bson.ObjectID    `json:"_id" bson:"_id"`
+	ActorID    bson.ObjectID    `json:"actor_id" bson:"actor_id"`
+	EntityType string           `json:"entity_type" bson:"entity_type"`
+	EntityID   bson.ObjectID    `json:"entity_id" bson:"entity_id"`
+	ProjectID  bson.ObjectID    `json:"project_id" bson:"project_id"` // project the entity belongs to, nil when none
+	Action     string           `json:"action" bson:"action"`
+	Changes    []ActivityChange `json:"changes" bson:"changes"`
+	CreatedAt  time.Time        `json:"created_at" bson:"created_at"`
+}
+
+type ActivityChange struct {
+	Field string      `json:"field" bson:"field"`
+	Old   interface{} `json:"old" bson:"old"`
+	New   interface{} `json:"new" bson:"new"`
+}
+
+type ActivityCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewActivityCollRepository(db *mongo.Database) *ActivityCollRepository {
+	// Changed values are free-form, nested documents decode as maps so they render as JSON objects.
+	collOptions := options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+	return &ActivityCollRepository{
+		coll: db.Collection("activities", collOptions),
+	}
+}
+
+func (r *ActivityCollRepository) filter(cq *util.CommonQuery, entityID bson.ObjectID) bson.M {
+	filter := bson.M{
+		"created_at": bson.M{"$gte": cq.Start, "$lt": cq.End},
+	}
+
+	if !entityID.IsZero() {
+		filter["entity_id"] = entityID
+	}
+
+	if len(cq.Type) > 0 && _const.IsValidEntityType(cq.Type) {
+		filter["entity_type"] = cq.Type
+	}
+
+	if cq.UserId != bson.NilObjectID {
+		filter["actor_id"] = cq.UserId
+	}
+
+	if cq.ProjectId != bson.NilObjectID {
+		filter["project_id"] = cq.ProjectId
+	}
+	return filter
+}
+
+// FindAll returns activities newest first, entityID limits the result to a single entity when not nil
+func (r *ActivityCollRepository) FindAll(cq *util.CommonQuery, entityID bson.ObjectID) ([]Activity, error) {
+	activities := []Activity{}
+
+	skip := (cq.Page - 1) * cq.Limit
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(cq.Limit)
+
+	cursor, err := r.coll.Find(context.TODO(), r.filter(cq, entityID), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &activities); err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
+
+func (r *ActivityCollRepository) Count(cq *util.CommonQuery, entityID bson.ObjectID) (int64, error) {
+	return r.coll.CountDocuments(context.TODO(), r.filter(cq, entityID))
+}
+
+func (r *ActivityCollRepository) CreateOne(activity *Activity) error {
+	_, err := r.coll.InsertOne(context.TODO(), activity)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -96,3 +96,12 @@ func (r *CodeCollRepository) UpdateOne(code *Code) (*Code, error) {
 	}
 	return &doc, nil
 }
+
+// DeleteExpiredBefore removes the codes that expired before the given time, used or not
+func (r *CodeCollRepository) DeleteExpiredBefore(before time.Time) (int64, error) {
+	result, err := r.coll.DeleteMany(context.TODO(), bson.M{"expired_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/config"
+	"strings"
+	"time"
+)
+
+type Comment struct {
+	ID          bson.ObjectID     `json:"_id" bson:"_id"`
+	TaskID      bson.ObjectID     `json:"task_id" bson:"task_id"`
+	ParentID    bson.ObjectID     `json:"parent_id" bson:"parent_id"` // nil for top-level comments
+	AuthorID    bson.ObjectID     `json:"author_id" bson:"author_id"`
+	Body        string            `json:"body" bson:"body"` // markdown
+	Attachments []string          `json:"attachments" bson:"attachments"`
+	History     []CommentRevision `json:"history" bson:"history"` // previous bodies, oldest first
+	CreatedAt   time.Time         `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" bson:"updated_at"`
+	IsDeleted   bool              `json:"is_deleted" bson:"is_deleted"`
+}
+
+type CommentRevision struct {
+	Body     string    `json:"body" bson:"body"`
+	EditedAt time.Time `json:"edited_at" bson:"edited_at"`
+}
+
+func (u *Comment) MarshalJSON() ([]byte, error) {
+	type Alias Comment
+	attachments := []string{}
+	if u.Attachments != nil {
+		for _, attachment := range u.Attachments {
+			urls := "https://" + config.S3.Bucket
+			if !strings.Contains(config.S3.EndPoint, "https://") {
+				urls = urls + "." + config.S3.EndPoint + "/" + attachment
+			} else {
+				urls = urls + "." + config.S3.EndPoint[8:] + "/" + attachment
+			}
+			attachments = append(attachments, urls)
+		}
+	}
+	return json.Marshal(&struct {
+		*Alias
+		Attachments []string `json:"attachments" bson:"attachments"`
+	}{
+		Alias:       (*Alias)(u),
+		Attachments: attachments,
+	})
+}
+
+type CommentCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewCommentCollRepository(db *mongo.Database) *CommentCollRepository {
+	return &CommentCollRepository{
+		coll: db.Collection("comments"),
+	}
+}
+
+// FindAllByTaskID returns every comment of the task including deleted ones, so threads can keep their shape
+func (r *CommentCollRepository) FindAllByTaskID(taskID bson.ObjectID) ([]Comment, error) {
+	comments := []Comment{}
+	filter := bson.M{"task_id": taskID}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *CommentCollRepository) FindOneByID(_id bson.ObjectID) (*Comment, error) {
+	comment := Comment{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&comment)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *CommentCollRepository) CreateOne(comment *Comment) error {
+	_, err := r.coll.InsertOne(context.TODO(), comment)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CommentCollRepository) UpdateOneByID(comment *Comment) error {
+	filter := bson.M{
+		"_id":        comment.ID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": comment}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CommentCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CountByTaskIDs returns the number of comments per task, tasks without comments are omitted
+func (r *CommentCollRepository) CountByTaskIDs(taskIDs []bson.ObjectID) (map[bson.ObjectID]int, error) {
+	counts := map[bson.ObjectID]int{}
+	if len(taskIDs) == 0 {
+		return counts, nil
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"task_id":    bson.M{"$in": taskIDs},
+				"is_deleted": bson.M{"$ne": true},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":   "$task_id",
+				"total": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err := r.coll.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(context.TODO()) {
+		result := struct {
+			ID    bson.ObjectID `bson:"_id"`
+			Total int           `bson:"total"`
+		}{}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		counts[result.ID] = result.Total
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// FillTaskCommentCount sets CommentCount on every task with a single query
+func (r *CommentCollRepository) FillTaskCommentCount(tasks []Task) error {
+	taskIDs := make([]bson.ObjectID, 0)
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	counts, err := r.CountByTaskIDs(taskIDs)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].CommentCount = counts[tasks[i].ID]
+	}
+	return nil
+}
+
+// FillTaskGroupCommentCount sets CommentCount on every task of the group with a single query
+func (r *CommentCollRepository) FillTaskGroupCommentCount(group *TaskGroup) error {
+	taskIDs := make([]bson.ObjectID, 0)
+	for _, tasks := range [][]Task{group.Active, group.Testing, group.Completed, group.Cancelled} {
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+
+	counts, err := r.CountByTaskIDs(taskIDs)
+	if err != nil {
+		return err
+	}
+	for _, tasks := range [][]Task{group.Active, group.Testing, group.Completed, group.Cancelled} {
+		for i := range tasks {
+			tasks[i].CommentCount = counts[tasks[i].ID]
+		}
+	}
+	return nil
+}
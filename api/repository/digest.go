@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"time"
+)
+
+// Digest records that the digest of a period was mailed to a user, so it is sent once
+// even when several instances run the digest job
+type Digest struct {
+	ID          bson.ObjectID `json:"_id" bson:"_id"`
+	UserID      bson.ObjectID `json:"user_id" bson:"user_id"`
+	Type        string        `json:"type" bson:"type"` // daily, weekly
+	PeriodStart time.Time     `json:"period_start" bson:"period_start"`
+	SentAt      time.Time     `json:"sent_at" bson:"sent_at"`
+}
+
+type DigestCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewDigestCollRepository(db *mongo.Database) *DigestCollRepository {
+	return &DigestCollRepository{
+		coll: db.Collection("digests"),
+	}
+}
+
+// CreateIndexes keeps a single digest per user, type and period
+func (r *DigestCollRepository) CreateIndexes() error {
+	_, err := r.coll.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "type", Value: 1}, {Key: "period_start", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Claim records the digest unless one of the same user, type and period exists. It reports whether it was recorded.
+func (r *DigestCollRepository) Claim(digest *Digest) (bool, error) {
+	filter := bson.M{
+		"user_id":      digest.UserID,
+		"type":         digest.Type,
+		"period_start": digest.PeriodStart,
+	}
+	update := bson.M{"$setOnInsert": digest}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// another instance claimed it first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// Release removes a claimed digest that could not be sent, the next run tries again
+func (r *DigestCollRepository) Release(_id bson.ObjectID) error {
+	_, err := r.coll.DeleteOne(context.TODO(), bson.M{"_id": _id})
+	return err
+}
+
+// FindLast returns the last digest of the type sent to the user for a period starting before the given time
+func (r *DigestCollRepository) FindLast(userID bson.ObjectID, digestType string, before time.Time) (*Digest, error) {
+	digest := Digest{}
+	filter := bson.M{
+		"user_id":      userID,
+		"type":         digestType,
+		"period_start": bson.M{"$lt": before},
+	}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "period_start", Value: -1}})
+
+	err := r.coll.FindOne(context.TODO(), filter, findOptions).Decode(&digest)
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+// DeleteBefore removes the digests of periods starting before the given time
+func (r *DigestCollRepository) DeleteBefore(before time.Time) (int64, error) {
+	result, err := r.coll.DeleteMany(context.TODO(), bson.M{"period_start": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
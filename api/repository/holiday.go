@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+type Holiday struct {
+	ID        bson.ObjectID `json:"_id" bson:"_id"`
+	Date      time.Time     `json:"date" bson:"date"` // midnight of the day in the server timezone
+	Name      string        `json:"name" bson:"name"`
+	UID       string        `json:"uid" bson:"uid"` // UID of the imported iCalendar event, empty when added by hand
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+	IsDeleted bool          `json:"-" bson:"is_deleted"`
+}
+
+type HolidayCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewHolidayCollRepository(db *mongo.Database) *HolidayCollRepository {
+	return &HolidayCollRepository{
+		coll: db.Collection("holidays"),
+	}
+}
+
+// FindAll returns the holidays within the query window by date
+func (r *HolidayCollRepository) FindAll(cq *util.CommonQuery) ([]Holiday, error) {
+	holidays := []Holiday{}
+	filter := bson.M{
+		"date":       bson.M{"$gte": cq.Start, "$lt": cq.End},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &holidays); err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (r *HolidayCollRepository) FindOneByID(_id bson.ObjectID) (*Holiday, error) {
+	holiday := Holiday{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&holiday)
+	if err != nil {
+		return nil, err
+	}
+	return &holiday, nil
+}
+
+// UpsertByDate saves the holiday on its day, replacing the name of a holiday already on that day.
+// It reports whether a new holiday was created.
+func (r *HolidayCollRepository) UpsertByDate(holiday *Holiday) (bool, error) {
+	filter := bson.M{
+		"date":       holiday.Date,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"name": holiday.Name,
+			"uid":  holiday.UID,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        holiday.ID,
+			"created_at": holiday.CreatedAt,
+			"is_deleted": false,
+		},
+	}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+func (r *HolidayCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
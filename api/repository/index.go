@@ -0,0 +1,20 @@
+package repository
+
+import "go.mongodb.org/mongo-driver/v2/mongo"
+
+// CreateIndexes creates the indexes the repositories rely on for uniqueness, creating an existing index is a no-op
+func CreateIndexes(db *mongo.Database) error {
+	creators := []func() error{
+		NewTimeEntryCollRepository(db).CreateIndexes,
+		NewSprintCollRepository(db).CreateIndexes,
+		NewNotificationCollRepository(db).CreateIndexes,
+		NewDigestCollRepository(db).CreateIndexes,
+		NewJobCollRepository(db).CreateIndexes,
+	}
+	for _, create := range creators {
+		if err := create(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+type Job struct {
+	ID          bson.ObjectID `json:"_id" bson:"_id"`
+	Type        string        `json:"type" bson:"type"`
+	Payload     bson.Raw      `json:"-" bson:"payload,omitempty"`         // may hold secrets like a verification code, never listed
+	Key         string        `json:"key,omitempty" bson:"key,omitempty"` // set on jobs enqueued at most once, like periodic runs
+	Status      string        `json:"status" bson:"status"`               // pending, running, done, failed
+	Attempts    int           `json:"attempts" bson:"attempts"`
+	MaxAttempts int           `json:"max_attempts" bson:"max_attempts"`
+	RunAt       time.Time     `json:"run_at" bson:"run_at"`
+	LockedBy    string        `json:"locked_by" bson:"locked_by"`
+	LockedUntil time.Time     `json:"locked_until" bson:"locked_until"`
+	LastError   string        `json:"last_error" bson:"last_error"`
+	CreatedAt   time.Time     `json:"created_at" bson:"created_at"`
+	StartedAt   time.Time     `json:"started_at" bson:"started_at"`
+	FinishedAt  time.Time     `json:"finished_at" bson:"finished_at"`
+}
+
+type JobCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewJobCollRepository(db *mongo.Database) *JobCollRepository {
+	return &JobCollRepository{
+		coll: db.Collection("jobs"),
+	}
+}
+
+func (r *JobCollRepository) filter(cq *util.CommonQuery) bson.M {
+	filter := bson.M{}
+
+	if _const.IsValidJobStatus(cq.Status) {
+		filter["status"] = cq.Status
+	}
+	if len(cq.Type) > 0 {
+		filter["type"] = cq.Type
+	}
+	return filter
+}
+
+// FindAll returns the jobs newest first
+func (r *JobCollRepository) FindAll(cq *util.CommonQuery) ([]Job, error) {
+	jobs := []Job{}
+
+	skip := (cq.Page - 1) * cq.Limit
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(cq.Limit)
+
+	cursor, err := r.coll.Find(context.TODO(), r.filter(cq), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *JobCollRepository) Count(cq *util.CommonQuery) (int64, error) {
+	return r.coll.CountDocuments(context.TODO(), r.filter(cq))
+}
+
+// CountByStatus returns how many jobs there are of every status
+func (r *JobCollRepository) CountByStatus() (map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.coll.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []struct {
+		Status string `bson:"_id"`
+		Count  int64  `bson:"count"`
+	}{}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{
+		_const.JobPending: 0,
+		_const.JobRunning: 0,
+		_const.JobDone:    0,
+		_const.JobFailed:  0,
+	}
+	for _, result := range results {
+		counts[result.Status] = result.Count
+	}
+	return counts, nil
+}
+
+func (r *JobCollRepository) FindOneByID(_id bson.ObjectID) (*Job, error) {
+	job := Job{}
+	err := r.coll.FindOne(context.TODO(), bson.M{"_id": _id}).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobCollRepository) CreateOne(job *Job) error {
+	_, err := r.coll.InsertOne(context.TODO(), job)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateIndexes keeps keyed jobs unique and serves the workers looking for due jobs
+func (r *JobCollRepository) CreateIndexes() error {
+	_, err := r.coll.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"key": bson.M{"$exists": true},
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "run_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateOnce stores the job unless one with the same key exists. It reports whether it was stored.
+func (r *JobCollRepository) CreateOnce(job *Job) (bool, error) {
+	filter := bson.M{"key": job.Key}
+	update := bson.M{"$setOnInsert": job}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// another instance enqueued it first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// Claim reserves the next due job for the worker until the lease ends. Running jobs whose lease
+// ended, because their instance stopped, are claimed again. It returns mongo.ErrNoDocuments when no job is due.
+func (r *JobCollRepository) Claim(worker string, now time.Time, lease time.Duration) (*Job, error) {
+	job := Job{}
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": _const.JobPending, "run_at": bson.M{"$lte": now}},
+			bson.M{"status": _const.JobRunning, "locked_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       _const.JobRunning,
+			"locked_by":    worker,
+			"locked_until": now.Add(lease),
+			"started_at":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	findOptions := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	err := r.coll.FindOneAndUpdate(context.TODO(), filter, update, findOptions).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Complete marks a job of the worker as done and drops its payload
+func (r *JobCollRepository) Complete(_id bson.ObjectID, worker string) error {
+	filter := bson.M{"_id": _id, "locked_by": worker}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       _const.JobDone,
+			"locked_until": time.Time{},
+			"last_error":   "",
+			"finished_at":  time.Now(),
+		},
+		"$unset": bson.M{"payload": ""},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	return err
+}
+
+// Retry puts a failed job of the worker back in the queue to run again at runAt
+func (r *JobCollRepository) Retry(_id bson.ObjectID, worker string, runAt time.Time, lastError string) error {
+	filter := bson.M{"_id": _id, "locked_by": worker}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       _const.JobPending,
+			"run_at":       runAt,
+			"locked_until": time.Time{},
+			"last_error":   lastError,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	return err
+}
+
+// Fail marks a job of the worker as failed for good, its payload is kept so it can be requeued
+func (r *JobCollRepository) Fail(_id bson.ObjectID, worker string, lastError string) error {
+	filter := bson.M{"_id": _id, "locked_by": worker}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       _const.JobFailed,
+			"locked_until": time.Time{},
+			"last_error":   lastError,
+			"finished_at":  time.Now(),
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	return err
+}
+
+// Requeue runs a failed job again from its first attempt. It reports whether a failed job was found.
+func (r *JobCollRepository) Requeue(_id bson.ObjectID) (bool, error) {
+	filter := bson.M{"_id": _id, "status": _const.JobFailed}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      _const.JobPending,
+			"attempts":    0,
+			"run_at":      time.Now(),
+			"finished_at": time.Time{},
+		},
+	}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// DeleteFinishedBefore removes the done and failed jobs that finished before the given time
+func (r *JobCollRepository) DeleteFinishedBefore(before time.Time) (int64, error) {
+	filter := bson.M{
+		"status":      bson.M{"$in": bson.A{_const.JobDone, _const.JobFailed}},
+		"finished_at": bson.M{"$lt": before},
+	}
+
+	result, err := r.coll.DeleteMany(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
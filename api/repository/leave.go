@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+type Leave struct {
+	ID        bson.ObjectID `json:"_id" bson:"_id"`
+	UserID    bson.ObjectID `json:"user_id" bson:"user_id"`
+	StartDate time.Time     `json:"start_date" bson:"start_date"` // first day off, midnight in the server timezone
+	EndDate   time.Time     `json:"end_date" bson:"end_date"`     // last day off, midnight in the server timezone
+	Reason    string        `json:"reason" bson:"reason"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+	IsDeleted bool          `json:"-" bson:"is_deleted"`
+}
+
+type LeaveCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewLeaveCollRepository(db *mongo.Database) *LeaveCollRepository {
+	return &LeaveCollRepository{
+		coll: db.Collection("leaves"),
+	}
+}
+
+// FindAll returns the leaves overlapping the query window by start date, of one user when the query has one
+func (r *LeaveCollRepository) FindAll(cq *util.CommonQuery) ([]Leave, error) {
+	leaves := []Leave{}
+	filter := bson.M{
+		"start_date": bson.M{"$lt": cq.End},
+		"end_date":   bson.M{"$gte": cq.Start},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	if cq.UserId != bson.NilObjectID {
+		filter["user_id"] = cq.UserId
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &leaves); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+func (r *LeaveCollRepository) FindOneByID(_id bson.ObjectID) (*Leave, error) {
+	leave := Leave{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&leave)
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+func (r *LeaveCollRepository) CreateOne(leave *Leave) error {
+	_, err := r.coll.InsertOne(context.TODO(), leave)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *LeaveCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"math"
+	"proman-backend/internal/pkg/const"
+	"time"
+)
+
+type Milestone struct {
+	ID          bson.ObjectID   `json:"_id" bson:"_id"`
+	ProjectID   bson.ObjectID   `json:"project_id" bson:"project_id"`
+	Name        string          `json:"name" bson:"name"`
+	Description string          `json:"description" bson:"description"`
+	DueDate     time.Time       `json:"due_date" bson:"due_date"`
+	TaskIDs     []bson.ObjectID `json:"task_ids" bson:"task_ids"`
+	CreatedAt   time.Time       `json:"created_at" bson:"created_at"`
+	IsDeleted   bool            `json:"-" bson:"is_deleted"`
+
+	// filled by CalculateCompletion
+	TaskCount      int     `json:"task_count" bson:"-"`
+	CompletedCount int     `json:"completed_count" bson:"-"`
+	Completion     float64 `json:"completion" bson:"-"` // ratio of completed linked tasks, from 0 to 1
+}
+
+// CalculateCompletion sets the completion of the milestone from the statuses of its linked tasks.
+// Cancelled and deleted tasks are left out, a milestone without tasks is not complete.
+func (u *Milestone) CalculateCompletion(index map[bson.ObjectID]*Task) {
+	u.TaskCount, u.CompletedCount = 0, 0
+	for _, taskID := range u.TaskIDs {
+		task, ok := index[taskID]
+		if !ok || task.Status == _const.TaskCancelled {
+			continue
+		}
+		u.TaskCount++
+		if task.Status == _const.TaskCompleted {
+			u.CompletedCount++
+		}
+	}
+
+	u.Completion = 0
+	if u.TaskCount > 0 {
+		u.Completion = math.Round(float64(u.CompletedCount)/float64(u.TaskCount)*100) / 100
+	}
+}
+
+type MilestoneCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMilestoneCollRepository(db *mongo.Database) *MilestoneCollRepository {
+	return &MilestoneCollRepository{
+		coll: db.Collection("milestones"),
+	}
+}
+
+// FindAllByProjectID returns the milestones of the project by due date
+func (r *MilestoneCollRepository) FindAllByProjectID(projectID bson.ObjectID) ([]Milestone, error) {
+	milestones := []Milestone{}
+	filter := bson.M{
+		"project_id": projectID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+// FindAllDueBefore returns the milestones due before the date by due date, projectIds limits the projects when not nil
+func (r *MilestoneCollRepository) FindAllDueBefore(due time.Time, projectIds []bson.ObjectID) ([]Milestone, error) {
+	milestones := []Milestone{}
+	filter := bson.M{
+		"due_date":   bson.M{"$lt": due},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	if projectIds != nil {
+		filter["project_id"] = bson.M{"$in": projectIds}
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &milestones); err != nil {
+		return nil, err
+	}
+	return milestones, nil
+}
+
+func (r *MilestoneCollRepository) FindOneByID(_id bson.ObjectID) (*Milestone, error) {
+	milestone := Milestone{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&milestone)
+	if err != nil {
+		return nil, err
+	}
+	return &milestone, nil
+}
+
+func (r *MilestoneCollRepository) CreateOne(milestone *Milestone) error {
+	_, err := r.coll.InsertOne(context.TODO(), milestone)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *MilestoneCollRepository) UpdateOneByID(milestone *Milestone) error {
+	filter := bson.M{
+		"_id":        milestone.ID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": milestone}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *MilestoneCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *MilestoneCollRepository) DeleteAllByProjectID(projectID bson.ObjectID) error {
+	filter := bson.M{
+		"project_id": projectID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// FillCompletion calculates the completion of every milestone with a single task query
+func (r *MilestoneCollRepository) FillCompletion(milestones []Milestone, taskRepo *TaskCollRepository) error {
+	taskIDs := make([]bson.ObjectID, 0)
+	for _, milestone := range milestones {
+		taskIDs = append(taskIDs, milestone.TaskIDs...)
+	}
+
+	tasks, err := taskRepo.FindAllByIDs(taskIDs)
+	if err != nil {
+		return err
+	}
+
+	index := IndexTasks(tasks)
+	for i := range milestones {
+		milestones[i].CalculateCompletion(index)
+	}
+	return nil
+}
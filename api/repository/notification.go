@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+const (
+	NotificationRead   = "read"
+	NotificationUnread = "unread"
+)
+
+type Notification struct {
+	ID         bson.ObjectID `json:"_id" bson:"_id"`
+	UserID     bson.ObjectID `json:"user_id" bson:"user_id"`
+	ActorID    bson.ObjectID `json:"actor_id" bson:"actor_id"` // nil for notifications of the system, like due dates
+	Type       string        `json:"type" bson:"type"`
+	EntityType string        `json:"entity_type" bson:"entity_type"`
+	EntityID   bson.ObjectID `json:"entity_id" bson:"entity_id"`
+	ProjectID  bson.ObjectID `json:"project_id" bson:"project_id"`
+	Title      string        `json:"title" bson:"title"`
+	Message    string        `json:"message" bson:"message"`
+	Key        string        `json:"-" bson:"key,omitempty"` // set on notifications sent at most once
+	IsRead     bool          `json:"is_read" bson:"is_read"`
+	ReadAt     time.Time     `json:"read_at" bson:"read_at"`
+	CreatedAt  time.Time     `json:"created_at" bson:"created_at"`
+}
+
+type NotificationCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewNotificationCollRepository(db *mongo.Database) *NotificationCollRepository {
+	return &NotificationCollRepository{
+		coll: db.Collection("notifications"),
+	}
+}
+
+// filter matches the notifications of the user, only the read or unread ones when the query status is set
+func (r *NotificationCollRepository) filter(cq *util.CommonQuery, userID bson.ObjectID) bson.M {
+	filter := bson.M{"user_id": userID}
+
+	switch cq.Status {
+	case NotificationRead:
+		filter["is_read"] = true
+	case NotificationUnread:
+		filter["is_read"] = bson.M{"$ne": true}
+	}
+
+	if len(cq.Type) > 0 {
+		filter["type"] = cq.Type
+	}
+	return filter
+}
+
+// FindAllByUserID returns the notifications of the user, newest first
+func (r *NotificationCollRepository) FindAllByUserID(cq *util.CommonQuery, userID bson.ObjectID) ([]Notification, error) {
+	notifications := []Notification{}
+
+	skip := (cq.Page - 1) * cq.Limit
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(cq.Limit)
+
+	cursor, err := r.coll.Find(context.TODO(), r.filter(cq, userID), findOptions)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// FindAllSince returns the notifications of the given types the user received from since, oldest first
+func (r *NotificationCollRepository) FindAllSince(userID bson.ObjectID, types []string, since time.Time) ([]Notification, error) {
+	notifications := []Notification{}
+	filter := bson.M{
+		"user_id":    userID,
+		"type":       bson.M{"$in": types},
+		"created_at": bson.M{"$gte": since},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *NotificationCollRepository) CountByUserID(cq *util.CommonQuery, userID bson.ObjectID) (int64, error) {
+	return r.coll.CountDocuments(context.TODO(), r.filter(cq, userID))
+}
+
+func (r *NotificationCollRepository) CountUnread(userID bson.ObjectID) (int64, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"is_read": bson.M{"$ne": true},
+	}
+	return r.coll.CountDocuments(context.TODO(), filter)
+}
+
+// CreateIndexes keeps keyed notifications unique and serves the unread lists of a user, newest first
+func (r *NotificationCollRepository) CreateIndexes() error {
+	_, err := r.coll.Indexes().CreateMany(context.TODO(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "is_read", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *NotificationCollRepository) CreateOne(notification *Notification) error {
+	_, err := r.coll.InsertOne(context.TODO(), notification)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreateOnce stores the notification unless one with the same key exists. It reports whether it was stored.
+func (r *NotificationCollRepository) CreateOnce(notification *Notification) (bool, error) {
+	filter := bson.M{"key": notification.Key}
+	update := bson.M{"$setOnInsert": notification}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent upsert stored it first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// MarkRead marks a notification of the user as read. It reports whether the notification was found.
+func (r *NotificationCollRepository) MarkRead(_id, userID bson.ObjectID) (bool, error) {
+	filter := bson.M{
+		"_id":     _id,
+		"user_id": userID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_read": true,
+			"read_at": time.Now(),
+		},
+	}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many there were
+func (r *NotificationCollRepository) MarkAllRead(userID bson.ObjectID) (int64, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"is_read": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_read": true,
+			"read_at": time.Now(),
+		},
+	}
+
+	result, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// DeleteReadBefore removes the read notifications created before the given time
+func (r *NotificationCollRepository) DeleteReadBefore(before time.Time) (int64, error) {
+	filter := bson.M{
+		"is_read":    true,
+		"created_at": bson.M{"$lt": before},
+	}
+
+	result, err := r.coll.DeleteMany(context.TODO(), filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
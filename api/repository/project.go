@@ -5,27 +5,171 @@ import (
 	"encoding/json"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"proman-backend/config"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/util"
+	"slices"
 	"strings"
 	"time"
 )
 
 type Project struct {
-	ID          bson.ObjectID   `json:"_id" bson:"_id"`
-	Name        string          `json:"name" bson:"name"`
-	Description string          `json:"description" bson:"description"`
-	Type        string          `json:"type" bson:"type"`
-	StartDate   time.Time       `json:"start_date" bson:"start_date"`
-	EndDate     time.Time       `json:"end_date" bson:"end_date"`
-	Contributor []bson.ObjectID `json:"contributor" bson:"contributor"`
-	Attachments []string        `json:"attachments" bson:"attachments"`
-	Status      string          `json:"status" bson:"status"` // active, completed, pending, cancelled
-	Logo        string          `json:"logo" bson:"logo"`
-	CreatedAt   time.Time       `json:"created_at" bson:"created_at"`
-	IsDeleted   bool            `json:"-" bson:"is_deleted"`
-	TaskCount   CountTaskDetail `json:"task_count" bson:"task_count"`
+	ID          bson.ObjectID        `json:"_id" bson:"_id"`
+	Name        string               `json:"name" bson:"name"`
+	Description string               `json:"description" bson:"description"`
+	Type        string               `json:"type" bson:"type"`
+	StartDate   time.Time            `json:"start_date" bson:"start_date"`
+	EndDate     time.Time            `json:"end_date" bson:"end_date"`
+	Contributor []bson.ObjectID      `json:"contributor" bson:"contributor"`
+	Members     []ProjectMember      `json:"members" bson:"members"`   // roles of contributors, see MemberRole for contributors without an entry
+	Workflow    []WorkflowTransition `json:"workflow" bson:"workflow"` // allowed task status transitions, empty uses DefaultWorkflow
+	Columns     []BoardColumn        `json:"columns" bson:"columns"`   // ordered board columns, empty uses DefaultColumns
+	Attachments []string             `json:"attachments" bson:"attachments"`
+	Status      string               `json:"status" bson:"status"` // active, completed, pending, cancelled
+	Logo        string               `json:"logo" bson:"logo"`
+	CreatedAt   time.Time            `json:"created_at" bson:"created_at"`
+	IsDeleted   bool                 `json:"-" bson:"is_deleted"`
+	TaskCount   CountTaskDetail      `json:"task_count" bson:"task_count"`
+	Milestones  []Milestone          `json:"milestones,omitempty" bson:"-"` // filled in the project detail
+	Effort      *ProjectEffort       `json:"effort,omitempty" bson:"-"`     // filled in the project detail
+	// filled by WorkingCalendarCollRepository.FillProjectWorkingDaysLeft for projects that are not finished
+	WorkingDaysLeft *int `json:"working_days_left,omitempty" bson:"-"`
+}
+
+type ProjectMember struct {
+	UserID bson.ObjectID `json:"user_id" bson:"user_id"`
+	Role   string        `json:"role" bson:"role"` // owner, manager, member
+}
+
+type WorkflowTransition struct {
+	From      string   `json:"from" bson:"from"`
+	To        string   `json:"to" bson:"to"`
+	Roles     []string `json:"roles" bson:"roles"`         // project roles allowed, a role includes the roles ranked above it
+	Positions []string `json:"positions" bson:"positions"` // user positions allowed, e.g. QA
+}
+
+// DefaultWorkflow is used by projects without their own workflow. Every member may move tasks
+// between statuses, but finished and cancelled tasks have to be reopened first.
+func DefaultWorkflow() []WorkflowTransition {
+	return []WorkflowTransition{
+		{From: _const.TaskActive, To: _const.TaskTesting},
+		{From: _const.TaskActive, To: _const.TaskCompleted},
+		{From: _const.TaskActive, To: _const.TaskCancelled},
+		{From: _const.TaskTesting, To: _const.TaskActive},
+		{From: _const.TaskTesting, To: _const.TaskCompleted},
+		{From: _const.TaskTesting, To: _const.TaskCancelled},
+		{From: _const.TaskCompleted, To: _const.TaskActive},
+		{From: _const.TaskCancelled, To: _const.TaskActive},
+	}
+}
+
+// EffectiveWorkflow returns the workflow of the project, or the default one when none is defined
+func (u *Project) EffectiveWorkflow() []WorkflowTransition {
+	if len(u.Workflow) == 0 {
+		return DefaultWorkflow()
+	}
+	return u.Workflow
+}
+
+// FindTransition returns the workflow transition between two statuses, or nil when it is not allowed
+func (u *Project) FindTransition(from, to string) *WorkflowTransition {
+	for _, transition := range u.EffectiveWorkflow() {
+		if transition.From == from && transition.To == to {
+			return &transition
+		}
+	}
+	return nil
+}
+
+type BoardColumn struct {
+	Key      string `json:"key" bson:"key"`
+	Name     string `json:"name" bson:"name"`
+	Category string `json:"category" bson:"category"` // task status of the tasks in the column
+}
+
+// DefaultColumns is the board of projects without their own columns, one column per task status
+func DefaultColumns() []BoardColumn {
+	return []BoardColumn{
+		{Key: _const.TaskActive, Name: "Active", Category: _const.TaskActive},
+		{Key: _const.TaskTesting, Name: "Testing", Category: _const.TaskTesting},
+		{Key: _const.TaskCompleted, Name: "Completed", Category: _const.TaskCompleted},
+		{Key: _const.TaskCancelled, Name: "Cancelled", Category: _const.TaskCancelled},
+	}
+}
+
+// EffectiveColumns returns the board columns of the project, or the default ones when none are defined
+func (u *Project) EffectiveColumns() []BoardColumn {
+	if len(u.Columns) == 0 {
+		return DefaultColumns()
+	}
+	return u.Columns
+}
+
+// FindColumn returns the board column with the key, or nil when the project has no such column
+func (u *Project) FindColumn(key string) *BoardColumn {
+	for _, column := range u.EffectiveColumns() {
+		if column.Key == key {
+			return &column
+		}
+	}
+	return nil
+}
+
+// ColumnOf returns the key of the column the task is shown in. Tasks without a column, or whose column
+// was removed or belongs to another status, are shown in the first column of their status.
+func (u *Project) ColumnOf(task *Task) string {
+	if column := u.FindColumn(task.Column); column != nil && column.Category == task.Status {
+		return column.Key
+	}
+	for _, column := range u.EffectiveColumns() {
+		if column.Category == task.Status {
+			return column.Key
+		}
+	}
+	return ""
+}
+
+// MemberRole returns the project role of the user, or an empty string when the user is not a contributor.
+// Contributors without an entry are members, or managers on projects without an owner, which were created
+// before project roles existed and would otherwise only be manageable by admins and maintainers.
+func (u *Project) MemberRole(userID bson.ObjectID) string {
+	if !slices.Contains(u.Contributor, userID) {
+		return ""
+	}
+	for _, member := range u.Members {
+		if member.UserID == userID && _const.IsValidProjectRole(member.Role) {
+			return member.Role
+		}
+	}
+	if u.Owner() == bson.NilObjectID {
+		return _const.ProjectRoleManager
+	}
+	return _const.ProjectRoleMember
+}
+
+// SetMemberRole stores the role of a contributor, members are not stored explicitly
+func (u *Project) SetMemberRole(userID bson.ObjectID, role string) {
+	members := make([]ProjectMember, 0)
+	for _, member := range u.Members {
+		if member.UserID != userID {
+			members = append(members, member)
+		}
+	}
+	if role != _const.ProjectRoleMember {
+		members = append(members, ProjectMember{UserID: userID, Role: role})
+	}
+	u.Members = members
+}
+
+// Owner returns the owner of the project, or bson.NilObjectID for projects created before project roles existed
+func (u *Project) Owner() bson.ObjectID {
+	for _, member := range u.Members {
+		if member.Role == _const.ProjectRoleOwner && slices.Contains(u.Contributor, member.UserID) {
+			return member.UserID
+		}
+	}
+	return bson.NilObjectID
 }
 
 func (u *Project) MarshalJSON() ([]byte, error) {
@@ -85,6 +229,20 @@ func NewProjectCollRepository(db *mongo.Database) *ProjectCollRepository {
 	}
 }
 
+// taskCountLookup joins the tasks counted in task_count, only top-level tasks when the query asks for it
+func taskCountLookup(cq *util.CommonQuery) bson.M {
+	lookup := bson.M{
+		"from":         "tasks",
+		"localField":   "_id",
+		"foreignField": "project_id",
+		"as":           "tasks",
+	}
+	if cq.TopLevel {
+		lookup["pipeline"] = []bson.M{{"$match": TopLevelTaskFilter}}
+	}
+	return lookup
+}
+
 func (r *ProjectCollRepository) FindAll(cq *util.CommonQuery) ([]Project, error) {
 	projects := []Project{}
 
@@ -105,6 +263,10 @@ func (r *ProjectCollRepository) FindAll(cq *util.CommonQuery) ([]Project, error)
 		matchStage["contributor"] = cq.UserId
 	}
 
+	if cq.ProjectIds != nil {
+		matchStage["_id"] = bson.M{"$in": cq.ProjectIds}
+	}
+
 	if existingOr, ok := matchStage["$or"]; ok {
 		matchStage["$or"] = append(existingOr.([]bson.M), bson.M{
 			"$or": []bson.M{
@@ -137,12 +299,7 @@ func (r *ProjectCollRepository) FindAll(cq *util.CommonQuery) ([]Project, error)
 			"$match": matchStage,
 		},
 		{
-			"$lookup": bson.M{
-				"from":         "tasks",
-				"localField":   "_id",
-				"foreignField": "project_id",
-				"as":           "tasks",
-			},
+			"$lookup": taskCountLookup(cq),
 		},
 		{
 			"$addFields": bson.M{
@@ -183,7 +340,23 @@ func (r *ProjectCollRepository) FindAll(cq *util.CommonQuery) ([]Project, error)
 							},
 						},
 					},
-					"total": bson.M{"$size": "$tasks"},
+					"total":  bson.M{"$size": "$tasks"},
+					"points": bson.M{"$sum": "$tasks.story_points"},
+					"completed_points": bson.M{
+						"$sum": bson.M{
+							"$map": bson.M{
+								"input": bson.M{
+									"$filter": bson.M{
+										"input": "$tasks",
+										"as":    "task",
+										"cond":  bson.M{"$eq": []interface{}{"$$task.status", _const.TaskCompleted}},
+									},
+								},
+								"as": "task",
+								"in": "$$task.story_points",
+							},
+						},
+					},
 				},
 			},
 		},
@@ -215,6 +388,11 @@ func (r *ProjectCollRepository) FindAll(cq *util.CommonQuery) ([]Project, error)
 }
 
 func (r *ProjectCollRepository) FindOneByID(_id bson.ObjectID) (*Project, error) {
+	return r.FindOneByIDQuery(_id, util.NilCommonQuery())
+}
+
+// FindOneByIDQuery returns the project, its task_count only counts top-level tasks when the query asks for it
+func (r *ProjectCollRepository) FindOneByIDQuery(_id bson.ObjectID, cq *util.CommonQuery) (*Project, error) {
 	project := Project{}
 
 	pipeline := []bson.M{
@@ -225,12 +403,7 @@ func (r *ProjectCollRepository) FindOneByID(_id bson.ObjectID) (*Project, error)
 			},
 		},
 		{
-			"$lookup": bson.M{
-				"from":         "tasks",
-				"localField":   "_id",
-				"foreignField": "project_id",
-				"as":           "tasks",
-			},
+			"$lookup": taskCountLookup(cq),
 		},
 		{
 			"$addFields": bson.M{
@@ -271,7 +444,23 @@ func (r *ProjectCollRepository) FindOneByID(_id bson.ObjectID) (*Project, error)
 							},
 						},
 					},
-					"total": bson.M{"$size": "$tasks"},
+					"total":  bson.M{"$size": "$tasks"},
+					"points": bson.M{"$sum": "$tasks.story_points"},
+					"completed_points": bson.M{
+						"$sum": bson.M{
+							"$map": bson.M{
+								"input": bson.M{
+									"$filter": bson.M{
+										"input": "$tasks",
+										"as":    "task",
+										"cond":  bson.M{"$eq": []interface{}{"$$task.status", _const.TaskCompleted}},
+									},
+								},
+								"as": "task",
+								"in": "$$task.story_points",
+							},
+						},
+					},
 				},
 			},
 		},
@@ -317,6 +506,10 @@ func (r *ProjectCollRepository) CountProject(cq *util.CommonQuery) (*CountProjec
 		matchStage = append(matchStage, bson.E{Key: "contributor", Value: cq.UserId})
 	}
 
+	if cq.ProjectIds != nil {
+		matchStage = append(matchStage, bson.E{Key: "_id", Value: bson.M{"$in": cq.ProjectIds}})
+	}
+
 	matchStage = append(matchStage, bson.E{
 		Key: "$or",
 		Value: bson.A{
@@ -384,6 +577,10 @@ func (r *ProjectCollRepository) CountProjectTypes(cq *util.CommonQuery) ([]Count
 		matchStage = append(matchStage, bson.E{Key: "contributor", Value: cq.UserId})
 	}
 
+	if cq.ProjectIds != nil {
+		matchStage = append(matchStage, bson.E{Key: "_id", Value: bson.M{"$in": cq.ProjectIds}})
+	}
+
 	matchStage = append(matchStage, bson.E{
 		Key: "$or",
 		Value: bson.A{
@@ -420,6 +617,35 @@ func (r *ProjectCollRepository) CountProjectTypes(cq *util.CommonQuery) ([]Count
 	return count, nil
 }
 
+// FindIDsByContributor returns the IDs of every project the user contributes to
+func (r *ProjectCollRepository) FindIDsByContributor(userID bson.ObjectID) ([]bson.ObjectID, error) {
+	ids := make([]bson.ObjectID, 0)
+	filter := bson.M{
+		"contributor": userID,
+		"is_deleted":  bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	for cursor.Next(context.TODO()) {
+		result := struct {
+			ID bson.ObjectID `bson:"_id"`
+		}{}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, err
+		}
+		ids = append(ids, result.ID)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *ProjectCollRepository) InsertOne(projectData *Project) (*Project, error) {
 	data := Project{}
 	dataInsert, err := r.coll.InsertOne(context.TODO(), projectData)
@@ -435,12 +661,75 @@ func (r *ProjectCollRepository) InsertOne(projectData *Project) (*Project, error
 	return &data, nil
 }
 
+// UpdateOneByID saves the fields of the project form. Members, workflow and columns have their own updates,
+// only the roles of removed contributors are dropped here.
 func (r *ProjectCollRepository) UpdateOneByID(projectData *Project) error {
 	filter := bson.M{
 		"_id":        projectData.ID,
 		"is_deleted": bson.M{"$ne": true},
 	}
-	update := bson.M{"$set": projectData}
+	update := bson.M{
+		"$set": bson.M{
+			"name":        projectData.Name,
+			"description": projectData.Description,
+			"type":        projectData.Type,
+			"start_date":  projectData.StartDate,
+			"end_date":    projectData.EndDate,
+			"status":      projectData.Status,
+			"contributor": projectData.Contributor,
+			"logo":        projectData.Logo,
+			"attachments": projectData.Attachments,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+
+	// members may be stored as null on projects saved before project roles existed
+	contributors := projectData.Contributor
+	if contributors == nil {
+		contributors = []bson.ObjectID{}
+	}
+	filter = bson.M{
+		"_id":     projectData.ID,
+		"members": bson.M{"$type": "array"},
+	}
+	update = bson.M{
+		"$pull": bson.M{
+			"members": bson.M{"user_id": bson.M{"$nin": contributors}},
+		},
+	}
+
+	_, err = r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateMembers saves the roles of the contributors
+func (r *ProjectCollRepository) UpdateMembers(_id bson.ObjectID, members []ProjectMember) error {
+	return r.updateField(_id, "members", members)
+}
+
+// UpdateWorkflow saves the task status transitions, empty restores the default workflow
+func (r *ProjectCollRepository) UpdateWorkflow(_id bson.ObjectID, workflow []WorkflowTransition) error {
+	return r.updateField(_id, "workflow", workflow)
+}
+
+// UpdateColumns saves the board columns, empty restores the default columns
+func (r *ProjectCollRepository) UpdateColumns(_id bson.ObjectID, columns []BoardColumn) error {
+	return r.updateField(_id, "columns", columns)
+}
+
+func (r *ProjectCollRepository) updateField(_id bson.ObjectID, field string, value interface{}) error {
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": bson.M{field: value}}
 
 	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
 	if err != nil {
@@ -6,21 +6,301 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/util"
+	"slices"
 	"time"
 )
 
 type Schedule struct {
-	ID          bson.ObjectID   `json:"_id" bson:"_id"`
-	Name        string          `json:"name" bson:"name"`
-	Description string          `json:"description" bson:"description"`
-	StartDate   time.Time       `json:"start_date" bson:"start_date"`
-	EndDate     time.Time       `json:"end_date" bson:"end_date"`
-	StartTime   string          `json:"start_time" bson:"start_time"` // 24-hour format (HH:MM)
-	EndTime     string          `json:"end_time" bson:"end_time"`     // 24-hour format (HH:MM)
-	Contributor []bson.ObjectID `json:"contributor" bson:"contributor"`
-	Type        string          `json:"type" bson:"type"` // meeting, discussion, review, presentation
-	CreatedAt   time.Time       `json:"created_at" bson:"created_at"`
-	IsDeleted   bool            `json:"-" bson:"is_deleted"`
+	ID             bson.ObjectID       `json:"_id" bson:"_id"`
+	Name           string              `json:"name" bson:"name"`
+	Description    string              `json:"description" bson:"description"`
+	StartDate      time.Time           `json:"start_date" bson:"start_date"`
+	EndDate        time.Time           `json:"end_date" bson:"end_date"`
+	StartTime      string              `json:"start_time" bson:"start_time"` // 24-hour format (HH:MM)
+	EndTime        string              `json:"end_time" bson:"end_time"`     // 24-hour format (HH:MM)
+	Contributor    []bson.ObjectID     `json:"contributor" bson:"contributor"`
+	Type           string              `json:"type" bson:"type"`             // meeting, discussion, review, presentation
+	ProjectID      bson.ObjectID       `json:"project_id" bson:"project_id"` // optional, nil when not tied to a project
+	Recurrence     *ScheduleRecurrence `json:"recurrence" bson:"recurrence"`
+	ExceptionDates []time.Time         `json:"exception_dates" bson:"exception_dates"`
+	Overrides      []ScheduleOverride  `json:"overrides" bson:"overrides"`
+	CreatedAt      time.Time           `json:"created_at" bson:"created_at"`
+	IsDeleted      bool                `json:"-" bson:"is_deleted"`
+}
+
+// ScheduleRecurrence repeats a schedule in the spirit of an iCalendar RRULE.
+// Until and Count are mutually exclusive, when both are empty the schedule repeats forever.
+type ScheduleRecurrence struct {
+	Frequency string     `json:"frequency" bson:"frequency"` // daily, weekly, monthly
+	Interval  int        `json:"interval" bson:"interval"`
+	Weekdays  []int      `json:"weekdays" bson:"weekdays"` // 0 (Sunday) - 6 (Saturday), weekly only
+	Until     *time.Time `json:"until" bson:"until"`
+	Count     int        `json:"count" bson:"count"`
+}
+
+// ScheduleOverride replaces the details of a single occurrence, identified by its original date
+type ScheduleOverride struct {
+	Date        time.Time `json:"date" bson:"date"`
+	Name        string    `json:"name" bson:"name"`
+	Description string    `json:"description" bson:"description"`
+	StartTime   string    `json:"start_time" bson:"start_time"`
+	EndTime     string    `json:"end_time" bson:"end_time"`
+}
+
+// ScheduleOccurrence is a single, expanded instance of a schedule
+type ScheduleOccurrence struct {
+	ScheduleID  bson.ObjectID   `json:"schedule_id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     time.Time       `json:"end_date"`
+	StartTime   string          `json:"start_time"`
+	EndTime     string          `json:"end_time"`
+	Contributor []bson.ObjectID `json:"contributor"`
+	Type        string          `json:"type"`
+	IsRecurring bool            `json:"is_recurring"`
+	IsOverride  bool            `json:"is_override"`
+}
+
+const (
+	maxScheduleOccurrences = 1000
+	maxScheduleIterations  = 100000
+	conflictLookahead      = 365 // days
+)
+
+func scheduleDayKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// eachOccurrenceDate calls fn with the start of every occurrence in ascending order,
+// including exception dates, until fn returns false or the recurrence ends.
+func (s *Schedule) eachOccurrenceDate(fn func(time.Time) bool) {
+	start := s.StartDate.Local()
+	if s.Recurrence == nil {
+		fn(start)
+		return
+	}
+
+	r := s.Recurrence
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	generated := 0
+	emit := func(d time.Time) bool {
+		if r.Until != nil && scheduleDayKey(d) > scheduleDayKey(*r.Until) {
+			return false
+		}
+		if r.Count > 0 && generated >= r.Count {
+			return false
+		}
+		generated++
+		return fn(d)
+	}
+
+	switch r.Frequency {
+	case _const.RecurrenceDaily:
+		for i := 0; i < maxScheduleIterations; i++ {
+			if !emit(start.AddDate(0, 0, i*interval)) {
+				return
+			}
+		}
+	case _const.RecurrenceWeekly:
+		// Weeks start on Monday, matching util.StartOfWeek.
+		offsets := make([]int, 0)
+		for _, weekday := range r.Weekdays {
+			offsets = append(offsets, (weekday+6)%7)
+		}
+		if len(offsets) == 0 {
+			offsets = append(offsets, (int(start.Weekday())+6)%7)
+		}
+		slices.Sort(offsets)
+		offsets = slices.Compact(offsets)
+
+		weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		for w := 0; w < maxScheduleIterations; w++ {
+			base := weekStart.AddDate(0, 0, w*7*interval)
+			for _, offset := range offsets {
+				d := base.AddDate(0, 0, offset)
+				if d.Before(start) {
+					continue
+				}
+				if !emit(d) {
+					return
+				}
+			}
+		}
+	case _const.RecurrenceMonthly:
+		for i := 0; i < maxScheduleIterations; i++ {
+			d := start.AddDate(0, i*interval, 0)
+			// Months without this day (e.g. the 31st) are skipped, as RFC 5545 does.
+			if d.Day() != start.Day() {
+				continue
+			}
+			if !emit(d) {
+				return
+			}
+		}
+	default:
+		fn(start)
+	}
+}
+
+func (s *Schedule) IsException(date time.Time) bool {
+	key := scheduleDayKey(date)
+	for _, exception := range s.ExceptionDates {
+		if scheduleDayKey(exception) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOccurrenceDate reports whether the recurrence generates an occurrence on the given day
+func (s *Schedule) IsOccurrenceDate(date time.Time) bool {
+	key := scheduleDayKey(date)
+	found := false
+	s.eachOccurrenceDate(func(d time.Time) bool {
+		k := scheduleDayKey(d)
+		if k == key {
+			found = true
+		}
+		return k < key
+	})
+	return found
+}
+
+func (s *Schedule) FindOverride(date time.Time) (int, *ScheduleOverride) {
+	key := scheduleDayKey(date)
+	for i := range s.Overrides {
+		if scheduleDayKey(s.Overrides[i].Date) == key {
+			return i, &s.Overrides[i]
+		}
+	}
+	return -1, nil
+}
+
+func (s *Schedule) occurrence(date time.Time, span time.Duration) ScheduleOccurrence {
+	o := ScheduleOccurrence{
+		ScheduleID:  s.ID,
+		Name:        s.Name,
+		Description: s.Description,
+		StartDate:   date,
+		EndDate:     date.Add(span),
+		StartTime:   s.StartTime,
+		EndTime:     s.EndTime,
+		Contributor: s.Contributor,
+		Type:        s.Type,
+		IsRecurring: s.Recurrence != nil,
+	}
+	if _, override := s.FindOverride(date); override != nil {
+		o.IsOverride = true
+		if override.Name != "" {
+			o.Name = override.Name
+		}
+		if override.Description != "" {
+			o.Description = override.Description
+		}
+		if override.StartTime != "" {
+			o.StartTime = override.StartTime
+		}
+		if override.EndTime != "" {
+			o.EndTime = override.EndTime
+		}
+	}
+	return o
+}
+
+// Occurrences expands the schedule into the occurrences taking place within [from, to)
+func (s *Schedule) Occurrences(from, to time.Time) []ScheduleOccurrence {
+	occurrences := make([]ScheduleOccurrence, 0)
+	span := s.EndDate.Sub(s.StartDate)
+
+	s.eachOccurrenceDate(func(d time.Time) bool {
+		if !d.Before(to) {
+			return false
+		}
+		if d.Add(span).Before(from) || s.IsException(d) {
+			return true
+		}
+		occurrences = append(occurrences, s.occurrence(d, span))
+		return len(occurrences) < maxScheduleOccurrences
+	})
+	return occurrences
+}
+
+// SeriesEnd returns the end of the last occurrence, or the zero time when the schedule repeats forever
+func (s *Schedule) SeriesEnd() time.Time {
+	span := s.EndDate.Sub(s.StartDate)
+	if s.Recurrence == nil {
+		return s.EndDate
+	}
+	if s.Recurrence.Until == nil && s.Recurrence.Count <= 0 {
+		return time.Time{}
+	}
+
+	last := s.StartDate
+	s.eachOccurrenceDate(func(d time.Time) bool {
+		last = d
+		return true
+	})
+	return last.Add(span)
+}
+
+// SharedContributors returns the contributors present in both schedules
+func (s *Schedule) SharedContributors(o *Schedule) []bson.ObjectID {
+	shared := make([]bson.ObjectID, 0)
+	for _, a := range s.Contributor {
+		for _, b := range o.Contributor {
+			if a == b {
+				shared = append(shared, a)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+// Overlaps reports whether both occurrences take place at the same time.
+// Dates are compared by calendar day and times by their daily HH:MM window.
+func (o *ScheduleOccurrence) Overlaps(p *ScheduleOccurrence) bool {
+	if scheduleDayKey(o.StartDate) > scheduleDayKey(p.EndDate) || scheduleDayKey(p.StartDate) > scheduleDayKey(o.EndDate) {
+		return false
+	}
+	return o.StartTime < p.EndTime && p.StartTime < o.EndTime
+}
+
+// Overlaps reports whether both schedules share a contributor and any of their occurrences
+// take place at the same time. Endless series are compared over the next year only.
+func (s *Schedule) Overlaps(o *Schedule) bool {
+	if len(s.SharedContributors(o)) == 0 {
+		return false
+	}
+
+	from := s.StartDate
+	if o.StartDate.After(from) {
+		from = o.StartDate
+	}
+	to := from.AddDate(0, 0, conflictLookahead)
+	for _, end := range []time.Time{s.SeriesEnd(), o.SeriesEnd()} {
+		if !end.IsZero() && end.Before(to) {
+			to = end
+		}
+	}
+	// Occurrences is end-exclusive, include occurrences starting on the last day.
+	to = to.Add(time.Millisecond)
+
+	occurrences := s.Occurrences(from.AddDate(0, 0, -1), to)
+	others := o.Occurrences(from.AddDate(0, 0, -1), to)
+	for i := range occurrences {
+		for j := range others {
+			if occurrences[i].Overlaps(&others[j]) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type ScheduleCollRepository struct {
@@ -35,7 +315,7 @@ func NewScheduleCollRepository(db *mongo.Database) *ScheduleCollRepository {
 
 func (r *ScheduleCollRepository) FindAll(cq *util.CommonQuery) ([]Schedule, error) {
 	schedules := []Schedule{}
-	filter := bson.M{"is_deleted": false}
+	filter := bson.M{"is_deleted": bson.M{"$ne": true}}
 
 	if len(cq.Q) > 0 {
 		filter["$or"] = []bson.M{
@@ -52,28 +332,41 @@ func (r *ScheduleCollRepository) FindAll(cq *util.CommonQuery) ([]Schedule, erro
 		filter["contributor"] = cq.UserId
 	}
 
-	if existingOr, ok := filter["$or"]; ok {
-		filter["$or"] = append(existingOr.([]bson.M), bson.M{
-			"$or": []bson.M{
-				{
-					"start_date": bson.M{"$lt": cq.End},
-					"end_date":   bson.M{"$gte": cq.Start},
-				},
-				{
-					"start_date": bson.M{"$gte": cq.Start, "$lt": cq.End},
-				},
-			},
-		})
-	} else {
-		filter["$or"] = []bson.M{
+	if cq.ProjectId != bson.NilObjectID {
+		filter["project_id"] = cq.ProjectId
+	} else if cq.ProjectIds != nil {
+		// Schedules without a project stay visible to everyone, null also matches a missing field.
+		projectIds := bson.A{nil, bson.NilObjectID}
+		for _, projectId := range cq.ProjectIds {
+			projectIds = append(projectIds, projectId)
+		}
+		filter["project_id"] = bson.M{"$in": projectIds}
+	}
+
+	window := bson.M{
+		"$or": []bson.M{
 			{
+				"recurrence": nil,
 				"start_date": bson.M{"$lt": cq.End},
 				"end_date":   bson.M{"$gte": cq.Start},
 			},
 			{
+				"recurrence": nil,
 				"start_date": bson.M{"$gte": cq.Start, "$lt": cq.End},
 			},
-		}
+			{
+				// Recurring schedules are expanded by the caller through Occurrences.
+				"recurrence": bson.M{"$ne": nil},
+				"start_date": bson.M{"$lt": cq.End},
+			},
+		},
+	}
+
+	if existingOr, ok := filter["$or"]; ok {
+		delete(filter, "$or")
+		filter["$and"] = []bson.M{{"$or": existingOr}, window}
+	} else {
+		filter["$or"] = window["$or"]
 	}
 
 	cursor, err := r.coll.Find(context.TODO(), filter)
@@ -93,3 +386,95 @@ func (r *ScheduleCollRepository) CreateOne(schedule *Schedule) error {
 	}
 	return nil
 }
+
+func (r *ScheduleCollRepository) FindOneByID(_id bson.ObjectID) (*Schedule, error) {
+	schedule := Schedule{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// FindConflicts returns schedules sharing at least one contributor with the given schedule
+// where any of their occurrences overlap by date and daily time window.
+func (r *ScheduleCollRepository) FindConflicts(schedule *Schedule) ([]Schedule, error) {
+	schedules := []Schedule{}
+	conflicts := []Schedule{}
+
+	if len(schedule.Contributor) == 0 {
+		return conflicts, nil
+	}
+
+	seriesEnd := schedule.SeriesEnd()
+	if seriesEnd.IsZero() {
+		seriesEnd = schedule.StartDate.AddDate(0, 0, conflictLookahead)
+	}
+
+	filter := bson.M{
+		"_id":         bson.M{"$ne": schedule.ID},
+		"is_deleted":  bson.M{"$ne": true},
+		"contributor": bson.M{"$in": schedule.Contributor},
+		"start_date":  bson.M{"$lte": seriesEnd},
+		"$or": []bson.M{
+			{
+				"recurrence": nil,
+				"end_date":   bson.M{"$gte": schedule.StartDate},
+			},
+			{
+				"recurrence": bson.M{"$ne": nil},
+			},
+		},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &schedules); err != nil {
+		return nil, err
+	}
+
+	for _, s := range schedules {
+		if schedule.Overlaps(&s) {
+			conflicts = append(conflicts, s)
+		}
+	}
+	return conflicts, nil
+}
+
+func (r *ScheduleCollRepository) UpdateOneByID(schedule *Schedule) error {
+	filter := bson.M{
+		"_id":        schedule.ID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": schedule}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ScheduleCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
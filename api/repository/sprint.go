@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/const"
+	"time"
+)
+
+type Sprint struct {
+	ID          bson.ObjectID `json:"_id" bson:"_id"`
+	ProjectID   bson.ObjectID `json:"project_id" bson:"project_id"`
+	Name        string        `json:"name" bson:"name"`
+	Goal        string        `json:"goal" bson:"goal"`
+	StartDate   time.Time     `json:"start_date" bson:"start_date"`
+	EndDate     time.Time     `json:"end_date" bson:"end_date"`
+	Status      string        `json:"status" bson:"status"` // planned, active, completed
+	StartedAt   time.Time     `json:"started_at" bson:"started_at"`
+	CompletedAt time.Time     `json:"completed_at" bson:"completed_at"`
+	CreatedAt   time.Time     `json:"created_at" bson:"created_at"`
+	IsDeleted   bool          `json:"-" bson:"is_deleted"`
+
+	// recorded when the sprint starts and completes, the sprint report is built from them
+	CommittedTaskIDs   []bson.ObjectID `json:"committed_task_ids" bson:"committed_task_ids"`
+	CompletedTaskIDs   []bson.ObjectID `json:"completed_task_ids" bson:"completed_task_ids"`
+	CarriedOverTaskIDs []bson.ObjectID `json:"carried_over_task_ids" bson:"carried_over_task_ids"`
+	NextSprintID       bson.ObjectID   `json:"next_sprint_id" bson:"next_sprint_id"` // where unfinished tasks went, nil for the backlog
+}
+
+type SprintCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewSprintCollRepository(db *mongo.Database) *SprintCollRepository {
+	return &SprintCollRepository{
+		coll: db.Collection("sprints"),
+	}
+}
+
+// FindAllByProjectID returns the sprints of the project by start date
+func (r *SprintCollRepository) FindAllByProjectID(projectID bson.ObjectID) ([]Sprint, error) {
+	sprints := []Sprint{}
+	filter := bson.M{
+		"project_id": projectID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &sprints); err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+func (r *SprintCollRepository) FindOneByID(_id bson.ObjectID) (*Sprint, error) {
+	sprint := Sprint{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&sprint)
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// FindActiveByProjectID returns the running sprint of the project
+func (r *SprintCollRepository) FindActiveByProjectID(projectID bson.ObjectID) (*Sprint, error) {
+	sprint := Sprint{}
+	filter := bson.M{
+		"project_id": projectID,
+		"status":     _const.SprintActive,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&sprint)
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// CreateIndexes allows a single running sprint per project
+func (r *SprintCollRepository) CreateIndexes() error {
+	_, err := r.coll.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "project_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"status":     _const.SprintActive,
+			"is_deleted": false,
+		}),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *SprintCollRepository) CreateOne(sprint *Sprint) error {
+	_, err := r.coll.InsertOne(context.TODO(), sprint)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *SprintCollRepository) UpdateOneByID(sprint *Sprint) error {
+	filter := bson.M{
+		"_id":        sprint.ID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": sprint}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateStatus saves the status of the sprint only while it still has the expected status, so two requests cannot
+// start or complete the same sprint. It reports whether the sprint was saved.
+func (r *SprintCollRepository) UpdateStatus(sprint *Sprint, from string) (bool, error) {
+	filter := bson.M{
+		"_id":        sprint.ID,
+		"status":     from,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":                sprint.Status,
+			"started_at":            sprint.StartedAt,
+			"completed_at":          sprint.CompletedAt,
+			"committed_task_ids":    sprint.CommittedTaskIDs,
+			"completed_task_ids":    sprint.CompletedTaskIDs,
+			"carried_over_task_ids": sprint.CarriedOverTaskIDs,
+			"next_sprint_id":        sprint.NextSprintID,
+		},
+	}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// Complete marks the running sprint completed and then carries its unfinished tasks over. It reports whether
+// the sprint was still running. When carrying over fails the sprint stays completed, CarryOver finishes the move.
+func (r *SprintCollRepository) Complete(sprint *Sprint) (bool, error) {
+	saved, err := r.UpdateStatus(sprint, _const.SprintActive)
+	if err != nil || !saved {
+		return false, err
+	}
+	return true, r.CarryOver(sprint)
+}
+
+// CarryOver moves the carried over tasks still in the completed sprint into the next sprint, so it can be repeated
+func (r *SprintCollRepository) CarryOver(sprint *Sprint) error {
+	return NewTaskCollRepository(r.coll.Database()).MoveSprint(sprint.CarriedOverTaskIDs, sprint.ID, sprint.NextSprintID)
+}
+
+func (r *SprintCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *SprintCollRepository) DeleteAllByProjectID(projectID bson.ObjectID) error {
+	filter := bson.M{
+		"project_id": projectID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -2,24 +2,275 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/util"
+	"sort"
 	"time"
 )
 
 type Task struct {
-	ID          bson.ObjectID   `json:"_id" bson:"_id"`
-	Name        string          `json:"name" bson:"name"`
-	Description string          `json:"description" bson:"description"`
-	StartDate   time.Time       `json:"start_date" bson:"start_date"`
-	EndDate     time.Time       `json:"end_date" bson:"end_date"`
-	Contributor []bson.ObjectID `json:"contributor" bson:"contributor"`
-	Status      string          `json:"status" bson:"status"` // active, testing, completed, cancelled
-	ProjectID   bson.ObjectID   `json:"project_id" bson:"project_id"`
-	CreatedAt   time.Time       `json:"created_at" bson:"created_at"`
-	IsDeleted   bool            `json:"-" bson:"is_deleted"`
+	ID             bson.ObjectID      `json:"_id" bson:"_id"`
+	Name           string             `json:"name" bson:"name"`
+	Description    string             `json:"description" bson:"description"`
+	StartDate      time.Time          `json:"start_date" bson:"start_date"`
+	EndDate        time.Time          `json:"end_date" bson:"end_date"`
+	Contributor    []bson.ObjectID    `json:"contributor" bson:"contributor"`
+	Status         string             `json:"status" bson:"status"` // active, testing, completed, cancelled
+	StoryPoints    int                `json:"story_points" bson:"story_points"`
+	EstimatedHours float64            `json:"estimated_hours" bson:"estimated_hours"`
+	ProjectID      bson.ObjectID      `json:"project_id" bson:"project_id"`
+	ParentID       bson.ObjectID      `json:"parent_id" bson:"parent_id"` // nil for top-level tasks
+	SprintID       bson.ObjectID      `json:"sprint_id" bson:"sprint_id"` // nil for tasks in the backlog
+	Checklist      []ChecklistItem    `json:"checklist" bson:"checklist"`
+	BlockedBy      []bson.ObjectID    `json:"blocked_by" bson:"blocked_by"` // tasks that have to end before this task starts
+	StatusHistory  []TaskStatusChange `json:"status_history" bson:"status_history"`
+	Column         string             `json:"column" bson:"column"` // board column key, empty shows the task in the first column of its status
+	Rank           float64            `json:"rank" bson:"rank"`     // order within the board column, lowest first
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	IsDeleted      bool               `json:"-" bson:"is_deleted"`
+
+	CommentCount int                 `json:"comment_count" bson:"-"` // filled by CommentCollRepository.FillTaskCommentCount
+	Progress     int                 `json:"progress" bson:"-"`      // percentage, filled by CalculateProgress
+	Subtasks     []Task              `json:"subtasks,omitempty" bson:"-"`
+	Warnings     []DependencyWarning `json:"warnings,omitempty" bson:"-"` // filled by DependencyWarnings
+	// filled by WorkingCalendarCollRepository.FillTaskWorkingDaysLeft for tasks that are not finished
+	WorkingDaysLeft *int `json:"working_days_left,omitempty" bson:"-"`
+}
+
+type DependencyWarning struct {
+	TaskID      bson.ObjectID `json:"task_id"`
+	BlockedByID bson.ObjectID `json:"blocked_by_id"`
+	Message     string        `json:"message"`
+}
+
+// IndexTasks maps tasks by their ID for dependency lookups
+func IndexTasks(tasks []Task) map[bson.ObjectID]*Task {
+	index := map[bson.ObjectID]*Task{}
+	for i := range tasks {
+		index[tasks[i].ID] = &tasks[i]
+	}
+	return index
+}
+
+// DependsOn reports whether the task depends on the other task, directly or through other tasks of the index
+func DependsOn(index map[bson.ObjectID]*Task, taskID, otherID bson.ObjectID) bool {
+	visited := map[bson.ObjectID]bool{}
+	stack := []bson.ObjectID{taskID}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		task, ok := index[id]
+		if !ok || visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		for _, blockerID := range task.BlockedBy {
+			if blockerID == otherID {
+				return true
+			}
+			stack = append(stack, blockerID)
+		}
+	}
+	return false
+}
+
+// DependencyWarnings returns a warning for every dependency of the task that ends after the task is scheduled to start
+func DependencyWarnings(index map[bson.ObjectID]*Task, task *Task) []DependencyWarning {
+	warnings := make([]DependencyWarning, 0)
+	for _, blockerID := range task.BlockedBy {
+		blocker, ok := index[blockerID]
+		if !ok || blocker.Status == _const.TaskCancelled {
+			continue
+		}
+		if task.StartDate.Before(blocker.EndDate) {
+			warnings = append(warnings, DependencyWarning{
+				TaskID:      task.ID,
+				BlockedByID: blocker.ID,
+				Message:     "Starts before \"" + blocker.Name + "\" ends.",
+			})
+		}
+	}
+	return warnings
+}
+
+// CriticalPath returns the chain of dependent tasks with the longest total duration, first task first.
+// Cancelled tasks and dependencies outside the given tasks are left out.
+func CriticalPath(tasks []Task) []Task {
+	index := map[bson.ObjectID]*Task{}
+	for i := range tasks {
+		if tasks[i].Status != _const.TaskCancelled {
+			index[tasks[i].ID] = &tasks[i]
+		}
+	}
+
+	// Order the tasks so every task comes after the tasks it depends on.
+	pending := map[bson.ObjectID]int{}
+	dependents := map[bson.ObjectID][]bson.ObjectID{}
+	for id, task := range index {
+		pending[id] = 0
+		for _, blockerID := range task.BlockedBy {
+			if _, ok := index[blockerID]; ok {
+				pending[id]++
+				dependents[blockerID] = append(dependents[blockerID], id)
+			}
+		}
+	}
+	queue := make([]bson.ObjectID, 0)
+	for _, task := range tasks {
+		if _, ok := index[task.ID]; ok && pending[task.ID] == 0 {
+			queue = append(queue, task.ID)
+		}
+	}
+
+	length := map[bson.ObjectID]time.Duration{}
+	previous := map[bson.ObjectID]bson.ObjectID{}
+	last := bson.NilObjectID
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		task := index[id]
+		// found links zero length blockers, like milestones, which a comparison with zero would skip
+		longest, found := time.Duration(0), false
+		for _, blockerID := range task.BlockedBy {
+			if _, ok := index[blockerID]; ok && (!found || length[blockerID] > longest) {
+				longest, found = length[blockerID], true
+				previous[id] = blockerID
+			}
+		}
+		length[id] = longest + task.EndDate.Sub(task.StartDate)
+		if last.IsZero() || length[id] > length[last] {
+			last = id
+		}
+
+		for _, dependentID := range dependents[id] {
+			pending[dependentID]--
+			if pending[dependentID] == 0 {
+				queue = append(queue, dependentID)
+			}
+		}
+	}
+
+	path := make([]Task, 0)
+	for id := last; !id.IsZero(); id = previous[id] {
+		path = append([]Task{*index[id]}, path...)
+	}
+	return path
+}
+
+type ChecklistItem struct {
+	ID         bson.ObjectID `json:"_id" bson:"_id"`
+	Text       string        `json:"text" bson:"text"`
+	Done       bool          `json:"done" bson:"done"`
+	AssigneeID bson.ObjectID `json:"assignee_id" bson:"assignee_id"` // nil when unassigned
+}
+
+// TopLevelTaskFilter matches tasks without a parent, including tasks created before subtasks existed
+var TopLevelTaskFilter = bson.M{"parent_id": bson.M{"$in": bson.A{nil, bson.NilObjectID}}}
+
+// FindChecklistItem returns the index of the checklist item, or -1 when the task has no such item
+func (u *Task) FindChecklistItem(itemID bson.ObjectID) int {
+	for i, item := range u.Checklist {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// CalculateProgress sets the completion percentage of the task from its subtasks and checklist items,
+// each counts as one step. Cancelled subtasks are left out. Without steps the status decides.
+func (u *Task) CalculateProgress(subtasks []Task) {
+	total, done := 0, 0
+	for _, subtask := range subtasks {
+		if subtask.Status == _const.TaskCancelled {
+			continue
+		}
+		total++
+		if subtask.Status == _const.TaskCompleted {
+			done++
+		}
+	}
+	for _, item := range u.Checklist {
+		total++
+		if item.Done {
+			done++
+		}
+	}
+
+	switch {
+	case total > 0:
+		u.Progress = done * 100 / total
+	case u.Status == _const.TaskCompleted:
+		u.Progress = 100
+	default:
+		u.Progress = 0
+	}
+}
+
+type TaskStatusChange struct {
+	From      string        `json:"from" bson:"from"` // empty when the task was created
+	To        string        `json:"to" bson:"to"`
+	Reason    string        `json:"reason" bson:"reason"`
+	ActorID   bson.ObjectID `json:"actor_id" bson:"actor_id"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+}
+
+// SetStatus changes the status of the task and appends the change to its status history
+func (u *Task) SetStatus(status, reason string, actorID bson.ObjectID) {
+	u.StatusHistory = append(u.StatusHistory, TaskStatusChange{
+		From:      u.Status,
+		To:        status,
+		Reason:    reason,
+		ActorID:   actorID,
+		CreatedAt: time.Now(),
+	})
+	u.Status = status
+	// The task leaves a column of another status and shows up in the first column of the new one.
+	u.Column = ""
+}
+
+// StatusAt replays the status history and returns the status the task had at the time,
+// or an empty status when the task did not exist yet. Tasks created before status history was recorded
+// are taken as active until their end date and in their current status after it.
+func (u *Task) StatusAt(t time.Time) string {
+	if u.CreatedAt.After(t) {
+		return ""
+	}
+
+	if len(u.StatusHistory) == 0 {
+		if u.Status != _const.TaskActive && !u.EndDate.After(t) {
+			return u.Status
+		}
+		return _const.TaskActive
+	}
+
+	status := u.StatusHistory[0].From
+	if status == "" {
+		status = _const.TaskActive
+	}
+	for _, change := range u.StatusHistory {
+		if change.CreatedAt.After(t) {
+			break
+		}
+		status = change.To
+	}
+	return status
+}
+
+// SortTasksByRank orders tasks the way they are shown on the board
+func SortTasksByRank(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Rank != tasks[j].Rank {
+			return tasks[i].Rank < tasks[j].Rank
+		}
+		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+	})
 }
 
 type TaskGroup struct {
@@ -30,11 +281,13 @@ type TaskGroup struct {
 }
 
 type CountTaskDetail struct {
-	Total     int `json:"total"`
-	Active    int `json:"active"`
-	Testing   int `json:"testing"`
-	Completed int `json:"completed"`
-	Cancelled int `json:"cancelled"`
+	Total           int `json:"total"`
+	Active          int `json:"active"`
+	Testing         int `json:"testing"`
+	Completed       int `json:"completed"`
+	Cancelled       int `json:"cancelled"`
+	Points          int `json:"points" bson:"points"`                     // story points of the counted tasks
+	CompletedPoints int `json:"completed_points" bson:"completed_points"` // story points of the completed tasks
 }
 
 type CountUserActive struct {
@@ -44,11 +297,18 @@ type CountUserActive struct {
 }
 
 type TaskOverview struct {
-	Start string `json:"start"`
-	End   string `json:"end"`
-	Count int    `json:"count"`
+	Label  string `json:"label"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Count  int    `json:"count"`
+	Points int    `json:"points"` // story points completed within the period
 }
 
+const (
+	defaultOverviewGranularity = _const.GranularityWeek
+	defaultOverviewPeriods     = 8
+)
+
 type TaskCollRepository struct {
 	coll *mongo.Collection
 }
@@ -80,6 +340,16 @@ func (r *TaskCollRepository) FindAll(cq *util.CommonQuery) ([]Task, error) {
 
 	if cq.ProjectId != bson.NilObjectID {
 		filter["project_id"] = cq.ProjectId
+	} else if cq.ProjectIds != nil {
+		filter["project_id"] = bson.M{"$in": cq.ProjectIds}
+	}
+
+	if cq.TopLevel {
+		filter["parent_id"] = TopLevelTaskFilter["parent_id"]
+	}
+
+	if cq.SprintId != bson.NilObjectID {
+		filter["sprint_id"] = cq.SprintId
 	}
 
 	if existingOr, ok := filter["$or"]; ok {
@@ -116,6 +386,90 @@ func (r *TaskCollRepository) FindAll(cq *util.CommonQuery) ([]Task, error) {
 	return tasks, nil
 }
 
+// FindGroup returns the tasks matching the query grouped by status with a single query, the status filter is ignored
+func (r *TaskCollRepository) FindGroup(cq *util.CommonQuery) (*TaskGroup, error) {
+	status := cq.Status
+	cq.Status = ""
+	tasks, err := r.FindAll(cq)
+	cq.Status = status
+	if err != nil {
+		return nil, err
+	}
+	SortTasksByRank(tasks)
+
+	group := &TaskGroup{
+		Active:    []Task{},
+		Testing:   []Task{},
+		Completed: []Task{},
+		Cancelled: []Task{},
+	}
+	for _, task := range tasks {
+		switch task.Status {
+		case _const.TaskActive:
+			group.Active = append(group.Active, task)
+		case _const.TaskTesting:
+			group.Testing = append(group.Testing, task)
+		case _const.TaskCompleted:
+			group.Completed = append(group.Completed, task)
+		case _const.TaskCancelled:
+			group.Cancelled = append(group.Cancelled, task)
+		}
+	}
+	return group, nil
+}
+
+// FindAllUnfinished returns the active and testing tasks that start before the end of the query window
+func (r *TaskCollRepository) FindAllUnfinished(cq *util.CommonQuery) ([]Task, error) {
+	tasks := []Task{}
+	filter := bson.M{
+		"status":     bson.M{"$in": bson.A{_const.TaskActive, _const.TaskTesting}},
+		"start_date": bson.M{"$lt": cq.End},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	if cq.UserId != bson.NilObjectID {
+		filter["contributor"] = cq.UserId
+	}
+
+	if cq.ProjectId != bson.NilObjectID {
+		filter["project_id"] = cq.ProjectId
+	} else if cq.ProjectIds != nil {
+		filter["project_id"] = bson.M{"$in": cq.ProjectIds}
+	}
+
+	if cq.TopLevel {
+		filter["parent_id"] = TopLevelTaskFilter["parent_id"]
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindAllDueBetween returns the active and testing tasks with an end date from start to end
+func (r *TaskCollRepository) FindAllDueBetween(start, end time.Time) ([]Task, error) {
+	tasks := []Task{}
+	filter := bson.M{
+		"status":     bson.M{"$in": bson.A{_const.TaskActive, _const.TaskTesting}},
+		"end_date":   bson.M{"$gte": start, "$lt": end},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
 func (r *TaskCollRepository) FindOneByID(_id bson.ObjectID) (*Task, error) {
 	user := Task{}
 	filter := bson.M{
@@ -130,6 +484,47 @@ func (r *TaskCollRepository) FindOneByID(_id bson.ObjectID) (*Task, error) {
 	return &user, nil
 }
 
+// FindAllByIDs returns the tasks with the given IDs, deleted tasks are left out
+func (r *TaskCollRepository) FindAllByIDs(ids []bson.ObjectID) ([]Task, error) {
+	tasks := []Task{}
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+
+	filter := bson.M{
+		"_id":        bson.M{"$in": ids},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FindAllByParentID returns the subtasks of the task
+func (r *TaskCollRepository) FindAllByParentID(parentID bson.ObjectID) ([]Task, error) {
+	tasks := []Task{}
+	filter := bson.M{
+		"parent_id":  parentID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &tasks); err != nil {
+		return nil, err
+	}
+	SortTasksByRank(tasks)
+	return tasks, nil
+}
+
 func (r *TaskCollRepository) CreateOne(task *Task) error {
 	_, err := r.coll.InsertOne(context.TODO(), task)
 	if err != nil {
@@ -158,6 +553,20 @@ func (r *TaskCollRepository) CountTask(cq *util.CommonQuery) ([]CountTaskDetail,
 		matchStage = append(matchStage, bson.E{Key: "contributor", Value: cq.UserId})
 	}
 
+	if cq.ProjectId != bson.NilObjectID {
+		matchStage = append(matchStage, bson.E{Key: "project_id", Value: cq.ProjectId})
+	} else if cq.ProjectIds != nil {
+		matchStage = append(matchStage, bson.E{Key: "project_id", Value: bson.M{"$in": cq.ProjectIds}})
+	}
+
+	if cq.TopLevel {
+		matchStage = append(matchStage, bson.E{Key: "parent_id", Value: TopLevelTaskFilter["parent_id"]})
+	}
+
+	if cq.SprintId != bson.NilObjectID {
+		matchStage = append(matchStage, bson.E{Key: "sprint_id", Value: cq.SprintId})
+	}
+
 	matchStage = append(matchStage, bson.E{
 		Key: "$or",
 		Value: bson.A{
@@ -184,6 +593,10 @@ func (r *TaskCollRepository) CountTask(cq *util.CommonQuery) ([]CountTaskDetail,
 			{_const.TaskTesting, bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$status", _const.TaskTesting}}}, 1, 0}}}}}},
 			{_const.TaskCompleted, bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$status", _const.TaskCompleted}}}, 1, 0}}}}}},
 			{_const.TaskCancelled, bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$status", _const.TaskCancelled}}}, 1, 0}}}}}},
+			{Key: "points", Value: bson.M{"$sum": bson.M{"$ifNull": bson.A{"$story_points", 0}}}},
+			{Key: "completed_points", Value: bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$status", _const.TaskCompleted}}, bson.M{"$ifNull": bson.A{"$story_points", 0}}, 0,
+			}}}},
 		}},
 	}
 
@@ -197,6 +610,246 @@ func (r *TaskCollRepository) CountTask(cq *util.CommonQuery) ([]CountTaskDetail,
 	return count, nil
 }
 
+// completedAtField is the last time a task moved to completed,
+// tasks completed before status history was recorded fall back to their end date
+var completedAtField = bson.M{
+	"$ifNull": bson.A{
+		bson.M{"$max": bson.M{
+			"$map": bson.M{
+				"input": bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$status_history", bson.A{}}},
+					"as":    "change",
+					"cond":  bson.M{"$eq": bson.A{"$$change.to", _const.TaskCompleted}},
+				}},
+				"as": "change",
+				"in": "$$change.created_at",
+			},
+		}},
+		"$end_date",
+	},
+}
+
+// SumCompletedPoints returns the story points of the tasks completed within the query window
+func (r *TaskCollRepository) SumCompletedPoints(cq *util.CommonQuery) (int, error) {
+	filter := bson.M{
+		"status":     _const.TaskCompleted,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	if cq.UserId != bson.NilObjectID {
+		filter["contributor"] = cq.UserId
+	}
+
+	if cq.ProjectId != bson.NilObjectID {
+		filter["project_id"] = cq.ProjectId
+	} else if cq.ProjectIds != nil {
+		filter["project_id"] = bson.M{"$in": cq.ProjectIds}
+	}
+
+	if cq.TopLevel {
+		filter["parent_id"] = TopLevelTaskFilter["parent_id"]
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$addFields": bson.M{"completed_at": completedAtField}},
+		bson.M{"$match": bson.M{"completed_at": bson.M{"$gte": cq.Start, "$lt": cq.End}}},
+		bson.M{"$group": bson.M{
+			"_id":    nil,
+			"points": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$story_points", 0}}},
+		}},
+	}
+
+	cursor, err := r.coll.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return 0, err
+	}
+
+	var docs []struct {
+		Points int `bson:"points"`
+	}
+	if err := cursor.All(context.TODO(), &docs); err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+	return docs[0].Points, nil
+}
+
+// Overview counts the tasks running and the story points completed in each of the last periods up to the
+// current one, the granularity and number of periods come from the query and default to 8 weeks.
+// Every period is counted in one aggregation, a task spanning several periods counts in each of them.
+func (r *TaskCollRepository) Overview(cq *util.CommonQuery) ([]TaskOverview, error) {
+	granularity, periods := cq.Granularity, cq.Periods
+	if granularity == "" {
+		granularity = defaultOverviewGranularity
+	}
+	if periods == 0 {
+		periods = defaultOverviewPeriods
+	}
+
+	starts := make([]time.Time, 0, periods+1)
+	for v := 1 - periods; v <= 1; v++ {
+		starts = append(starts, util.StartOfPeriod(granularity, v))
+	}
+	start, end := starts[0], starts[periods]
+
+	filter := bson.M{"is_deleted": bson.M{"$ne": true}}
+
+	if len(cq.Q) > 0 {
+		filter["$or"] = []bson.M{
+			{"name": bson.M{"$regex": bson.Regex{Pattern: cq.Q, Options: "i"}}},
+			{"description": bson.M{"$regex": bson.Regex{Pattern: cq.Q, Options: "i"}}},
+		}
+	}
+
+	if cq.UserId != bson.NilObjectID {
+		filter["contributor"] = cq.UserId
+	}
+
+	if cq.ProjectId != bson.NilObjectID {
+		filter["project_id"] = cq.ProjectId
+	} else if cq.ProjectIds != nil {
+		filter["project_id"] = bson.M{"$in": cq.ProjectIds}
+	}
+
+	if cq.TopLevel {
+		filter["parent_id"] = TopLevelTaskFilter["parent_id"]
+	}
+
+	if cq.SprintId != bson.NilObjectID {
+		filter["sprint_id"] = cq.SprintId
+	}
+
+	timezone := util.Timezone()
+	truncate := func(date interface{}) bson.M {
+		return bson.M{"$dateTrunc": bson.M{
+			"date":        date,
+			"unit":        granularity,
+			"timezone":    timezone,
+			"startOfWeek": "monday",
+		}}
+	}
+
+	// A running task counts from the period of its start date, or the first period, to the period of its
+	// end date, or the current one. Tasks without an end date only count in the period they started.
+	running := bson.A{
+		bson.M{"$match": bson.M{
+			"status":     bson.M{"$in": bson.A{_const.TaskActive, _const.TaskTesting, _const.TaskCompleted}},
+			"start_date": bson.M{"$lt": end},
+			"$or": bson.A{
+				bson.M{"end_date": bson.M{"$gte": start}},
+				bson.M{"start_date": bson.M{"$gte": start}},
+			},
+		}},
+		bson.M{"$project": bson.M{
+			"first": truncate(bson.M{"$max": bson.A{"$start_date", start}}),
+			"last": truncate(bson.M{"$min": bson.A{
+				bson.M{"$max": bson.A{"$end_date", "$start_date"}},
+				end.Add(-time.Millisecond),
+			}}),
+		}},
+		bson.M{"$project": bson.M{
+			"first": 1,
+			"offset": bson.M{"$range": bson.A{0, bson.M{"$add": bson.A{
+				bson.M{"$dateDiff": bson.M{
+					"startDate":   "$first",
+					"endDate":     "$last",
+					"unit":        granularity,
+					"timezone":    timezone,
+					"startOfWeek": "monday",
+				}},
+				1,
+			}}}},
+		}},
+		bson.M{"$unwind": "$offset"},
+		bson.M{"$group": bson.M{
+			"_id": bson.M{"$dateAdd": bson.M{
+				"startDate": "$first",
+				"unit":      granularity,
+				"amount":    "$offset",
+				"timezone":  timezone,
+			}},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	completed := bson.A{
+		bson.M{"$match": bson.M{"status": _const.TaskCompleted}},
+		bson.M{"$addFields": bson.M{"completed_at": completedAtField}},
+		bson.M{"$match": bson.M{"completed_at": bson.M{"$gte": start, "$lt": end}}},
+		bson.M{"$group": bson.M{
+			"_id":    truncate("$completed_at"),
+			"points": bson.M{"$sum": bson.M{"$ifNull": bson.A{"$story_points", 0}}},
+		}},
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$facet": bson.M{
+			"running":   running,
+			"completed": completed,
+		}},
+	}
+
+	cursor, err := r.coll.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		Running []struct {
+			Start time.Time `bson:"_id"`
+			Count int       `bson:"count"`
+		} `bson:"running"`
+		Completed []struct {
+			Start  time.Time `bson:"_id"`
+			Points int       `bson:"points"`
+		} `bson:"completed"`
+	}
+	if err := cursor.All(context.TODO(), &docs); err != nil {
+		return nil, err
+	}
+
+	counts, points := map[int64]int{}, map[int64]int{}
+	if len(docs) > 0 {
+		for _, doc := range docs[0].Running {
+			counts[doc.Start.Unix()] = doc.Count
+		}
+		for _, doc := range docs[0].Completed {
+			points[doc.Start.Unix()] = doc.Points
+		}
+	}
+
+	overview := make([]TaskOverview, 0, periods)
+	for i := 0; i < periods; i++ {
+		overview = append(overview, TaskOverview{
+			Label:  periodLabel(granularity, starts[i]),
+			Start:  starts[i].Format("02 Jan"),
+			End:    starts[i+1].Add(-time.Second).Format("02 Jan"),
+			Count:  counts[starts[i].Unix()],
+			Points: points[starts[i].Unix()],
+		})
+	}
+	return overview, nil
+}
+
+// periodLabel names the period starting at the given time
+func periodLabel(granularity string, start time.Time) string {
+	switch granularity {
+	case _const.GranularityDay:
+		return start.Format("Mon 02 Jan")
+	case _const.GranularityMonth:
+		return start.Format("Jan 2006")
+	case _const.GranularityQuarter:
+		return fmt.Sprintf("Q%d %d", (int(start.Month())-1)/3+1, start.Year())
+	case _const.GranularityYear:
+		return start.Format("2006")
+	}
+	return start.Format("02 Jan 2006")
+}
+
 func (r *TaskCollRepository) CountUserTask(userRepo *UserCollRepository) (*CountUserActive, error) {
 	result := &CountUserActive{}
 
@@ -252,6 +905,25 @@ func (r *TaskCollRepository) UpdateOneByID(task *Task) error {
 	return nil
 }
 
+// UpdateOneWithRanks updates the task and stores new board ranks for its siblings in a single bulk write
+func (r *TaskCollRepository) UpdateOneWithRanks(task *Task, ranks map[bson.ObjectID]float64) error {
+	models := make([]mongo.WriteModel, 0)
+	for id, rank := range ranks {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": id}).
+			SetUpdate(bson.M{"$set": bson.M{"rank": rank}}))
+	}
+	models = append(models, mongo.NewUpdateOneModel().
+		SetFilter(bson.M{"_id": task.ID, "is_deleted": bson.M{"$ne": true}}).
+		SetUpdate(bson.M{"$set": task}))
+
+	_, err := r.coll.BulkWrite(context.TODO(), models)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *TaskCollRepository) DeleteOneByID(_id bson.ObjectID) error {
 	filter := bson.M{
 		"_id": _id,
@@ -269,6 +941,107 @@ func (r *TaskCollRepository) DeleteOneByID(_id bson.ObjectID) error {
 	return nil
 }
 
+// PullBlockedBy removes the task from the dependencies of every other task
+func (r *TaskCollRepository) PullBlockedBy(taskID bson.ObjectID) error {
+	filter := bson.M{
+		"blocked_by": taskID,
+	}
+	update := bson.M{
+		"$pull": bson.M{
+			"blocked_by": taskID,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetSprint moves the tasks into the sprint, a nil sprint moves them to the backlog
+func (r *TaskCollRepository) SetSprint(taskIDs []bson.ObjectID, sprintID bson.ObjectID) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	filter := bson.M{
+		"_id":        bson.M{"$in": taskIDs},
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"sprint_id": sprintID,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// MoveSprint moves the tasks still in the from sprint into the to sprint, a nil sprint is the backlog.
+// Tasks moved elsewhere in the meantime are left alone, so the move can be repeated.
+func (r *TaskCollRepository) MoveSprint(taskIDs []bson.ObjectID, from, to bson.ObjectID) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	filter := bson.M{
+		"_id":        bson.M{"$in": taskIDs},
+		"sprint_id":  from,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"sprint_id": to,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReleaseSprint moves every task of the sprint back to the backlog
+func (r *TaskCollRepository) ReleaseSprint(sprintID bson.ObjectID) error {
+	filter := bson.M{
+		"sprint_id": sprintID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"sprint_id": bson.NilObjectID,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TaskCollRepository) DeleteAllByParentID(parentID bson.ObjectID) error {
+	filter := bson.M{
+		"parent_id": parentID,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateMany(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *TaskCollRepository) DeleteAllByProjectID(projectID bson.ObjectID) error {
 	filter := bson.M{
 		"project_id": projectID,
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"math"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+type TimeEntry struct {
+	ID        bson.ObjectID `json:"_id" bson:"_id"`
+	UserID    bson.ObjectID `json:"user_id" bson:"user_id"`
+	TaskID    bson.ObjectID `json:"task_id" bson:"task_id"`
+	ProjectID bson.ObjectID `json:"project_id" bson:"project_id"`
+	StartDate time.Time     `json:"start_date" bson:"start_date"`
+	EndDate   time.Time     `json:"end_date" bson:"end_date"` // zero while the timer is running
+	Duration  int64         `json:"duration" bson:"duration"` // seconds, set when the entry is stopped
+	Note      string        `json:"note" bson:"note"`
+	IsRunning bool          `json:"is_running" bson:"is_running"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
+	IsDeleted bool          `json:"-" bson:"is_deleted"`
+}
+
+// Hours returns the logged time in hours, a running entry counts up to now
+func (u *TimeEntry) Hours() float64 {
+	if u.IsRunning {
+		return time.Since(u.StartDate).Hours()
+	}
+	return float64(u.Duration) / 3600
+}
+
+type ProjectEffort struct {
+	EstimatedHours float64 `json:"estimated_hours"` // estimates of tasks that are not cancelled
+	LoggedHours    float64 `json:"logged_hours"`
+	VarianceHours  float64 `json:"variance_hours"` // logged minus estimated, positive when over the estimate
+	OverEstimate   int     `json:"over_estimate"`  // tasks with more logged than estimated hours
+}
+
+// CalculateEffort compares the estimated hours of the tasks with the seconds logged per task
+func CalculateEffort(tasks []Task, logged map[bson.ObjectID]int64) ProjectEffort {
+	effort := ProjectEffort{}
+	for _, task := range tasks {
+		hours := float64(logged[task.ID]) / 3600
+		effort.LoggedHours += hours
+		if task.Status == _const.TaskCancelled {
+			continue
+		}
+		effort.EstimatedHours += task.EstimatedHours
+		if task.EstimatedHours > 0 && hours > task.EstimatedHours {
+			effort.OverEstimate++
+		}
+	}
+
+	effort.EstimatedHours = math.Round(effort.EstimatedHours*100) / 100
+	effort.LoggedHours = math.Round(effort.LoggedHours*100) / 100
+	effort.VarianceHours = math.Round((effort.LoggedHours-effort.EstimatedHours)*100) / 100
+	return effort
+}
+
+type TimeEntryCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewTimeEntryCollRepository(db *mongo.Database) *TimeEntryCollRepository {
+	return &TimeEntryCollRepository{
+		coll: db.Collection("time_entries"),
+	}
+}
+
+// FindAll returns the stopped entries started within the query window, oldest first
+func (r *TimeEntryCollRepository) FindAll(cq *util.CommonQuery) ([]TimeEntry, error) {
+	entries := []TimeEntry{}
+	filter := bson.M{
+		"start_date": bson.M{"$gte": cq.Start, "$lt": cq.End},
+		"is_running": bson.M{"$ne": true},
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	if cq.UserId != bson.NilObjectID {
+		filter["user_id"] = cq.UserId
+	}
+
+	if cq.ProjectId != bson.NilObjectID {
+		filter["project_id"] = cq.ProjectId
+	} else if cq.ProjectIds != nil {
+		filter["project_id"] = bson.M{"$in": cq.ProjectIds}
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindAllByTaskID returns the entries of the task, newest first
+func (r *TimeEntryCollRepository) FindAllByTaskID(taskID bson.ObjectID) ([]TimeEntry, error) {
+	entries := []TimeEntry{}
+	filter := bson.M{
+		"task_id":    taskID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "start_date", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *TimeEntryCollRepository) FindOneByID(_id bson.ObjectID) (*TimeEntry, error) {
+	entry := TimeEntry{}
+	filter := bson.M{
+		"_id":        _id,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// FindRunningByUserID returns the running timer of the user
+func (r *TimeEntryCollRepository) FindRunningByUserID(userID bson.ObjectID) (*TimeEntry, error) {
+	entry := TimeEntry{}
+	filter := bson.M{
+		"user_id":    userID,
+		"is_running": true,
+		"is_deleted": bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&entry)
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// CreateIndexes allows a single running timer per user, soft deleted entries are never running
+func (r *TimeEntryCollRepository) CreateIndexes() error {
+	_, err := r.coll.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"is_running": true}),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TimeEntryCollRepository) CreateOne(entry *TimeEntry) error {
+	_, err := r.coll.InsertOne(context.TODO(), entry)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// StartTimer inserts the running entry unless the user already has a running timer, the unique index on
+// running entries keeps two concurrent starts from both succeeding. It reports whether the timer was started.
+func (r *TimeEntryCollRepository) StartTimer(entry *TimeEntry) (bool, error) {
+	filter := bson.M{
+		"user_id":    entry.UserID,
+		"is_running": true,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":        entry.ID,
+			"task_id":    entry.TaskID,
+			"project_id": entry.ProjectID,
+			"start_date": entry.StartDate,
+			"end_date":   entry.EndDate,
+			"duration":   entry.Duration,
+			"note":       entry.Note,
+			"created_at": entry.CreatedAt,
+			"is_deleted": false,
+		},
+	}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount == 1, nil
+}
+
+// StopTimer stops the running entry, it reports false when the entry was stopped in the meantime
+func (r *TimeEntryCollRepository) StopTimer(entry *TimeEntry) (bool, error) {
+	filter := bson.M{
+		"_id":        entry.ID,
+		"is_running": true,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"end_date":   entry.EndDate,
+			"duration":   entry.Duration,
+			"note":       entry.Note,
+			"is_running": false,
+		},
+	}
+
+	result, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *TimeEntryCollRepository) UpdateOneByID(entry *TimeEntry) error {
+	filter := bson.M{
+		"_id":        entry.ID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": entry}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteOneByID soft deletes the entry, a discarded timer stops running so the user can start a new one
+func (r *TimeEntryCollRepository) DeleteOneByID(_id bson.ObjectID) error {
+	filter := bson.M{
+		"_id": _id,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"is_running": false,
+			"is_deleted": true,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SumDurationByTask returns the logged seconds of every task of the project that has stopped entries
+func (r *TimeEntryCollRepository) SumDurationByTask(projectID bson.ObjectID) (map[bson.ObjectID]int64, error) {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{
+			"project_id": projectID,
+			"is_running": bson.M{"$ne": true},
+			"is_deleted": bson.M{"$ne": true},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":      "$task_id",
+			"duration": bson.M{"$sum": "$duration"},
+		}},
+	}
+
+	cursor, err := r.coll.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		TaskID   bson.ObjectID `bson:"_id"`
+		Duration int64         `bson:"duration"`
+	}
+	if err := cursor.All(context.TODO(), &docs); err != nil {
+		return nil, err
+	}
+
+	result := make(map[bson.ObjectID]int64, len(docs))
+	for _, doc := range docs {
+		result[doc.TaskID] = doc.Duration
+	}
+	return result, nil
+}
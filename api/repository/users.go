@@ -5,22 +5,51 @@ import (
 	"encoding/json"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"proman-backend/config"
+	"proman-backend/internal/pkg/const"
 	"proman-backend/internal/pkg/util"
+	"slices"
 	"strings"
 	"time"
 )
 
 type User struct {
-	ID        bson.ObjectID `json:"_id" bson:"_id"`
-	Email     string        `json:"email" bson:"email"`
-	Password  string        `json:"-" bson:"password"`
-	Name      string        `json:"name" bson:"name"`
-	Position  string        `json:"position" bson:"position"`
-	Avatar    string        `json:"avatar" bson:"avatar"`
-	Phone     string        `json:"phone" bson:"phone"`
-	CreatedAt time.Time     `json:"created_at" bson:"created_at"`
-	IsDeleted bool          `json:"-" bson:"is_deleted"`
+	ID            bson.ObjectID `json:"_id" bson:"_id"`
+	Email         string        `json:"email" bson:"email"`
+	Password      string        `json:"-" bson:"password"`
+	Name          string        `json:"name" bson:"name"`
+	Position      string        `json:"position" bson:"position"`
+	Role          string        `json:"role" bson:"role"` // admin, maintainer, developer
+	Avatar        string        `json:"avatar" bson:"avatar"`
+	Phone         string        `json:"phone" bson:"phone"`
+	CreatedAt     time.Time     `json:"created_at" bson:"created_at"`
+	IsDeactivated bool          `json:"is_deactivated" bson:"is_deactivated"`
+	FeedToken     string        `json:"-" bson:"feed_token"` // SHA-256 of the calendar feed token
+	IsDeleted     bool          `json:"-" bson:"is_deleted"`
+
+	// notification types the user turned off, every type notifies by default
+	MutedNotifications []string `json:"-" bson:"muted_notifications"`
+	// digest types the user turned off, every digest is mailed by default
+	MutedDigests []string `json:"-" bson:"muted_digests"`
+}
+
+// WantsNotification reports whether the user has not turned the notification type off
+func (u *User) WantsNotification(notificationType string) bool {
+	return !slices.Contains(u.MutedNotifications, notificationType)
+}
+
+// WantsDigest reports whether the user has not turned the digest type off
+func (u *User) WantsDigest(digestType string) bool {
+	return !slices.Contains(u.MutedDigests, digestType)
+}
+
+// GetRole returns the stored role, falling back to developer for users created before roles existed
+func (u *User) GetRole() string {
+	if !_const.IsValidRole(u.Role) {
+		return _const.RoleDeveloper
+	}
+	return u.Role
 }
 
 func (u *User) MarshalJSON() ([]byte, error) {
@@ -65,6 +94,10 @@ func (r *UserCollRepository) FindAllUsers(cq *util.CommonQuery) ([]map[string]in
 		}})
 	}
 
+	if len(cq.Role) > 0 && _const.IsValidRole(cq.Role) {
+		matchStage = append(matchStage, bson.E{Key: "role", Value: cq.Role})
+	}
+
 	skip := (cq.Page - 1) * cq.Limit
 	limit := cq.Limit
 
@@ -120,7 +153,7 @@ func (r *UserCollRepository) FindAllUsers(cq *util.CommonQuery) ([]map[string]in
 				}},
 			}},
 		}}},
-		{{"$unset", bson.A{"projects", "tasks"}}},
+		{{"$unset", bson.A{"projects", "tasks", "password", "feed_token"}}},
 		{{"$sort", bson.D{{"created_at", cq.Sort}}}},
 		{{"$skip", skip}},
 		{{"$limit", limit}},
@@ -173,6 +206,21 @@ func (r *UserCollRepository) FindOneByEmail(email string) (*User, error) {
 	return &user, nil
 }
 
+func (r *UserCollRepository) FindOneByFeedToken(hashedToken string) (*User, error) {
+	user := User{}
+	filter := bson.M{
+		"feed_token":     hashedToken,
+		"is_deactivated": bson.M{"$ne": true},
+		"is_deleted":     bson.M{"$ne": true},
+	}
+
+	err := r.coll.FindOne(context.TODO(), filter).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (r *UserCollRepository) Insert(userData *User) (*User, error) {
 	data := User{}
 	dataInsert, err := r.coll.InsertOne(context.TODO(), userData)
@@ -200,6 +248,48 @@ func (r *UserCollRepository) Update(userData *User) (*User, error) {
 	return userData, nil
 }
 
+// ClaimFirstAdmin makes the inserted user the admin when it is the oldest active user, and reports whether it did.
+// The grant is an upsert of a marker document, so of concurrent first registrations only one wins, and it is
+// given back when the role cannot be saved.
+func (r *UserCollRepository) ClaimFirstAdmin(user *User) (bool, error) {
+	first := User{}
+	filter := bson.M{"is_deleted": bson.M{"$ne": true}}
+	findOptions := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	err := r.coll.FindOne(context.TODO(), filter, findOptions).Decode(&first)
+	if err != nil {
+		return false, err
+	}
+	if first.ID != user.ID {
+		return false, nil
+	}
+
+	bootstrap := r.coll.Database().Collection("bootstrap")
+	marker := bson.M{"_id": "first_admin"}
+	update := bson.M{"$setOnInsert": bson.M{"user_id": user.ID, "claimed_at": time.Now()}}
+
+	result, err := bootstrap.UpdateOne(context.TODO(), marker, update, options.UpdateOne().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if result.UpsertedCount != 1 {
+		return false, nil
+	}
+
+	_, err = r.coll.UpdateOne(context.TODO(), bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"role": _const.RoleAdmin}})
+	if err != nil {
+		if _, rollbackErr := bootstrap.DeleteOne(context.TODO(), bson.M{"_id": "first_admin", "user_id": user.ID}); rollbackErr != nil {
+			return false, rollbackErr
+		}
+		return false, err
+	}
+	user.Role = _const.RoleAdmin
+	return true, nil
+}
+
 func (r *UserCollRepository) Check() bool {
 	filter := bson.M{"is_deleted": bson.M{"$ne": true}}
 	count, err := r.coll.CountDocuments(context.TODO(), filter)
@@ -211,3 +301,48 @@ func (r *UserCollRepository) Check() bool {
 	}
 	return false
 }
+
+func (r *UserCollRepository) CountActiveByRole(role string) (int64, error) {
+	filter := bson.M{
+		"role":           role,
+		"is_deactivated": bson.M{"$ne": true},
+		"is_deleted":     bson.M{"$ne": true},
+	}
+	return r.coll.CountDocuments(context.TODO(), filter)
+}
+
+func (r *UserCollRepository) UpdateRoleByEmail(email, role string) error {
+	filter := bson.M{
+		"email":      email,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	update := bson.M{"$set": bson.M{"role": role}}
+
+	_, err := r.coll.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindAllActive returns the users that are not deactivated by name, of one position when a position is given
+func (r *UserCollRepository) FindAllActive(position string) ([]User, error) {
+	users := []User{}
+	filter := bson.M{
+		"is_deactivated": bson.M{"$ne": true},
+		"is_deleted":     bson.M{"$ne": true},
+	}
+
+	if len(position) > 0 {
+		filter["position"] = position
+	}
+
+	cursor, err := r.coll.Find(context.TODO(), filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(context.TODO(), &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
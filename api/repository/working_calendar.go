@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/util"
+	"time"
+)
+
+const (
+	defaultHoursPerDay = 8
+	dayKeyFormat       = "2006-01-02"
+)
+
+// defaultWorkingDays are Monday to Friday, used until the calendar is saved
+var defaultWorkingDays = []int{1, 2, 3, 4, 5}
+
+// WorkingCalendar is the organization calendar, there is at most one document.
+// Holidays and leaves are only set on a calendar returned by Load, for the loaded window.
+type WorkingCalendar struct {
+	ID          bson.ObjectID `json:"_id" bson:"_id,omitempty"`
+	WorkingDays []int         `json:"working_days" bson:"working_days"` // weekdays, 0 is Sunday
+	HoursPerDay float64       `json:"hours_per_day" bson:"hours_per_day"`
+	UpdatedAt   time.Time     `json:"updated_at" bson:"updated_at"`
+
+	holidays map[string]string                 // holiday names by day
+	leaves   map[bson.ObjectID]map[string]bool // leave days by user
+}
+
+func dayKey(t time.Time) string {
+	return t.In(time.Local).Format(dayKeyFormat)
+}
+
+// Holiday returns the name of the holiday on the day
+func (u *WorkingCalendar) Holiday(day time.Time) (string, bool) {
+	name, ok := u.holidays[dayKey(day)]
+	return name, ok
+}
+
+// IsWorkingDay reports whether the day is a working weekday and not a holiday,
+// nor a leave day of the user when a user is given
+func (u *WorkingCalendar) IsWorkingDay(day time.Time, userID bson.ObjectID) bool {
+	weekday := int(day.In(time.Local).Weekday())
+	working := false
+	for _, d := range u.WorkingDays {
+		if d == weekday {
+			working = true
+			break
+		}
+	}
+	if !working {
+		return false
+	}
+
+	key := dayKey(day)
+	if _, ok := u.holidays[key]; ok {
+		return false
+	}
+	return userID.IsZero() || !u.leaves[userID][key]
+}
+
+// CountWorkingDays counts the working days from the day of start to the day of end, both included.
+// Whole weeks are counted at once and the loaded holidays and leaves taken off, so the cost does not
+// grow with the length of the window.
+func (u *WorkingCalendar) CountWorkingDays(start, end time.Time, userID bson.ObjectID) int {
+	start, end = start.In(time.Local), end.In(time.Local)
+	// calendar days in UTC, so a daylight saving change does not shorten a day
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if last.Before(first) {
+		return 0
+	}
+
+	working := map[time.Weekday]bool{}
+	for _, d := range u.WorkingDays {
+		working[time.Weekday(d)] = true
+	}
+
+	days := int(last.Sub(first).Hours()/24) + 1
+	count := days / 7 * len(working)
+	for day := first.AddDate(0, 0, days/7*7); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if working[day.Weekday()] {
+			count++
+		}
+	}
+
+	off := map[string]bool{}
+	for key := range u.holidays {
+		off[key] = true
+	}
+	if !userID.IsZero() {
+		for key := range u.leaves[userID] {
+			off[key] = true
+		}
+	}
+	for key := range off {
+		day, err := time.Parse(dayKeyFormat, key)
+		if err == nil && !day.Before(first) && !day.After(last) && working[day.Weekday()] {
+			count--
+		}
+	}
+	return count
+}
+
+// WorkingDaysLeft counts the organization working days from today to the due date, zero once it has passed
+func (u *WorkingCalendar) WorkingDaysLeft(due time.Time) int {
+	if due.IsZero() {
+		return 0
+	}
+	return u.CountWorkingDays(util.StartOfDay(0), due, bson.NilObjectID)
+}
+
+type WorkingCalendarCollRepository struct {
+	coll *mongo.Collection
+}
+
+func NewWorkingCalendarCollRepository(db *mongo.Database) *WorkingCalendarCollRepository {
+	return &WorkingCalendarCollRepository{
+		coll: db.Collection("working_calendar"),
+	}
+}
+
+// Find returns the organization calendar, or Monday to Friday for 8 hours before it is saved
+func (r *WorkingCalendarCollRepository) Find() (*WorkingCalendar, error) {
+	calendar := WorkingCalendar{}
+
+	err := r.coll.FindOne(context.TODO(), bson.M{}).Decode(&calendar)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return &WorkingCalendar{
+			WorkingDays: defaultWorkingDays,
+			HoursPerDay: defaultHoursPerDay,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &calendar, nil
+}
+
+// Save creates or replaces the organization calendar
+func (r *WorkingCalendarCollRepository) Save(calendar *WorkingCalendar) error {
+	update := bson.M{
+		"$set": bson.M{
+			"working_days":  calendar.WorkingDays,
+			"hours_per_day": calendar.HoursPerDay,
+			"updated_at":    calendar.UpdatedAt,
+		},
+	}
+
+	_, err := r.coll.UpdateOne(context.TODO(), bson.M{}, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Load returns the organization calendar with the holidays and the leaves of every user from start to end,
+// leaves are left out without a leave repository
+func (r *WorkingCalendarCollRepository) Load(start, end time.Time, holidayRepo *HolidayCollRepository, leaveRepo *LeaveCollRepository) (*WorkingCalendar, error) {
+	calendar, err := r.Find()
+	if err != nil {
+		return nil, err
+	}
+
+	cq := util.NilCommonQuery()
+	cq.Start, cq.End = start.AddDate(0, 0, -1), end.AddDate(0, 0, 1)
+
+	holidays, err := holidayRepo.FindAll(cq)
+	if err != nil {
+		return nil, err
+	}
+	calendar.holidays = map[string]string{}
+	for _, holiday := range holidays {
+		calendar.holidays[dayKey(holiday.Date)] = holiday.Name
+	}
+
+	calendar.leaves = map[bson.ObjectID]map[string]bool{}
+	if leaveRepo == nil {
+		return calendar, nil
+	}
+
+	leaves, err := leaveRepo.FindAll(cq)
+	if err != nil {
+		return nil, err
+	}
+	for _, leave := range leaves {
+		if calendar.leaves[leave.UserID] == nil {
+			calendar.leaves[leave.UserID] = map[string]bool{}
+		}
+		for day := leave.StartDate; !day.After(leave.EndDate); day = day.AddDate(0, 0, 1) {
+			calendar.leaves[leave.UserID][dayKey(day)] = true
+		}
+	}
+	return calendar, nil
+}
+
+// FillTaskWorkingDaysLeft sets the working days left until the end date of the active and testing tasks
+func (r *WorkingCalendarCollRepository) FillTaskWorkingDaysLeft(tasks []Task, holidayRepo *HolidayCollRepository) error {
+	dues := make([]time.Time, len(tasks))
+	for i := range tasks {
+		if tasks[i].Status == _const.TaskActive || tasks[i].Status == _const.TaskTesting {
+			dues[i] = tasks[i].EndDate
+		}
+	}
+
+	left, err := r.workingDaysLeft(dues, holidayRepo)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].WorkingDaysLeft = left[i]
+	}
+	return nil
+}
+
+// FillProjectWorkingDaysLeft sets the working days left until the end date of the projects that are not
+// completed or cancelled
+func (r *WorkingCalendarCollRepository) FillProjectWorkingDaysLeft(projects []Project, holidayRepo *HolidayCollRepository) error {
+	dues := make([]time.Time, len(projects))
+	for i := range projects {
+		if projects[i].Status != _const.ProjectCompleted && projects[i].Status != _const.ProjectCancelled {
+			dues[i] = projects[i].EndDate
+		}
+	}
+
+	left, err := r.workingDaysLeft(dues, holidayRepo)
+	if err != nil {
+		return err
+	}
+	for i := range projects {
+		projects[i].WorkingDaysLeft = left[i]
+	}
+	return nil
+}
+
+// workingDaysLeft counts the working days left for every due date, nil for zero dates
+func (r *WorkingCalendarCollRepository) workingDaysLeft(dues []time.Time, holidayRepo *HolidayCollRepository) ([]*int, error) {
+	left := make([]*int, len(dues))
+
+	today := util.StartOfDay(0)
+	last := today
+	for _, due := range dues {
+		if due.After(last) {
+			last = due
+		}
+	}
+
+	var calendar *WorkingCalendar
+	for i, due := range dues {
+		if due.IsZero() {
+			continue
+		}
+		if calendar == nil {
+			var err error
+			if calendar, err = r.Load(today, last, holidayRepo, nil); err != nil {
+				return nil, err
+			}
+		}
+		days := calendar.WorkingDaysLeft(due)
+		left[i] = &days
+	}
+	return left, nil
+}
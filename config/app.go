@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 var App struct {
@@ -10,6 +11,7 @@ var App struct {
 	Port         string `mapstructure:"APP_PORT"`
 	SwaggerHost  string `mapstructure:"SWAGGER_HOST"`
 	AllowOrigins string `mapstructure:"CORS_ALLOW_ORIGINS"`
+	AdminEmail   string `mapstructure:"APP_ADMIN_EMAIL"`
 }
 
 var Basic struct {
@@ -41,6 +43,7 @@ func initApp() {
 	App.Port = os.Getenv("APP_PORT")
 	App.SwaggerHost = os.Getenv("SWAGGER_HOST")
 	App.AllowOrigins = os.Getenv("CORS_ALLOW_ORIGINS")
+	App.AdminEmail = strings.ToLower(strings.TrimSpace(os.Getenv("APP_ADMIN_EMAIL")))
 
 	if App.Host == "" {
 		panic("APP_HOST is not set")
@@ -105,10 +108,11 @@ func initApp() {
 	if Mail.SenderName == "" {
 		panic("MAIL_SENDER_NAME is not set")
 	}
-	if Mail.AuthMail == "" {
+	// Both are left empty for servers without authentication, like MailHog when testing locally.
+	if Mail.AuthMail == "" && Mail.AuthPass != "" {
 		panic("MAIL_AUTH_EMAIL is not set")
 	}
-	if Mail.AuthPass == "" {
+	if Mail.AuthPass == "" && Mail.AuthMail != "" {
 		panic("MAIL_AUTH_PASSWORD is not set")
 	}
 
@@ -15,8 +15,13 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/forgot-password": {
-            "post": {
+        "/api/admin/activity": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -24,19 +29,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "ForgotPassword",
-                "operationId": "forgot-password",
+                "summary": "Get the activity of every project, task, schedule and user",
+                "operationId": "admin-activity",
                 "parameters": [
                     {
-                        "description": "forgot password json",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.forgotPasswordForm"
-                        }
+                        "enum": [
+                            "project",
+                            "task",
+                            "schedule",
+                            "milestone",
+                            "sprint",
+                            "user"
+                        ],
+                        "type": "string",
+                        "description": "Entity type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -46,8 +92,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/login": {
+        "/api/admin/digest/{type}/send": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -55,19 +106,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Login",
-                "operationId": "login",
+                "summary": "Queue the daily or weekly digest of a user to be mailed now, regardless of their preferences and of earlier digests",
+                "operationId": "admin-send-digest",
                 "parameters": [
                     {
-                        "description": "login json",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.loginForm"
-                        }
+                        "enum": [
+                            "daily",
+                            "weekly"
+                        ],
+                        "type": "string",
+                        "description": "Digest type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -77,8 +137,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me": {
-            "get": {
+        "/api/admin/holiday/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -91,17 +151,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me"
+                    "Admin"
+                ],
+                "summary": "Delete a holiday",
+                "operationId": "admin-holiday-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Holiday ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my info",
-                "operationId": "my-profile",
                 "responses": {
                     "200": {
                         "description": "OK"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/admin/holidays": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -114,47 +185,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me"
+                    "Admin"
                 ],
-                "summary": "Update my profile",
-                "operationId": "update-my-profile",
+                "summary": "Add a holiday, replacing the name of a holiday already on that day",
+                "operationId": "admin-holiday-create",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "name",
-                        "name": "name",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "email",
-                        "name": "email",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "position",
-                        "name": "position",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "phone",
-                        "name": "phone",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "file",
-                        "description": "avatar",
-                        "name": "avatar",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "verification_code",
-                        "name": "verification_code",
-                        "in": "formData",
-                        "required": true
+                        "description": "Holiday",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workday.holidayForm"
+                        }
                     }
                 ],
                 "responses": {
@@ -164,33 +207,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/password": {
-            "put": {
+        "/api/admin/holidays/import": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Every day of an all-day event becomes a holiday, timed events mark the day they start.\nCancelled events are skipped and holidays already on a day are renamed.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Me"
+                    "Admin"
                 ],
-                "summary": "Update my password",
-                "operationId": "update-my-password",
+                "summary": "Import the holidays of an iCalendar file",
+                "operationId": "admin-holiday-import",
                 "parameters": [
                     {
-                        "description": "update my password json",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/me.updateMyPasswordForm"
-                        }
+                        "type": "file",
+                        "description": "iCalendar (.ics) file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -200,7 +242,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/project/count": {
+        "/api/admin/job/{id}": {
             "get": {
                 "security": [
                     {
@@ -214,10 +256,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Project"
+                    "Admin"
+                ],
+                "summary": "Get a background job",
+                "operationId": "admin-job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my project count",
-                "operationId": "my-project-count",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -225,8 +276,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/project/count/type": {
-            "get": {
+        "/api/admin/job/{id}/retry": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -239,10 +290,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Project"
+                    "Admin"
+                ],
+                "summary": "Run a failed background job again from its first attempt",
+                "operationId": "admin-retry-job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my project count by type",
-                "operationId": "my-project-count-type",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -250,7 +310,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/projects": {
+        "/api/admin/jobs": {
             "get": {
                 "security": [
                     {
@@ -264,49 +324,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Project"
+                    "Admin"
                 ],
-                "summary": "Get my projects",
-                "operationId": "my-projects",
+                "summary": "Get the background jobs, newest first",
+                "operationId": "admin-jobs",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search by nama or description",
-                        "name": "q",
-                        "in": "query"
-                    },
                     {
                         "enum": [
-                            "active",
-                            "completed",
                             "pending",
-                            "cancelled"
+                            "running",
+                            "done",
+                            "failed"
                         ],
                         "type": "string",
-                        "description": "Search by status",
+                        "description": "Job status",
                         "name": "status",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Start date",
-                        "name": "start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date",
-                        "name": "end",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "asc",
-                            "desc"
-                        ],
-                        "type": "string",
-                        "description": "Sort",
-                        "name": "sort",
+                        "description": "Job type, like mail, due-soon, digest or cleanup",
+                        "name": "type",
                         "in": "query"
                     },
                     {
@@ -329,7 +367,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/schedules": {
+        "/api/admin/jobs/status": {
             "get": {
                 "security": [
                     {
@@ -343,29 +381,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me"
+                    "Admin"
                 ],
-                "summary": "Get my schedule",
-                "operationId": "my-schedule",
+                "summary": "Get how many background jobs are pending, running, done and failed",
+                "operationId": "admin-jobs-status",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/admin/leaves": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Without dates the leaves of the current year are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Get the leaves of every user",
+                "operationId": "admin-leaves",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search by name",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "all",
-                            "meeting",
-                            "discussion",
-                            "review",
-                            "presentation",
-                            "etc"
-                        ],
-                        "type": "string",
-                        "description": "Search by type",
-                        "name": "type",
+                        "description": "Search by user",
+                        "name": "userId",
                         "in": "query"
                     },
                     {
@@ -388,8 +438,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/task/count": {
-            "get": {
+        "/api/admin/user": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -402,10 +452,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Task"
+                    "Admin"
+                ],
+                "summary": "Create user with role",
+                "operationId": "admin-user-create",
+                "parameters": [
+                    {
+                        "description": "create user json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.createUserForm"
+                        }
+                    }
                 ],
-                "summary": "Get my task count",
-                "operationId": "my-task-count",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -413,8 +474,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/task/overview": {
-            "get": {
+        "/api/admin/user/{id}/deactivate": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -427,10 +488,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Task"
+                    "Admin"
+                ],
+                "summary": "Deactivate user",
+                "operationId": "admin-user-deactivate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my task overview",
-                "operationId": "my-task-overview",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -438,8 +508,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/task/status": {
-            "get": {
+        "/api/admin/user/{id}/reactivate": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -452,10 +522,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Task"
+                    "Admin"
+                ],
+                "summary": "Reactivate user",
+                "operationId": "admin-user-reactivate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my task list by status",
-                "operationId": "my-task-list-status",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -463,7 +542,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/me/tasks": {
+        "/api/admin/user/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Change user role",
+                "operationId": "admin-user-role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update role json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.updateRoleForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/admin/users": {
             "get": {
                 "security": [
                     {
@@ -477,45 +599,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Me Task"
+                    "Admin"
                 ],
-                "summary": "Get my tasks",
-                "operationId": "my-tasks",
+                "summary": "Get list users with role and status",
+                "operationId": "admin-user-list",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search by nama or description",
+                        "description": "Search by name or email",
                         "name": "q",
                         "in": "query"
                     },
                     {
                         "enum": [
-                            "active",
-                            "testing",
-                            "completed",
-                            "cancelled"
+                            "admin",
+                            "maintainer",
+                            "developer"
                         ],
                         "type": "string",
-                        "description": "Search by status",
-                        "name": "status",
+                        "description": "Search by role",
+                        "name": "role",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
                         "type": "string",
-                        "description": "Search by project",
-                        "name": "projectId",
+                        "description": "Sort",
+                        "name": "sort",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Start date",
-                        "name": "start",
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "End date",
-                        "name": "end",
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -526,11 +651,11 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/option/project": {
-            "get": {
+        "/api/admin/working-calendar": {
+            "put": {
                 "security": [
                     {
-                        "BasicAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
                 "consumes": [
@@ -540,10 +665,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Option"
+                    "Admin"
+                ],
+                "summary": "Update the working weekdays and hours of the organization",
+                "operationId": "admin-working-calendar-update",
+                "parameters": [
+                    {
+                        "description": "Working calendar",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workday.calendarForm"
+                        }
+                    }
                 ],
-                "summary": "Get project",
-                "operationId": "option-project",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -551,13 +687,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/option/type/position": {
-            "get": {
+        "/api/comment/{id}": {
+            "put": {
                 "security": [
                     {
-                        "BasicAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "The previous body is kept in the comment history.",
                 "consumes": [
                     "application/json"
                 ],
@@ -565,24 +702,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Option"
+                    "Comment"
+                ],
+                "summary": "Edit my comment",
+                "operationId": "update-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Markdown body",
+                        "name": "body",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Add comment attachments",
+                        "name": "attachments",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated attachment keys or urls to remove",
+                        "name": "remove_attachments",
+                        "in": "formData"
+                    }
                 ],
-                "summary": "Get position",
-                "operationId": "option-position",
                 "responses": {
                     "200": {
                         "description": "OK"
                     }
                 }
-            }
-        },
-        "/api/option/type/project": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
-                        "BasicAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "Authors can delete their own comments, project managers can delete any comment of the project.",
                 "consumes": [
                     "application/json"
                 ],
@@ -590,10 +753,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Option"
+                    "Comment"
+                ],
+                "summary": "Delete a comment",
+                "operationId": "delete-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get project type",
-                "operationId": "option-project-type",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -601,13 +773,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/option/type/schedule": {
+        "/api/events": {
             "get": {
                 "security": [
                     {
-                        "BasicAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "Every event is named after its entity type, or notification, and carries a JSON object with its action, ids and changes.\nA client that falls behind is disconnected and should reload what it shows once it reconnects.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "Stream task, project, schedule and other changes of the given projects and my notifications as Server-Sent Events",
+                "operationId": "event-stream",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token, for clients that cannot send the Authorization header",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated project IDs to receive the changes of",
+                        "name": "projectId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/forgot-password": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -615,10 +819,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Option"
+                    "Auth"
+                ],
+                "summary": "ForgotPassword",
+                "operationId": "forgot-password",
+                "parameters": [
+                    {
+                        "description": "forgot password json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.forgotPasswordForm"
+                        }
+                    }
                 ],
-                "summary": "Get schedule type",
-                "operationId": "option-schedule-type",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -626,13 +841,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/option/user": {
+        "/api/holidays": {
             "get": {
                 "security": [
                     {
-                        "BasicAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
+                "description": "Without dates the holidays of the current year are returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -640,10 +856,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Option"
+                    "Working Calendar"
+                ],
+                "summary": "Get the holidays by date",
+                "operationId": "holidays",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get user",
-                "operationId": "option-user",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -651,8 +881,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/project": {
-            "post": {
+        "/api/leave/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -665,73 +895,135 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Working Calendar"
                 ],
-                "summary": "Create project",
-                "operationId": "create-project",
+                "summary": "Delete a leave, only its user and admins can delete it",
+                "operationId": "leave-delete",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Project name",
-                        "name": "name",
-                        "in": "formData",
+                        "description": "Leave ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login",
+                "operationId": "login",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Project description",
-                        "name": "description",
-                        "in": "formData",
-                        "required": true
-                    },
+                        "description": "login json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.loginForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "get": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Project start date",
-                        "name": "start_date",
-                        "in": "formData",
-                        "required": true
-                    },
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Get my info",
+                "operationId": "my-profile",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Project end date",
-                        "name": "end_date",
-                        "in": "formData",
-                        "required": true
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me"
+                ],
+                "summary": "Update my profile",
+                "operationId": "update-my-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "formData"
                     },
                     {
                         "type": "string",
-                        "description": "Project contributor",
-                        "name": "contributor",
-                        "in": "formData",
-                        "required": true
+                        "description": "email",
+                        "name": "email",
+                        "in": "formData"
                     },
                     {
-                        "enum": [
-                            "frontend",
-                            "backend",
-                            "mobile",
-                            "desktop",
-                            "monitor",
-                            "tool",
-                            "etc"
-                        ],
                         "type": "string",
-                        "description": "Project type",
-                        "name": "type",
-                        "in": "formData",
-                        "required": true
+                        "description": "position",
+                        "name": "position",
+                        "in": "formData"
                     },
                     {
-                        "type": "file",
-                        "description": "Project logo",
-                        "name": "logo",
+                        "type": "string",
+                        "description": "phone",
+                        "name": "phone",
                         "in": "formData"
                     },
                     {
                         "type": "file",
-                        "description": "Project attachments",
-                        "name": "attachments",
+                        "description": "avatar",
+                        "name": "avatar",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "verification_code",
+                        "name": "verification_code",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -741,13 +1033,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/project/count": {
+        "/api/me/calendar.ics": {
             "get": {
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Get my schedules and task deadlines as an iCalendar feed",
+                "operationId": "calendar-me",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Calendar feed token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me/calendar/token": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "The token is only shown once, creating a new one revokes the previous token.",
                 "consumes": [
                     "application/json"
                 ],
@@ -755,19 +1074,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Calendar"
                 ],
-                "summary": "Get project count",
-                "operationId": "count-project",
+                "summary": "Create or rotate my calendar feed token",
+                "operationId": "calendar-token-create",
                 "responses": {
                     "200": {
                         "description": "OK"
                     }
                 }
-            }
-        },
-        "/api/project/count/type": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -780,10 +1097,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Calendar"
                 ],
-                "summary": "Get project count by type",
-                "operationId": "count-project-type",
+                "summary": "Revoke my calendar feed token",
+                "operationId": "calendar-token-revoke",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -791,7 +1108,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/project/{id}": {
+        "/api/me/digest-preferences": {
             "get": {
                 "security": [
                     {
@@ -805,26 +1122,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
-                ],
-                "summary": "Get project by id",
-                "operationId": "get-project",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Digest"
                 ],
+                "summary": "Get which email digests I receive",
+                "operationId": "my-digest-preferences",
                 "responses": {
                     "200": {
                         "description": "OK"
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -837,15 +1145,52 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Digest"
                 ],
-                "summary": "Delete project by id",
-                "operationId": "delete-project",
+                "summary": "Turn email digests on or off, types left out keep their setting",
+                "operationId": "update-my-digest-preferences",
+                "parameters": [
+                    {
+                        "description": "Digest type (daily, weekly) to whether it is mailed",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/digest.preferencesForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me/digest/{type}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Digest"
+                ],
+                "summary": "Render my daily or weekly digest as it would be mailed now",
+                "operationId": "preview-my-digest",
                 "parameters": [
                     {
+                        "enum": [
+                            "daily",
+                            "weekly"
+                        ],
                         "type": "string",
-                        "description": "Project ID",
-                        "name": "id",
+                        "description": "Digest type",
+                        "name": "type",
                         "in": "path",
                         "required": true
                     }
@@ -857,13 +1202,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/projects": {
+        "/api/me/leaves": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Without dates the leaves of the current year are returned.",
                 "consumes": [
                     "application/json"
                 ],
@@ -871,29 +1217,11 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Project"
+                    "Working Calendar"
                 ],
-                "summary": "Get list of project",
-                "operationId": "list-project",
+                "summary": "Get my leaves",
+                "operationId": "my-leaves",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search by nama or description",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "active",
-                            "completed",
-                            "pending",
-                            "cancelled"
-                        ],
-                        "type": "string",
-                        "description": "Search by status",
-                        "name": "status",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Start date",
@@ -905,28 +1233,6 @@ const docTemplate = `{
                         "description": "End date",
                         "name": "end",
                         "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "asc",
-                            "desc"
-                        ],
-                        "type": "string",
-                        "description": "Sort",
-                        "name": "sort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number pagination",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit pagination",
-                        "name": "limit",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -934,10 +1240,13 @@ const docTemplate = `{
                         "description": "OK"
                     }
                 }
-            }
-        },
-        "/api/register": {
+            },
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -945,18 +1254,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Working Calendar"
                 ],
-                "summary": "Register",
-                "operationId": "register",
+                "summary": "Take days off, they are not working days of the logged-in user",
+                "operationId": "leave-create",
                 "parameters": [
                     {
-                        "description": "register json",
+                        "description": "Leave",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.registerForm"
+                            "$ref": "#/definitions/workday.leaveForm"
                         }
                     }
                 ],
@@ -967,8 +1276,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/schedule": {
-            "post": {
+        "/api/me/notification-preferences": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -981,30 +1290,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Schedule"
-                ],
-                "summary": "Create schedule",
-                "operationId": "schedule-create",
-                "parameters": [
-                    {
-                        "description": "Schedule data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schedule.scheduleForm"
-                        }
-                    }
+                    "Notification"
                 ],
+                "summary": "Get which notification types I receive",
+                "operationId": "my-notification-preferences",
                 "responses": {
                     "200": {
                         "description": "OK"
                     }
                 }
-            }
-        },
-        "/api/schedules": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -1017,42 +1313,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Schedule"
+                    "Notification"
                 ],
-                "summary": "Get list of schedule",
-                "operationId": "list-schedule",
+                "summary": "Turn notification types on or off, types left out keep their setting",
+                "operationId": "update-my-notification-preferences",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Search by name",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "all",
-                            "meeting",
-                            "discussion",
-                            "review",
-                            "presentation",
-                            "etc"
-                        ],
-                        "type": "string",
-                        "description": "Search by type",
-                        "name": "type",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Start date",
-                        "name": "start",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "End date",
-                        "name": "end",
-                        "in": "query"
+                        "description": "Notification type to whether it is sent",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notification.preferencesForm"
+                        }
                     }
                 ],
                 "responses": {
@@ -1062,8 +1335,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/task": {
-            "post": {
+        "/api/me/notification/{id}/read": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -1076,19 +1349,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Notification"
                 ],
-                "summary": "Create task",
-                "operationId": "task-create",
+                "summary": "Mark one of my notifications as read",
+                "operationId": "mark-notification-read",
                 "parameters": [
                     {
-                        "description": "Task data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/task.taskForm"
-                        }
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1098,7 +1369,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/task/count": {
+        "/api/me/notifications": {
             "get": {
                 "security": [
                     {
@@ -1112,10 +1383,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Notification"
+                ],
+                "summary": "Get my notifications, newest first",
+                "operationId": "my-notifications",
+                "parameters": [
+                    {
+                        "enum": [
+                            "read",
+                            "unread"
+                        ],
+                        "type": "string",
+                        "description": "Read status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "project_contributor",
+                            "task_contributor",
+                            "schedule_contributor",
+                            "task_status",
+                            "task_due"
+                        ],
+                        "type": "string",
+                        "description": "Notification type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get task count",
-                "operationId": "task-count",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -1123,8 +1431,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/task/overview": {
-            "get": {
+        "/api/me/notifications/read-all": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -1137,10 +1445,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Notification"
                 ],
-                "summary": "Get task overview",
-                "operationId": "task-overview",
+                "summary": "Mark all my notifications as read",
+                "operationId": "mark-all-notifications-read",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -1148,7 +1456,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/task/status": {
+        "/api/me/notifications/unread-count": {
             "get": {
                 "security": [
                     {
@@ -1162,10 +1470,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Notification"
                 ],
-                "summary": "Get task list by status",
-                "operationId": "task-list-status",
+                "summary": "Get the number of my unread notifications",
+                "operationId": "my-notifications-unread-count",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -1173,8 +1481,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/task/{id}": {
-            "get": {
+        "/api/me/password": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -1187,17 +1495,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Me"
                 ],
-                "summary": "Get task by ID",
-                "operationId": "task",
+                "summary": "Update my password",
+                "operationId": "update-my-password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "update my password json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/me.updateMyPasswordForm"
+                        }
                     }
                 ],
                 "responses": {
@@ -1205,8 +1515,10 @@ const docTemplate = `{
                         "description": "OK"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/me/project/count": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -1219,35 +1531,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
-                ],
-                "summary": "Update task",
-                "operationId": "task-update",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update task data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/task.updateTaskForm"
-                        }
-                    }
+                    "Me Project"
                 ],
+                "summary": "Get my project count",
+                "operationId": "my-project-count",
                 "responses": {
                     "200": {
                         "description": "OK"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/me/project/count/type": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -1260,19 +1556,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
-                ],
-                "summary": "Delete task",
-                "operationId": "task-delete",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Me Project"
                 ],
+                "summary": "Get my project count by type",
+                "operationId": "my-project-count-type",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -1280,7 +1567,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/tasks": {
+        "/api/me/projects": {
             "get": {
                 "security": [
                     {
@@ -1294,10 +1581,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Task"
+                    "Me Project"
                 ],
-                "summary": "Get tasks",
-                "operationId": "tasks",
+                "summary": "Get my projects",
+                "operationId": "my-projects",
                 "parameters": [
                     {
                         "type": "string",
@@ -1308,8 +1595,8 @@ const docTemplate = `{
                     {
                         "enum": [
                             "active",
-                            "testing",
                             "completed",
+                            "pending",
                             "cancelled"
                         ],
                         "type": "string",
@@ -1319,28 +1606,44 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Search by contributor",
-                        "name": "userId",
+                        "description": "Start date",
+                        "name": "start",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Search by project",
-                        "name": "projectId",
+                        "description": "End date",
+                        "name": "end",
                         "in": "query"
                     },
                     {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
                         "type": "string",
-                        "description": "Start date",
-                        "name": "start",
+                        "description": "Sort",
+                        "name": "sort",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "End date",
-                        "name": "end",
-                        "in": "query"
-                    }
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
@@ -1349,7 +1652,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/count": {
+        "/api/me/schedule/conflicts": {
             "get": {
                 "security": [
                     {
@@ -1363,10 +1666,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Me"
+                ],
+                "summary": "Get my overlapping schedules",
+                "operationId": "my-schedule-conflicts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get user count",
-                "operationId": "user-count",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -1374,7 +1691,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/user/{id}": {
+        "/api/me/schedules": {
             "get": {
                 "security": [
                     {
@@ -1388,17 +1705,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Me"
                 ],
-                "summary": "Get user",
-                "operationId": "user",
+                "summary": "Get my schedule",
+                "operationId": "my-schedule",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Search by name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "meeting",
+                            "discussion",
+                            "review",
+                            "presentation",
+                            "etc"
+                        ],
+                        "type": "string",
+                        "description": "Search by type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1408,7 +1750,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/users": {
+        "/api/me/task/count": {
             "get": {
                 "security": [
                     {
@@ -1422,37 +1764,85 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Me Task"
                 ],
-                "summary": "Get list users",
-                "operationId": "user-list",
+                "summary": "Get my task count",
+                "operationId": "my-task-count",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Search by nama",
-                        "name": "q",
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me/task/overview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me Task"
+                ],
+                "summary": "Get my task overview",
+                "operationId": "my-task-overview",
+                "parameters": [
                     {
                         "enum": [
-                            "asc",
-                            "desc"
+                            "day",
+                            "week",
+                            "month",
+                            "quarter",
+                            "year"
                         ],
                         "type": "string",
-                        "description": "Sort",
-                        "name": "sort",
+                        "description": "Length of a period, default week",
+                        "name": "granularity",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page number pagination",
-                        "name": "page",
+                        "description": "Number of periods up to the current one, default 8, at most 366",
+                        "name": "periods",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Limit pagination",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
                         "in": "query"
                     }
                 ],
@@ -1463,11 +1853,11 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/verification-code/{email}": {
-            "post": {
+        "/api/me/task/status": {
+            "get": {
                 "security": [
                     {
-                        "BasicAuth": []
+                        "ApiKeyAuth": []
                     }
                 ],
                 "consumes": [
@@ -1477,17 +1867,22 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Code"
+                    "Me Task"
                 ],
-                "summary": "Create verification code",
-                "operationId": "code-create",
+                "summary": "Get my task list by status",
+                "operationId": "my-task-list-status",
                 "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Email User",
-                        "name": "email",
-                        "in": "path",
-                        "required": true
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1496,58 +1891,3911 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "auth.forgotPasswordForm": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                }
-            }
         },
-        "auth.loginForm": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+        "/api/me/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Me Task"
+                ],
+                "summary": "Get my tasks",
+                "operationId": "my-tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by nama or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "testing",
+                            "completed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Search by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
                 }
             }
         },
-        "auth.registerForm": {
-            "type": "object",
-            "properties": {
-                "confirm_password": {
-                    "type": "string"
-                },
-                "email": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
-                }
-            }
+        "/api/me/timer": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Get the running timer of the logged-in user",
+                "operationId": "my-timer",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me/timer/start": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Start a timer on a task, a user can run one timer at a time",
+                "operationId": "start-timer",
+                "parameters": [
+                    {
+                        "description": "Timer data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/timesheet.timerForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me/timer/stop": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Stop the running timer and log its time",
+                "operationId": "stop-timer",
+                "parameters": [
+                    {
+                        "description": "Timer data",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/timesheet.stopTimerForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/me/timesheet": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Weeks count their working days, users their capacity over the working days without their leaves.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Get the hours of the logged-in user per project and week",
+                "operationId": "my-timesheet",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Week offset of the last week, 0 is the current week",
+                        "name": "week",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of weeks, default 1, at most 12",
+                        "name": "weeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/milestones/at-risk": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Milestone"
+                ],
+                "summary": "Get milestones due soon or overdue with a low completion",
+                "operationId": "milestone-at-risk",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Due within this many days, default 14",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Completion below this ratio is at risk, default 0.5",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/project": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get project",
+                "operationId": "option-project",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/type/position": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get position",
+                "operationId": "option-position",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/type/project": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get project type",
+                "operationId": "option-project-type",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/type/project-role": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get project role",
+                "operationId": "option-project-role",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/type/role": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get role",
+                "operationId": "option-role",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/type/schedule": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get schedule type",
+                "operationId": "option-schedule-type",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/option/user": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Option"
+                ],
+                "summary": "Get user",
+                "operationId": "option-user",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Create project",
+                "operationId": "create-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project description",
+                        "name": "description",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project start date",
+                        "name": "start_date",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project end date",
+                        "name": "end_date",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project contributor",
+                        "name": "contributor",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "frontend",
+                            "backend",
+                            "mobile",
+                            "desktop",
+                            "monitor",
+                            "tool",
+                            "etc"
+                        ],
+                        "type": "string",
+                        "description": "Project type",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Project logo",
+                        "name": "logo",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Project attachments",
+                        "name": "attachments",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get project count",
+                "operationId": "count-project",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/count/type": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get project count by type",
+                "operationId": "count-project-type",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get project by id",
+                "operationId": "get-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out of task_count",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Update project by id",
+                "operationId": "update-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "name",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project description",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project start date",
+                        "name": "start_date",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Project end date",
+                        "name": "end_date",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project contributor",
+                        "name": "contributor",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "frontend",
+                            "backend",
+                            "mobile",
+                            "desktop",
+                            "monitor",
+                            "tool",
+                            "etc"
+                        ],
+                        "type": "string",
+                        "description": "Project type",
+                        "name": "type",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "completed",
+                            "pending",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Project status",
+                        "name": "status",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Replace project logo",
+                        "name": "logo",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Remove project logo",
+                        "name": "remove_logo",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Add project attachments",
+                        "name": "attachments",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated attachment keys or urls to remove",
+                        "name": "remove_attachments",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Delete project by id",
+                "operationId": "delete-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/activity": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Activity"
+                ],
+                "summary": "Get the activity of a project, its tasks and its schedules",
+                "operationId": "activity-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "project",
+                            "task",
+                            "schedule",
+                            "milestone",
+                            "sprint"
+                        ],
+                        "type": "string",
+                        "description": "Entity type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/board": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get the tasks of a project grouped by the project board columns",
+                "operationId": "task-board",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by name or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by contributor",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/burndown": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The window defaults to the sprint dates, or the project dates without a sprint.\nThe ideal line runs from the work at the start of the window to zero at its end.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chart"
+                ],
+                "summary": "Get the remaining work of a project or sprint per day next to an ideal line",
+                "operationId": "project-burndown",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sprint of the project",
+                        "name": "sprintId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "tasks",
+                            "points"
+                        ],
+                        "type": "string",
+                        "description": "Count tasks or story points",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/calendar.ics": {
+            "get": {
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "Calendar"
+                ],
+                "summary": "Get project schedules and task deadlines as an iCalendar feed",
+                "operationId": "calendar-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Calendar feed token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/columns": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get the board columns of the project",
+                "operationId": "project-columns",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Columns are ordered and map to a task status, every status needs at least one column.\nTasks of a removed column move to the first column of their status. Sending no columns restores the default columns.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Replace the board columns of the project",
+                "operationId": "update-project-columns",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Columns json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.columnsForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/critical-path": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The chain length is the sum of the task durations. Cancelled tasks are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get the longest chain of dependent tasks of the project",
+                "operationId": "task-critical-path",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/cumulative-flow": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The window defaults to the sprint dates, or the project dates without a sprint. Days to come are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chart"
+                ],
+                "summary": "Get the number of tasks per status at the end of every day",
+                "operationId": "project-cumulative-flow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sprint of the project",
+                        "name": "sprintId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/member/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Only the owner can change roles, giving the owner role to another contributor transfers ownership.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Change the project role of a contributor",
+                "operationId": "update-project-member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update member json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.updateMemberForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/milestones": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Milestone"
+                ],
+                "summary": "Get the milestones of a project with their completion",
+                "operationId": "list-milestone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Milestone"
+                ],
+                "summary": "Create a milestone in a project",
+                "operationId": "create-milestone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Milestone data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/milestone.milestoneForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/milestones/{milestoneId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Milestone"
+                ],
+                "summary": "Get a milestone with its linked tasks",
+                "operationId": "get-milestone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Milestone ID",
+                        "name": "milestoneId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Milestone"
+                ],
+                "summary": "Update a milestone",
+                "operationId": "update-milestone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Milestone ID",
+                        "name": "milestoneId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Milestone data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/milestone.updateMilestoneForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Milestone"
+                ],
+                "summary": "Delete a milestone, its tasks are kept",
+                "operationId": "delete-milestone",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Milestone ID",
+                        "name": "milestoneId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/sprints": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Get the sprints of a project",
+                "operationId": "list-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Create a sprint in a project",
+                "operationId": "create-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sprint data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sprint.sprintForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Bars are listed once and referenced by the groups. Left and width are percentages of the start to end window,\nwhich defaults to the project dates. Tasks appear in the group of every contributor when grouping by contributor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timeline"
+                ],
+                "summary": "Get the tasks, dependencies, schedules and milestones of a project positioned on a timeline",
+                "operationId": "project-timeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "status",
+                            "contributor"
+                        ],
+                        "type": "string",
+                        "description": "Group bars by",
+                        "name": "groupBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Window end date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/project/{id}/workflow": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get the task status transitions allowed in the project",
+                "operationId": "project-workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Transitions without roles and positions are open to every member. Sending no transitions restores the default workflow.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Replace the task status transitions allowed in the project",
+                "operationId": "update-project-workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Project ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Workflow json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/project.workflowForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/projects": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Project"
+                ],
+                "summary": "Get list of project",
+                "operationId": "list-project",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by nama or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "completed",
+                            "pending",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Search by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register",
+                "operationId": "register",
+                "parameters": [
+                    {
+                        "description": "register json",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.registerForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/schedule": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Create schedule",
+                "operationId": "schedule-create",
+                "parameters": [
+                    {
+                        "description": "Schedule data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.scheduleForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "409": {
+                        "description": "A contributor already has an overlapping schedule"
+                    }
+                }
+            }
+        },
+        "/api/schedule/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Get schedule by id",
+                "operationId": "get-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Update schedule",
+                "operationId": "schedule-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update schedule data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.updateScheduleForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "409": {
+                        "description": "A contributor already has an overlapping schedule"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Delete schedule",
+                "operationId": "schedule-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/schedule/{id}/occurrence": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Override or cancel a single occurrence of a recurring schedule",
+                "operationId": "schedule-occurrence-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Occurrence data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schedule.occurrenceForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/schedule/{id}/occurrence/{date}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Remove a single occurrence from a recurring schedule",
+                "operationId": "schedule-occurrence-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Occurrence date in unix milli",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedule"
+                ],
+                "summary": "Get list of schedule",
+                "operationId": "list-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by name",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "all",
+                            "meeting",
+                            "discussion",
+                            "review",
+                            "presentation",
+                            "etc"
+                        ],
+                        "type": "string",
+                        "description": "Search by type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date, recurring schedules are expanded into occurrences within the window",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/sprint/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Get a sprint with its tasks",
+                "operationId": "get-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Update a sprint that is not completed",
+                "operationId": "update-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Sprint data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sprint.updateSprintForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Delete a sprint that is not running, its tasks go back to the backlog",
+                "operationId": "delete-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/sprint/{id}/complete": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Completing a completed sprint again retries moving its unfinished tasks.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Complete the running sprint and move its unfinished tasks into the next sprint or the backlog",
+                "operationId": "complete-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Next sprint",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/sprint.completeSprintForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/sprint/{id}/report": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Committed tasks are the tasks in the sprint when it started. Until the sprint completes,\ncarried-over lists the unfinished tasks that would move on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Get the committed, completed, carried-over and added tasks of a sprint",
+                "operationId": "sprint-report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/sprint/{id}/start": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Start a planned sprint, its current tasks become the committed tasks",
+                "operationId": "start-sprint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/sprint/{id}/tasks": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sprint"
+                ],
+                "summary": "Move tasks of the project into a sprint",
+                "operationId": "add-sprint-tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sprint ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tasks",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/sprint.sprintTasksForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Create task",
+                "operationId": "task-create",
+                "parameters": [
+                    {
+                        "description": "Task data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.taskForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get task count",
+                "operationId": "task-count",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/overview": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get task overview",
+                "operationId": "task-overview",
+                "parameters": [
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month",
+                            "quarter",
+                            "year"
+                        ],
+                        "type": "string",
+                        "description": "Length of a period, default week",
+                        "name": "granularity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of periods up to the current one, default 8, at most 366",
+                        "name": "periods",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by contributor",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get task list by status",
+                "operationId": "task-list-status",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/velocity": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Only full weeks are counted, the current week is left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get the story points completed per week and their average",
+                "operationId": "task-velocity",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of past weeks, default 6, at most 26",
+                        "name": "weeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by contributor",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get task by ID",
+                "operationId": "task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Update task",
+                "operationId": "task-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update task data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.updateTaskForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Delete task",
+                "operationId": "task-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/activity": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Activity"
+                ],
+                "summary": "Get the activity of a task",
+                "operationId": "activity-task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/checklist": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Add an item to the task checklist",
+                "operationId": "task-checklist-create",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checklist item data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.checklistItemForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/checklist/{itemId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Update an item of the task checklist",
+                "operationId": "task-checklist-update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Checklist item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Checklist item data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.checklistItemForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Remove an item from the task checklist",
+                "operationId": "task-checklist-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Checklist item ID",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/comments": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replies are nested under their parent, deleted comments with replies are kept without their content.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Get the comment threads of a task",
+                "operationId": "list-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Comment on a task",
+                "operationId": "create-comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Markdown body",
+                        "name": "body",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID to reply to",
+                        "name": "parent_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Comment attachments",
+                        "name": "attachments",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/dependency": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Dependencies that would make a task depend on itself are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Make the task depend on another task of the project",
+                "operationId": "task-dependency-create",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dependency data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.dependencyForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/dependency/{blockerId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Remove a dependency of the task",
+                "operationId": "task-dependency-delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the task depended on",
+                        "name": "blockerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moving to a column of another status category changes the task status following the project workflow.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Move a task to a position in a board column",
+                "operationId": "task-move",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Move data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.moveForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/time-entries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Get the time entries of a task",
+                "operationId": "task-time-entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Log time on a task",
+                "operationId": "create-time-entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Time entry data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/timesheet.timeEntryForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/task/{id}/transition": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Move a task to another status following the project workflow",
+                "operationId": "task-transition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transition data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/task.transitionForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Task"
+                ],
+                "summary": "Get tasks",
+                "operationId": "tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by nama or description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "testing",
+                            "completed",
+                            "cancelled"
+                        ],
+                        "type": "string",
+                        "description": "Search by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by contributor",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by sprint",
+                        "name": "sprintId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/time-entry/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Update a logged time entry",
+                "operationId": "update-time-entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Time entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Time entry data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/timesheet.timeEntryForm"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Delete a time entry, a running timer is discarded",
+                "operationId": "delete-time-entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Time entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/timesheets": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Weeks count their working days, users their capacity over the working days without their leaves.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Timesheet"
+                ],
+                "summary": "Get the hours of every user per project and week",
+                "operationId": "timesheets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Week offset of the last week, 0 is the current week",
+                        "name": "week",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of weeks, default 1, at most 12",
+                        "name": "weeks",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/user/count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user count",
+                "operationId": "user-count",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/user/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get user",
+                "operationId": "user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get list users",
+                "operationId": "user-list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search by nama",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number pagination",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/verification-code/{email}": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Code"
+                ],
+                "summary": "Create verification code",
+                "operationId": "code-create",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Email User",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/working-calendar": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Working Calendar"
+                ],
+                "summary": "Get the working weekdays and hours of the organization",
+                "operationId": "working-calendar",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/working-days": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Both days are included, leaves are left out when a user is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Working Calendar"
+                ],
+                "summary": "Count the working days between two dates",
+                "operationId": "working-days",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User whose leaves are left out",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/api/workload": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Estimated hours of active and testing tasks are shared by their contributors and spread over the working\ndays of the task. Capacity is the working hours of the working days of the user, holidays and leaves left out.\nThe window defaults to the current and next 3 weeks, users are sorted by load.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workload"
+                ],
+                "summary": "Get the task and schedule hours of every user against their capacity per week",
+                "operationId": "workload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start date",
+                        "name": "start",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End date",
+                        "name": "end",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by position",
+                        "name": "position",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search by project",
+                        "name": "projectId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave subtasks out",
+                        "name": "topLevel",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "admin.createUserForm": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.updateRoleForm": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.forgotPasswordForm": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.loginForm": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.registerForm": {
+            "type": "object",
+            "properties": {
+                "confirm_password": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "digest.preferencesForm": {
+            "type": "object",
+            "properties": {
+                "preferences": {
+                    "description": "digest type to whether it is mailed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                }
+            }
+        },
+        "me.updateMyPasswordForm": {
+            "type": "object",
+            "properties": {
+                "confirm_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                },
+                "verification_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "milestone.milestoneForm": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "task_ids": {
+                    "description": "comma separated task IDs of the project",
+                    "type": "string"
+                }
+            }
+        },
+        "milestone.updateMilestoneForm": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "due_date": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "task_ids": {
+                    "description": "comma separated task IDs, replaces the linked tasks, \"none\" removes them",
+                    "type": "string"
+                }
+            }
+        },
+        "notification.preferencesForm": {
+            "type": "object",
+            "properties": {
+                "preferences": {
+                    "description": "notification type to whether it is sent",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                }
+            }
+        },
+        "project.columnForm": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "active, testing, completed, cancelled",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "optional, derived from the name",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
         },
-        "me.updateMyPasswordForm": {
+        "project.columnsForm": {
             "type": "object",
             "properties": {
-                "confirm_password": {
+                "columns": {
+                    "description": "ordered, empty restores the default columns",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.columnForm"
+                    }
+                }
+            }
+        },
+        "project.updateMemberForm": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "owner, manager, member",
+                    "type": "string"
+                }
+            }
+        },
+        "project.workflowForm": {
+            "type": "object",
+            "properties": {
+                "transitions": {
+                    "description": "empty restores the default workflow",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/project.workflowTransitionForm"
+                    }
+                }
+            }
+        },
+        "project.workflowTransitionForm": {
+            "type": "object",
+            "properties": {
+                "from": {
                     "type": "string"
                 },
-                "new_password": {
+                "positions": {
+                    "description": "e.g. QA",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "roles": {
+                    "description": "owner, manager, member",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
                     "type": "string"
+                }
+            }
+        },
+        "schedule.occurrenceForm": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "description": "Cancelled removes the occurrence from the series instead of overriding it",
+                    "type": "boolean"
                 },
-                "old_password": {
+                "date": {
+                    "description": "original date of the occurrence",
+                    "type": "integer"
+                },
+                "description": {
                     "type": "string"
                 },
-                "verification_code": {
+                "end_time": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_time": {
                     "type": "string"
                 }
             }
@@ -1567,9 +5815,94 @@ const docTemplate = `{
                 "end_time": {
                     "type": "string"
                 },
+                "exception_dates": {
+                    "description": "comma separated unix milli",
+                    "type": "string"
+                },
+                "ignore_conflict": {
+                    "description": "IgnoreConflict saves the schedule even when a contributor is already booked",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "project_id": {
+                    "description": "optional",
+                    "type": "string"
+                },
+                "recurrence_count": {
+                    "type": "integer"
+                },
+                "recurrence_frequency": {
+                    "description": "Recurrence, leave the frequency empty for a one-off schedule",
+                    "type": "string"
+                },
+                "recurrence_interval": {
+                    "type": "integer"
+                },
+                "recurrence_until": {
+                    "type": "integer"
+                },
+                "recurrence_weekdays": {
+                    "description": "comma separated, 0 (Sunday) - 6 (Saturday)",
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "schedule.updateScheduleForm": {
+            "type": "object",
+            "properties": {
+                "contributor": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "exception_dates": {
+                    "description": "comma separated unix milli",
+                    "type": "string"
+                },
+                "ignore_conflict": {
+                    "type": "boolean"
+                },
                 "name": {
                     "type": "string"
                 },
+                "project_id": {
+                    "type": "string"
+                },
+                "recurrence_count": {
+                    "type": "integer"
+                },
+                "recurrence_frequency": {
+                    "description": "Recurrence, use \"none\" as frequency to turn the schedule into a one-off",
+                    "type": "string"
+                },
+                "recurrence_interval": {
+                    "type": "integer"
+                },
+                "recurrence_until": {
+                    "type": "integer"
+                },
+                "recurrence_weekdays": {
+                    "description": "comma separated, 0 (Sunday) - 6 (Saturday)",
+                    "type": "string"
+                },
                 "start_date": {
                     "type": "integer"
                 },
@@ -1581,6 +5914,99 @@ const docTemplate = `{
                 }
             }
         },
+        "sprint.completeSprintForm": {
+            "type": "object",
+            "properties": {
+                "next_sprint_id": {
+                    "description": "optional, unfinished tasks go to the backlog without it",
+                    "type": "string"
+                }
+            }
+        },
+        "sprint.sprintForm": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "integer"
+                },
+                "goal": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "integer"
+                }
+            }
+        },
+        "sprint.sprintTasksForm": {
+            "type": "object",
+            "properties": {
+                "task_ids": {
+                    "description": "comma separated task IDs of the project",
+                    "type": "string"
+                }
+            }
+        },
+        "sprint.updateSprintForm": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "type": "integer"
+                },
+                "goal": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "integer"
+                }
+            }
+        },
+        "task.checklistItemForm": {
+            "type": "object",
+            "properties": {
+                "assignee_id": {
+                    "description": "optional, \"none\" removes the assignee",
+                    "type": "string"
+                },
+                "done": {
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "task.dependencyForm": {
+            "type": "object",
+            "properties": {
+                "blocked_by": {
+                    "description": "ID of the task to depend on",
+                    "type": "string"
+                }
+            }
+        },
+        "task.moveForm": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "description": "board column key",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "zero based position within the column",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "optional, recorded when the move changes the status",
+                    "type": "string"
+                }
+            }
+        },
         "task.taskForm": {
             "type": "object",
             "properties": {
@@ -1593,14 +6019,40 @@ const docTemplate = `{
                 "end_date": {
                     "type": "integer"
                 },
+                "estimated_hours": {
+                    "type": "number"
+                },
                 "name": {
                     "type": "string"
                 },
+                "parent_id": {
+                    "description": "optional, makes the task a subtask",
+                    "type": "string"
+                },
                 "project_id": {
                     "type": "string"
                 },
+                "sprint_id": {
+                    "description": "optional, the task goes to the backlog without it",
+                    "type": "string"
+                },
                 "start_date": {
                     "type": "integer"
+                },
+                "story_points": {
+                    "description": "optional",
+                    "type": "integer"
+                }
+            }
+        },
+        "task.transitionForm": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
@@ -1616,14 +6068,110 @@ const docTemplate = `{
                 "end_date": {
                     "type": "integer"
                 },
+                "estimated_hours": {
+                    "type": "number"
+                },
                 "name": {
                     "type": "string"
                 },
+                "parent_id": {
+                    "description": "\"none\" makes the task top-level again",
+                    "type": "string"
+                },
+                "sprint_id": {
+                    "description": "\"none\" moves the task to the backlog",
+                    "type": "string"
+                },
                 "start_date": {
                     "type": "integer"
                 },
                 "status": {
                     "type": "string"
+                },
+                "story_points": {
+                    "description": "0 clears the estimate",
+                    "type": "integer"
+                }
+            }
+        },
+        "timesheet.stopTimerForm": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "description": "optional, replaces the note given on start",
+                    "type": "string"
+                }
+            }
+        },
+        "timesheet.timeEntryForm": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "minutes, starts at start_date or now",
+                    "type": "integer"
+                },
+                "end_date": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "either a start and end date, or a duration",
+                    "type": "integer"
+                }
+            }
+        },
+        "timesheet.timerForm": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "workday.calendarForm": {
+            "type": "object",
+            "properties": {
+                "hours_per_day": {
+                    "type": "number"
+                },
+                "working_days": {
+                    "description": "weekdays, 0 is Sunday",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "workday.holidayForm": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "workday.leaveForm": {
+            "type": "object",
+            "properties": {
+                "end_date": {
+                    "description": "last day off, the start date for a single day",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "description": "first day off",
+                    "type": "integer"
                 }
             }
         }
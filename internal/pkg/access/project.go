@@ -0,0 +1,105 @@
+package access
+
+import (
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/util"
+	"slices"
+	"strings"
+)
+
+// projectRoleRank orders project roles, a role includes every permission of the roles ranked below it
+var projectRoleRank = map[string]int{
+	_const.ProjectRoleMember:  1,
+	_const.ProjectRoleManager: 2,
+	_const.ProjectRoleOwner:   3,
+}
+
+// HasProjectRole reports whether the user holds at least the given role in the project.
+// Admins and maintainers are allowed everything.
+func HasProjectRole(claims *context.UserClaims, project *repository.Project, role string) bool {
+	if claims.IsAdminOrMaintainer() {
+		return true
+	}
+	return projectRoleRank[project.MemberRole(claims.IDAsObjectID)] >= projectRoleRank[role]
+}
+
+// CanViewProject reports whether the user may read the project and its tasks.
+// Admins and maintainers can read every project.
+func CanViewProject(claims *context.UserClaims, project *repository.Project) bool {
+	return claims.IsAdminOrMaintainer() || project.MemberRole(claims.IDAsObjectID) != ""
+}
+
+// RequireProjectRole returns a 403 error unless the user holds at least the given role in the project
+func RequireProjectRole(claims *context.UserClaims, project *repository.Project, role string) error {
+	if !HasProjectRole(claims, project, role) {
+		return echo.NewHTTPError(http.StatusForbidden, "You do not have permission to perform this action on this project")
+	}
+	return nil
+}
+
+// RequireViewProject returns a 403 error unless the user may read the project
+func RequireViewProject(claims *context.UserClaims, project *repository.Project) error {
+	if !CanViewProject(claims, project) {
+		return echo.NewHTTPError(http.StatusForbidden, "You are not a member of this project")
+	}
+	return nil
+}
+
+// CanModifySchedule reports whether the user may change the schedule. Contributors of the schedule
+// and managers of the project it belongs to are allowed, project may be nil for schedules without one.
+func CanModifySchedule(claims *context.UserClaims, schedule *repository.Schedule, project *repository.Project) bool {
+	if claims.IsAdmin() || slices.Contains(schedule.Contributor, claims.IDAsObjectID) {
+		return true
+	}
+	return project != nil && HasProjectRole(claims, project, _const.ProjectRoleManager)
+}
+
+// CanTransition reports whether the user may move a task along the workflow transition. A transition without
+// roles and positions is open to every member, otherwise the user needs one of the roles or positions.
+func CanTransition(claims *context.UserClaims, position string, project *repository.Project, transition *repository.WorkflowTransition) bool {
+	if claims.IsAdminOrMaintainer() {
+		return true
+	}
+	if project.MemberRole(claims.IDAsObjectID) == "" {
+		return false
+	}
+	if len(transition.Roles) == 0 && len(transition.Positions) == 0 {
+		return true
+	}
+	for _, role := range transition.Roles {
+		if HasProjectRole(claims, project, role) {
+			return true
+		}
+	}
+	for _, allowed := range transition.Positions {
+		if strings.EqualFold(strings.TrimSpace(allowed), strings.TrimSpace(position)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeQuery limits the query to the projects the user contributes to, unless the user is an admin or maintainer.
+// Filtering on a project the user does not belong to is rejected.
+func ScopeQuery(claims *context.UserClaims, projectRepo *repository.ProjectCollRepository, cq *util.CommonQuery) error {
+	if claims.IsAdminOrMaintainer() {
+		return nil
+	}
+
+	projectIds, err := projectRepo.FindIDsByContributor(claims.IDAsObjectID)
+	if err != nil {
+		log.Errorf("Error finding user projects: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "There was an error, please try again")
+	}
+
+	if !cq.ProjectId.IsZero() && !slices.Contains(projectIds, cq.ProjectId) {
+		return echo.NewHTTPError(http.StatusForbidden, "You are not a member of this project")
+	}
+	cq.ProjectIds = projectIds
+	return nil
+}
@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/realtime"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// ignoredFields are derived or bookkeeping fields, a change to them alone is not worth an activity
+var ignoredFields = map[string]bool{
+	"_id":           true,
+	"created_at":    true,
+	"updated_at":    true,
+	"task_count":    true,
+	"comment_count": true,
+	"progress":      true,
+	"subtasks":      true,
+	"warnings":      true,
+	"milestones":    true,
+	// milestone completion follows the linked tasks
+	"completed_count": true,
+	"completion":      true,
+	// status changes are already part of the status field
+	"status_history": true,
+}
+
+// Snapshot captures the JSON fields of a document. Take it before changing the document,
+// handlers modify the loaded struct in place. A nil document gives a nil snapshot.
+func Snapshot(doc interface{}) map[string]interface{} {
+	if doc == nil || reflect.ValueOf(doc).Kind() == reflect.Ptr && reflect.ValueOf(doc).IsNil() {
+		return nil
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		log.Warnf("Error taking activity snapshot: %v", err)
+		return nil
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		log.Warnf("Error taking activity snapshot: %v", err)
+		return nil
+	}
+	return fields
+}
+
+// Diff returns the top-level fields that differ between two snapshots sorted by name.
+// A nil before lists every field as added, a nil after lists every field as removed.
+func Diff(before, after map[string]interface{}) []repository.ActivityChange {
+	changes := make([]repository.ActivityChange, 0)
+
+	fields := make([]string, 0)
+	for field := range before {
+		fields = append(fields, field)
+	}
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if ignoredFields[field] {
+			continue
+		}
+		if reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+		changes = append(changes, repository.ActivityChange{
+			Field: field,
+			Old:   before[field],
+			New:   after[field],
+		})
+	}
+	return changes
+}
+
+// Recorder writes activity events and publishes them to realtime subscribers.
+// Recording never fails the request, errors are only logged.
+type Recorder struct {
+	activityRepo *repository.ActivityCollRepository
+}
+
+func NewRecorder(db *mongo.Database) *Recorder {
+	return &Recorder{
+		activityRepo: repository.NewActivityCollRepository(db),
+	}
+}
+
+// Record stores an activity of the current user, before and after are snapshots of the entity.
+// Updates that change nothing are skipped.
+func (r *Recorder) Record(c echo.Context, entityType string, entityID, projectID bson.ObjectID, action string, before, after map[string]interface{}) {
+	changes := Diff(before, after)
+	if action == _const.ActivityUpdate && len(changes) == 0 {
+		return
+	}
+	r.RecordChanges(c, entityType, entityID, projectID, action, changes)
+}
+
+// RecordChanges stores an activity of the current user with changes that cannot be derived from snapshots
+func (r *Recorder) RecordChanges(c echo.Context, entityType string, entityID, projectID bson.ObjectID, action string, changes []repository.ActivityChange) {
+	uc := c.(*context.Context)
+
+	activity := &repository.Activity{
+		ID:         bson.NewObjectID(),
+		ActorID:    uc.Claims.IDAsObjectID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		ProjectID:  projectID,
+		Action:     action,
+		Changes:    changes,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := r.activityRepo.CreateOne(activity); err != nil {
+		log.Errorf("Error recording activity: %v", err)
+	}
+
+	// Every recorded change is also pushed to the clients watching the project.
+	realtime.Default.Publish(realtime.Event{
+		Type:      entityType,
+		Action:    action,
+		EntityID:  entityID,
+		ProjectID: projectID,
+		ActorID:   activity.ActorID,
+		Data:      changes,
+		CreatedAt: activity.CreatedAt,
+	})
+}
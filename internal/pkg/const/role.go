@@ -7,6 +7,45 @@ const (
 	RoleDeveloper  = "developer"
 )
 
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleMaintainer, RoleDeveloper:
+		return true
+	}
+	return false
+}
+
+func GetAllRoles() []string {
+	return []string{
+		RoleAdmin,
+		RoleMaintainer,
+		RoleDeveloper,
+	}
+}
+
+// Project role type, ordered from the most to the least privileged
+const (
+	ProjectRoleOwner   = "owner"
+	ProjectRoleManager = "manager"
+	ProjectRoleMember  = "member"
+)
+
+func IsValidProjectRole(role string) bool {
+	switch role {
+	case ProjectRoleOwner, ProjectRoleManager, ProjectRoleMember:
+		return true
+	}
+	return false
+}
+
+func GetAllProjectRoles() []string {
+	return []string{
+		ProjectRoleOwner,
+		ProjectRoleManager,
+		ProjectRoleMember,
+	}
+}
+
 // Position type
 const (
 	PositionCEO              = "Chief Executive Officer (CEO)"
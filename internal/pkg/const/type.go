@@ -74,6 +74,22 @@ func GetAllScheduleTypes() []string {
 	}
 }
 
+// Schedule recurrence frequency
+const (
+	RecurrenceNone    = "none"
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+func IsValidRecurrenceFrequency(frequency string) bool {
+	switch frequency {
+	case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly:
+		return true
+	}
+	return false
+}
+
 // Task status
 const (
 	TaskActive    = "active"
@@ -90,6 +106,127 @@ func IsValidTaskStatus(taskStatus string) bool {
 	return false
 }
 
+// Sprint status
+const (
+	SprintPlanned   = "planned"
+	SprintActive    = "active"
+	SprintCompleted = "completed"
+)
+
+func IsValidSprintStatus(sprintStatus string) bool {
+	switch sprintStatus {
+	case SprintPlanned, SprintActive, SprintCompleted:
+		return true
+	}
+	return false
+}
+
+// Activity entity type
+const (
+	EntityProject   = "project"
+	EntityTask      = "task"
+	EntitySchedule  = "schedule"
+	EntityMilestone = "milestone"
+	EntitySprint    = "sprint"
+	EntityUser      = "user"
+)
+
+func IsValidEntityType(entityType string) bool {
+	switch entityType {
+	case EntityProject, EntityTask, EntitySchedule, EntityMilestone, EntitySprint, EntityUser:
+		return true
+	}
+	return false
+}
+
+// Overview granularity, the length of one overview period
+const (
+	GranularityDay     = "day"
+	GranularityWeek    = "week"
+	GranularityMonth   = "month"
+	GranularityQuarter = "quarter"
+	GranularityYear    = "year"
+)
+
+func IsValidGranularity(granularity string) bool {
+	switch granularity {
+	case GranularityDay, GranularityWeek, GranularityMonth, GranularityQuarter, GranularityYear:
+		return true
+	}
+	return false
+}
+
+// Notification type
+const (
+	NotificationProjectContributor  = "project_contributor"
+	NotificationTaskContributor     = "task_contributor"
+	NotificationScheduleContributor = "schedule_contributor"
+	NotificationTaskStatus          = "task_status"
+	NotificationTaskDue             = "task_due"
+)
+
+func GetAllNotificationTypes() []string {
+	return []string{
+		NotificationProjectContributor,
+		NotificationTaskContributor,
+		NotificationScheduleContributor,
+		NotificationTaskStatus,
+		NotificationTaskDue,
+	}
+}
+
+func IsValidNotificationType(notificationType string) bool {
+	switch notificationType {
+	case NotificationProjectContributor, NotificationTaskContributor, NotificationScheduleContributor, NotificationTaskStatus, NotificationTaskDue:
+		return true
+	}
+	return false
+}
+
+// Digest type
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+func GetAllDigestTypes() []string {
+	return []string{
+		DigestDaily,
+		DigestWeekly,
+	}
+}
+
+func IsValidDigestType(digestType string) bool {
+	switch digestType {
+	case DigestDaily, DigestWeekly:
+		return true
+	}
+	return false
+}
+
+// Job status
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+func IsValidJobStatus(status string) bool {
+	switch status {
+	case JobPending, JobRunning, JobDone, JobFailed:
+		return true
+	}
+	return false
+}
+
+// Activity action
+const (
+	ActivityCreate = "create"
+	ActivityUpdate = "update"
+	ActivityDelete = "delete"
+)
+
 var AllowedFileExtension = map[string]bool{
 	"image/jpeg":      true,
 	"image/jpg":       true,
@@ -120,9 +120,12 @@ func ContextHandler(next echo.HandlerFunc) echo.HandlerFunc {
 		if err != nil {
 			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
 		}
-		if nc.LoggedInUser().IsDeleted {
+		user := nc.LoggedInUser()
+		if user.IsDeleted || user.IsDeactivated {
 			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
 		}
+		// Role changes should take effect without waiting for the token to expire.
+		nc.Claims.Role = user.GetRole()
 		return next(nc)
 	}
 }
@@ -136,7 +139,7 @@ func AdminOnly(next echo.HandlerFunc) echo.HandlerFunc {
 		if nc.Claims.IsAdmin() {
 			return next(c)
 		}
-		return echo.ErrUnauthorized
+		return echo.ErrForbidden
 	}
 }
 
@@ -149,7 +152,7 @@ func AdminOrMaintainerOnly(next echo.HandlerFunc) echo.HandlerFunc {
 		if nc.Claims.IsAdminOrMaintainer() {
 			return next(c)
 		}
-		return echo.ErrUnauthorized
+		return echo.ErrForbidden
 	}
 }
 
@@ -157,6 +160,7 @@ func MakeToken(u *repository.User) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
 	claims["id"] = u.ID.Hex()
+	claims["role"] = u.GetRole()
 	claims["expiredDateInMilis"] = time.Now().AddDate(0, 0, config.JWT.Expire).Unix() * 1000
 
 	accessToken, err := token.SignedString([]byte(config.JWT.Key))
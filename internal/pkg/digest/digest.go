@@ -0,0 +1,270 @@
+package digest
+
+import (
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"proman-backend/api/repository"
+	"proman-backend/config"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/job"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/mail"
+	"proman-backend/internal/pkg/util"
+	"sort"
+	"time"
+)
+
+const (
+	// SendHour is the hour of the day from which the digests of the day or week are mailed
+	SendHour = 7
+	// Schedule is when users are checked for digests to mail, every 15 minutes
+	Schedule = "*/15 * * * *"
+	// maxItems is how many items a section lists before the rest is only counted
+	maxItems = 20
+)
+
+// dueSoonDays is how many days ahead a digest lists the tasks due soon
+var dueSoonDays = map[string]int{
+	_const.DigestDaily:  2,
+	_const.DigestWeekly: 7,
+}
+
+// assignmentTypes are the notifications listed as new assignments
+var assignmentTypes = []string{
+	_const.NotificationProjectContributor,
+	_const.NotificationTaskContributor,
+	_const.NotificationScheduleContributor,
+}
+
+// Message is a rendered digest, a digest without items is not worth mailing
+type Message struct {
+	Subject string
+	Body    string
+	Items   int
+}
+
+// Digester builds and mails the daily and weekly digests
+type Digester struct {
+	userRepo         *repository.UserCollRepository
+	projectRepo      *repository.ProjectCollRepository
+	taskRepo         *repository.TaskCollRepository
+	scheduleRepo     *repository.ScheduleCollRepository
+	notificationRepo *repository.NotificationCollRepository
+	digestRepo       *repository.DigestCollRepository
+	queue            *job.Queue
+}
+
+func NewDigester(db *mongo.Database) *Digester {
+	return &Digester{
+		userRepo:         repository.NewUserCollRepository(db),
+		projectRepo:      repository.NewProjectCollRepository(db),
+		taskRepo:         repository.NewTaskCollRepository(db),
+		scheduleRepo:     repository.NewScheduleCollRepository(db),
+		notificationRepo: repository.NewNotificationCollRepository(db),
+		digestRepo:       repository.NewDigestCollRepository(db),
+		queue:            job.NewQueue(db),
+	}
+}
+
+// Period returns the start and end of the day or week (from Monday) covered by a digest sent at now
+func Period(digestType string, now time.Time) (time.Time, time.Time) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if digestType == _const.DigestWeekly {
+		weekday := int(start.Weekday())
+		if weekday == 0 {
+			weekday = 7
+		}
+		start = start.AddDate(0, 0, 1-weekday)
+		return start, start.AddDate(0, 0, 7)
+	}
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Build renders the digest of the user: overdue tasks, tasks due soon, the schedules of the period
+// and the assignments since the previous digest
+func (d *Digester) Build(user *repository.User, digestType string, now time.Time) (*Message, error) {
+	start, end := Period(digestType, now)
+
+	// Assignments are listed since the previous digest, or since the previous period for the first one.
+	since := start.AddDate(0, 0, -1)
+	if digestType == _const.DigestWeekly {
+		since = start.AddDate(0, 0, -7)
+	}
+	last, err := d.digestRepo.FindLast(user.ID, digestType, start)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if last != nil {
+		since = last.SentAt
+	}
+
+	cq := util.NilCommonQuery()
+	cq.UserId = user.ID
+	cq.End = now.AddDate(0, 0, dueSoonDays[digestType])
+	tasks, err := d.taskRepo.FindAllUnfinished(cq)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].EndDate.Before(tasks[j].EndDate)
+	})
+
+	projects := map[bson.ObjectID]string{}
+	projectName := func(projectID bson.ObjectID) string {
+		name, ok := projects[projectID]
+		if !ok {
+			if project, err := d.projectRepo.FindOneByID(projectID); err == nil {
+				name = project.Name
+			}
+			projects[projectID] = name
+		}
+		return name
+	}
+
+	overdue := mail.DigestSection{Title: "Overdue tasks"}
+	dueSoon := mail.DigestSection{Title: "Tasks due soon"}
+	for _, task := range tasks {
+		if task.EndDate.IsZero() || !task.EndDate.Before(cq.End) {
+			continue
+		}
+		item := mail.DigestItem{Name: task.Name}
+		if name := projectName(task.ProjectID); name != "" {
+			item.Name = fmt.Sprintf("%v (%v)", task.Name, name)
+		}
+		if task.EndDate.Before(now) {
+			item.Detail = "Due " + task.EndDate.In(now.Location()).Format("Mon 02 Jan 2006")
+			overdue.Items = append(overdue.Items, item)
+		} else {
+			item.Detail = "Due " + task.EndDate.In(now.Location()).Format("Mon 02 Jan 15:04")
+			dueSoon.Items = append(dueSoon.Items, item)
+		}
+	}
+
+	cq = util.NilCommonQuery()
+	cq.UserId = user.ID
+	cq.Start = start
+	cq.End = end
+	schedules, err := d.scheduleRepo.FindAll(cq)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := make([]repository.ScheduleOccurrence, 0)
+	for _, schedule := range schedules {
+		occurrences = append(occurrences, schedule.Occurrences(start, end)...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if !occurrences[i].StartDate.Equal(occurrences[j].StartDate) {
+			return occurrences[i].StartDate.Before(occurrences[j].StartDate)
+		}
+		return occurrences[i].StartTime < occurrences[j].StartTime
+	})
+
+	agenda := mail.DigestSection{Title: "Today's schedules"}
+	if digestType == _const.DigestWeekly {
+		agenda.Title = "This week's schedules"
+	}
+	for _, occurrence := range occurrences {
+		detail := fmt.Sprintf("%v - %v", occurrence.StartTime, occurrence.EndTime)
+		if digestType == _const.DigestWeekly {
+			detail = occurrence.StartDate.In(now.Location()).Format("Mon 02 Jan") + ", " + detail
+		}
+		agenda.Items = append(agenda.Items, mail.DigestItem{Name: occurrence.Name, Detail: detail})
+	}
+
+	notifications, err := d.notificationRepo.FindAllSince(user.ID, assignmentTypes, since)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := mail.DigestSection{Title: "New assignments"}
+	for _, notification := range notifications {
+		assignments.Items = append(assignments.Items, mail.DigestItem{
+			Name:   notification.Title,
+			Detail: notification.CreatedAt.In(now.Location()).Format("Mon 02 Jan 15:04"),
+		})
+	}
+
+	sections := []mail.DigestSection{overdue, dueSoon, agenda, assignments}
+	message := &Message{
+		Subject: "Daily digest, " + start.Format("Mon 02 Jan 2006"),
+	}
+	intro := "Here is your daily digest."
+	if digestType == _const.DigestWeekly {
+		message.Subject = "Weekly digest, week of " + start.Format("02 Jan 2006")
+		intro = "Here is your weekly digest."
+	}
+	for i := range sections {
+		message.Items += len(sections[i].Items)
+		if more := len(sections[i].Items) - maxItems; more > 0 {
+			sections[i].Items = append(sections[i].Items[:maxItems], mail.DigestItem{Name: fmt.Sprintf("and %v more", more)})
+		}
+	}
+	message.Body = mail.DigestTemplate(message.Subject, intro, sections)
+	return message, nil
+}
+
+// Send queues the digest to be mailed to the user, it reports false when there was nothing to report
+func (d *Digester) Send(user *repository.User, digestType string, now time.Time) (bool, error) {
+	message, err := d.Build(user, digestType, now)
+	if err != nil {
+		return false, err
+	}
+	if message.Items == 0 {
+		return false, nil
+	}
+	if err := d.queue.Mail([]string{user.Email}, message.Subject, message.Body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Run mails the digests of the current day and week that were not sent yet to the users that want them.
+// Every digest is claimed before it is sent, so it goes out once even when a run is repeated.
+// Nothing is sent while mail is disabled.
+func (d *Digester) Run(now time.Time) error {
+	if !config.Mail.Enable || now.Hour() < SendHour {
+		return nil
+	}
+
+	users, err := d.userRepo.FindAllActive("")
+	if err != nil {
+		return err
+	}
+
+	for _, digestType := range _const.GetAllDigestTypes() {
+		start, _ := Period(digestType, now)
+		for i := range users {
+			user := &users[i]
+			if !user.WantsDigest(digestType) {
+				continue
+			}
+
+			claim := &repository.Digest{
+				ID:          bson.NewObjectID(),
+				UserID:      user.ID,
+				Type:        digestType,
+				PeriodStart: start,
+				SentAt:      now,
+			}
+			claimed, err := d.digestRepo.Claim(claim)
+			if err != nil {
+				log.Errorf("Error claiming %v digest: %v", digestType, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			if _, err := d.Send(user, digestType, now); err != nil {
+				log.Errorf("Error sending %v digest to %v: %v", digestType, user.Email, err)
+				if err := d.digestRepo.Release(claim.ID); err != nil {
+					log.Errorf("Error releasing %v digest: %v", digestType, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -99,3 +99,24 @@ func upload(dir string, inputFilePacks *InputFilePack) (string, error) {
 	locations = append(locations, out.Location)
 	return fileDestination, nil
 }
+
+func Delete(key string) error {
+	_, err := S3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(config.S3.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		log.Errorf("Failed to delete media from amazon s3 server, %v", err)
+		return err
+	}
+	return nil
+}
+
+func DeleteMany(keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		_ = Delete(key)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"proman-backend/internal/pkg/log"
+	"slices"
 	"strings"
 	"time"
 )
@@ -154,3 +155,43 @@ func GetFilesThenUpload(c echo.Context, paramName, uploadDir string) ([]string,
 	}
 	return locations, nil
 }
+
+// SplitAttachments resolves the comma separated attachments to remove, given by their key or by the public url
+// returned in the responses. It returns the attachments to keep and the keys to delete, or a 400 error when a
+// requested attachment is not one of the stored ones.
+func SplitAttachments(attachments []string, remove string) ([]string, []string, error) {
+	kept := make([]string, 0)
+	removed := make([]string, 0)
+	if len(remove) == 0 {
+		return append(kept, attachments...), removed, nil
+	}
+
+	requested := make([]string, 0)
+	for _, attachment := range strings.Split(remove, ",") {
+		attachment = strings.TrimSpace(attachment)
+		if attachment != "" && !slices.Contains(requested, attachment) {
+			requested = append(requested, attachment)
+		}
+	}
+	for _, attachment := range attachments {
+		if matchAttachment(attachment, requested) {
+			removed = append(removed, attachment)
+		} else {
+			kept = append(kept, attachment)
+		}
+	}
+	if len(removed) != len(requested) {
+		return nil, nil, echo.NewHTTPError(http.StatusBadRequest, "Attachment not found.")
+	}
+	return kept, removed, nil
+}
+
+// matchAttachment reports whether a stored attachment key was requested, by its key or by its url
+func matchAttachment(attachment string, requested []string) bool {
+	for _, r := range requested {
+		if r == attachment || strings.HasSuffix(r, "/"+attachment) {
+			return true
+		}
+	}
+	return false
+}
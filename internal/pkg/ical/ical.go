@@ -0,0 +1,289 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	maxLineLength  = 75 // octets, RFC 5545 section 3.1
+)
+
+// Calendar is a minimal RFC 5545 VCALENDAR containing VEVENT components
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is a single VEVENT, all-day events only use the date part of Start and End
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Cancelled   bool
+	Created     time.Time
+}
+
+func NewCalendar(name string) *Calendar {
+	return &Calendar{
+		ProdID: "-//Proman//Proman Backend//EN",
+		Name:   name,
+		Events: make([]Event, 0),
+	}
+}
+
+func (c *Calendar) Add(event Event) {
+	c.Events = append(c.Events, event)
+}
+
+// String renders the calendar with CRLF line endings and folded content lines
+func (c *Calendar) String() string {
+	b := &strings.Builder{}
+	now := time.Now()
+
+	writeLine(b, "BEGIN:VCALENDAR")
+	writeLine(b, "VERSION:2.0")
+	writeLine(b, "PRODID:"+c.ProdID)
+	writeLine(b, "CALSCALE:GREGORIAN")
+	writeLine(b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(b, "X-WR-CALNAME:"+Escape(c.Name))
+	}
+
+	for _, event := range c.Events {
+		writeLine(b, "BEGIN:VEVENT")
+		writeLine(b, "UID:"+event.UID)
+		writeLine(b, "DTSTAMP:"+now.UTC().Format(dateTimeFormat))
+		if event.AllDay {
+			writeLine(b, "DTSTART;VALUE=DATE:"+event.Start.Format(dateFormat))
+			writeLine(b, "DTEND;VALUE=DATE:"+event.End.Format(dateFormat))
+		} else {
+			writeLine(b, "DTSTART:"+event.Start.UTC().Format(dateTimeFormat))
+			writeLine(b, "DTEND:"+event.End.UTC().Format(dateTimeFormat))
+		}
+		writeLine(b, "SUMMARY:"+Escape(event.Summary))
+		if event.Description != "" {
+			writeLine(b, "DESCRIPTION:"+Escape(event.Description))
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, 0)
+			for _, category := range event.Categories {
+				categories = append(categories, Escape(category))
+			}
+			writeLine(b, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		if event.Cancelled {
+			writeLine(b, "STATUS:CANCELLED")
+		} else {
+			writeLine(b, "STATUS:CONFIRMED")
+		}
+		if !event.Created.IsZero() {
+			writeLine(b, "CREATED:"+event.Created.UTC().Format(dateTimeFormat))
+		}
+		writeLine(b, "END:VEVENT")
+	}
+
+	writeLine(b, "END:VCALENDAR")
+	return b.String()
+}
+
+// Escape escapes a TEXT value as described in RFC 5545 section 3.3.11
+func Escape(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// writeLine folds lines longer than 75 octets without splitting UTF-8 characters
+func writeLine(b *strings.Builder, line string) {
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	b.WriteString("\r\n")
+}
+
+// UID builds a globally unique identifier for an entity occurrence
+func UID(kind, id, suffix string) string {
+	if suffix == "" {
+		return fmt.Sprintf("%v-%v@proman", kind, id)
+	}
+	return fmt.Sprintf("%v-%v-%v@proman", kind, id, suffix)
+}
+
+// Parse reads the VEVENT components of an RFC 5545 calendar. Dates with a TZID parameter are read in that
+// timezone, floating dates in the server timezone. Recurrence rules are not expanded, only the first
+// occurrence of a recurring event is returned.
+func Parse(r io.Reader) (*Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := NewCalendar("")
+	var event *Event
+	found := false
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			found = true
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			event = &Event{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if event != nil && !event.Start.IsZero() {
+				if event.End.IsZero() {
+					// RFC 5545 section 3.6.1, an all-day event without an end lasts one day
+					event.End = event.Start
+					if event.AllDay {
+						event.End = event.Start.AddDate(0, 0, 1)
+					}
+				}
+				calendar.Add(*event)
+			}
+			event = nil
+		case event == nil:
+			if name == "X-WR-CALNAME" {
+				calendar.Name = Unescape(value)
+			}
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = Unescape(value)
+		case name == "DESCRIPTION":
+			event.Description = Unescape(value)
+		case name == "CATEGORIES":
+			for _, category := range strings.Split(value, ",") {
+				event.Categories = append(event.Categories, Unescape(category))
+			}
+		case name == "STATUS":
+			event.Cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART", name == "DTEND":
+			t, allDay, err := parseTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v %q: %w", name, value, err)
+			}
+			if name == "DTSTART" {
+				event.Start, event.AllDay = t, allDay
+			} else {
+				event.End = t
+			}
+		case name == "CREATED":
+			if t, _, err := parseTime(params, value); err == nil {
+				event.Created = t
+			}
+		}
+	}
+
+	if !found {
+		return nil, errors.New("not an iCalendar file")
+	}
+	return calendar, nil
+}
+
+// Unescape reverses Escape
+func Unescape(text string) string {
+	replacer := strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	)
+	return replacer.Replace(text)
+}
+
+// unfold joins folded content lines, RFC 5545 section 3.1
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lines := make([]string, 0)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// splitLine splits a content line into its upper-cased name, its parameters and its value
+func splitLine(line string) (string, map[string]string, string, bool) {
+	colon := -1
+	quoted := false
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+// parseTime reads a DATE or DATE-TIME value, all-day dates are midnight in the server timezone
+func parseTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateFormat) {
+		t, err := time.ParseInLocation(dateFormat, value, time.Local)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		return t, false, err
+	}
+
+	location := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			location = l
+		}
+	}
+	t, err := time.ParseInLocation(strings.TrimSuffix(dateTimeFormat, "Z"), value, location)
+	return t, false, err
+}
@@ -0,0 +1,61 @@
+package job
+
+import (
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/log"
+	"time"
+)
+
+// CleanupSchedule runs the cleanup every night
+const CleanupSchedule = "30 3 * * *"
+
+// How long data is kept before the cleanup removes it
+const (
+	codeRetention         = 24 * time.Hour
+	notificationRetention = 90 * 24 * time.Hour // read notifications only
+	digestRetention       = 60 * 24 * time.Hour
+	jobRetention          = 30 * 24 * time.Hour // done and failed jobs
+)
+
+// Cleanup removes expired verification codes, old read notifications, old digest records and finished jobs
+type Cleanup struct {
+	codeRepo         *repository.CodeCollRepository
+	notificationRepo *repository.NotificationCollRepository
+	digestRepo       *repository.DigestCollRepository
+	jobRepo          *repository.JobCollRepository
+}
+
+func NewCleanup(db *mongo.Database) *Cleanup {
+	return &Cleanup{
+		codeRepo:         repository.NewCodeCollRepository(db),
+		notificationRepo: repository.NewNotificationCollRepository(db),
+		digestRepo:       repository.NewDigestCollRepository(db),
+		jobRepo:          repository.NewJobCollRepository(db),
+	}
+}
+
+func (c *Cleanup) Run(at time.Time) error {
+	codes, err := c.codeRepo.DeleteExpiredBefore(at.Add(-codeRetention))
+	if err != nil {
+		return err
+	}
+
+	notifications, err := c.notificationRepo.DeleteReadBefore(at.Add(-notificationRetention))
+	if err != nil {
+		return err
+	}
+
+	digests, err := c.digestRepo.DeleteBefore(at.Add(-digestRetention))
+	if err != nil {
+		return err
+	}
+
+	jobs, err := c.jobRepo.DeleteFinishedBefore(at.Add(-jobRetention))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Cleanup removed %v codes, %v notifications, %v digests and %v jobs", codes, notifications, digests, jobs)
+	return nil
+}
@@ -0,0 +1,302 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"os"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/mail"
+	"sync"
+	"time"
+)
+
+const (
+	// TypeMail sends one email
+	TypeMail = "mail"
+	// MaxAttempts is how often a job runs before it is marked as failed
+	MaxAttempts = 5
+	// PollInterval is how long an idle worker waits before looking for due jobs again
+	PollInterval = 2 * time.Second
+	// Lease is how long a running job is reserved for its worker, a job should finish well within it.
+	// A job still running after its lease, because its instance stopped, is taken over by another worker.
+	Lease = 5 * time.Minute
+
+	workers     = 4
+	backoffBase = 30 * time.Second
+	backoffMax  = time.Hour
+)
+
+// Handler runs a job, returning an error retries the job later
+type Handler func(job *repository.Job) error
+
+// Queue stores jobs for the runner, it is all the handlers need to hand work off
+type Queue struct {
+	jobRepo *repository.JobCollRepository
+}
+
+func NewQueue(db *mongo.Database) *Queue {
+	return &Queue{
+		jobRepo: repository.NewJobCollRepository(db),
+	}
+}
+
+// Enqueue stores a job of the type to run as soon as a worker is free, payload is encoded as BSON
+func (q *Queue) Enqueue(jobType string, payload interface{}) error {
+	raw, err := bson.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return q.jobRepo.CreateOne(&repository.Job{
+		ID:          bson.NewObjectID(),
+		Type:        jobType,
+		Payload:     raw,
+		Status:      _const.JobPending,
+		MaxAttempts: MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+	})
+}
+
+type mailPayload struct {
+	Cc      []string `bson:"cc"`
+	To      []string `bson:"to"`
+	Subject string   `bson:"subject"`
+	Body    string   `bson:"body"`
+}
+
+// Mail enqueues an HTML email, it is retried until the mail server accepts it
+func (q *Queue) Mail(to []string, subject, body string) error {
+	return q.Enqueue(TypeMail, mailPayload{To: to, Subject: subject, Body: body})
+}
+
+func sendMail(job *repository.Job) error {
+	payload := mailPayload{}
+	if err := bson.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+	return mail.SendMail(payload.Cc, payload.To, payload.Subject, payload.Body)
+}
+
+// periodicPayload is the payload of periodic runs, At is the time the run was due
+type periodicPayload struct {
+	At time.Time `bson:"at"`
+}
+
+type periodic struct {
+	name string
+	cron *Cron
+	next time.Time
+}
+
+// Runner runs the queued jobs with a few workers and enqueues the periodic jobs when they are due.
+// Every instance may run one, jobs are claimed atomically and periodic runs are enqueued once.
+type Runner struct {
+	jobRepo  *repository.JobCollRepository
+	worker   string
+	handlers map[string]Handler
+	periodic []*periodic
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewRunner(db *mongo.Database) *Runner {
+	hostname, _ := os.Hostname()
+
+	r := &Runner{
+		jobRepo:  repository.NewJobCollRepository(db),
+		worker:   fmt.Sprintf("%v-%v", hostname, os.Getpid()),
+		handlers: map[string]Handler{},
+		stop:     make(chan struct{}),
+	}
+	r.Handle(TypeMail, sendMail)
+	r.Handle(TypeResetPassword, resetPassword(repository.NewUserCollRepository(db)))
+	return r
+}
+
+// Handle registers the handler of a job type, register every handler before Start
+func (r *Runner) Handle(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// Periodic runs fn through the queue whenever the cron expression is due, at is the time the run was due.
+// Runs missed while no instance was running are skipped.
+func (r *Runner) Periodic(name, spec string, fn func(at time.Time) error) error {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+
+	r.periodic = append(r.periodic, &periodic{name: name, cron: cron})
+	r.Handle(name, func(job *repository.Job) error {
+		payload := periodicPayload{}
+		if err := bson.Unmarshal(job.Payload, &payload); err != nil {
+			return err
+		}
+		return fn(payload.At)
+	})
+	return nil
+}
+
+func (r *Runner) Start() {
+	now := time.Now()
+	for _, p := range r.periodic {
+		p.next = p.cron.Next(now)
+	}
+
+	r.wg.Add(1)
+	go r.schedule()
+
+	for i := 0; i < workers; i++ {
+		r.wg.Add(1)
+		go r.work()
+	}
+	log.Infof("Job runner %v started with %v workers", r.worker, workers)
+}
+
+// Stop stops taking jobs and waits for the running ones. When ctx ends first it returns without them,
+// a job cut off by the exit is taken over by another worker once its lease ends.
+func (r *Runner) Stop(ctx context.Context) error {
+	close(r.stop)
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// wait sleeps for d and reports false when the runner is stopping
+func (r *Runner) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-r.stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (r *Runner) schedule() {
+	defer r.wg.Done()
+	defer log.RecoverWithTrace()
+
+	for r.wait(PollInterval) {
+		now := time.Now()
+		for _, p := range r.periodic {
+			if p.next.IsZero() || now.Before(p.next) {
+				continue
+			}
+
+			raw, err := bson.Marshal(periodicPayload{At: p.next})
+			if err != nil {
+				log.Errorf("Error encoding %v job: %v", p.name, err)
+				continue
+			}
+			_, err = r.jobRepo.CreateOnce(&repository.Job{
+				ID:          bson.NewObjectID(),
+				Type:        p.name,
+				Payload:     raw,
+				Key:         fmt.Sprintf("%v:%v", p.name, p.next.Unix()),
+				Status:      _const.JobPending,
+				MaxAttempts: MaxAttempts,
+				RunAt:       p.next,
+				CreatedAt:   now,
+			})
+			if err != nil {
+				log.Errorf("Error enqueuing %v job: %v", p.name, err)
+				continue
+			}
+			p.next = p.cron.Next(now)
+		}
+	}
+}
+
+func (r *Runner) work() {
+	defer r.wg.Done()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+
+		job, err := r.jobRepo.Claim(r.worker, time.Now(), Lease)
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Errorf("Error claiming job: %v", err)
+			}
+			if !r.wait(PollInterval) {
+				return
+			}
+			continue
+		}
+		r.run(job)
+	}
+}
+
+func (r *Runner) run(job *repository.Job) {
+	err := r.call(job)
+	if err == nil {
+		if err := r.jobRepo.Complete(job.ID, r.worker); err != nil {
+			log.Errorf("Error completing %v job %v: %v", job.Type, job.ID.Hex(), err)
+		}
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		log.Errorf("%v job %v failed after %v attempts: %v", job.Type, job.ID.Hex(), job.Attempts, err)
+		if err := r.jobRepo.Fail(job.ID, r.worker, err.Error()); err != nil {
+			log.Errorf("Error failing %v job %v: %v", job.Type, job.ID.Hex(), err)
+		}
+		return
+	}
+
+	log.Warnf("%v job %v attempt %v failed: %v", job.Type, job.ID.Hex(), job.Attempts, err)
+	if err := r.jobRepo.Retry(job.ID, r.worker, time.Now().Add(Backoff(job.Attempts)), err.Error()); err != nil {
+		log.Errorf("Error retrying %v job %v: %v", job.Type, job.ID.Hex(), err)
+	}
+}
+
+// call runs the handler of the job, a panic fails the attempt like an error
+func (r *Runner) call(job *repository.Job) (err error) {
+	handler, ok := r.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler for job type %v", job.Type)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return handler(job)
+}
+
+// Backoff is how long to wait before the next attempt of a job that failed its attempt-th time:
+// 30 seconds doubling with every attempt, at most an hour
+func Backoff(attempt int) time.Duration {
+	backoff := backoffBase
+	for i := 1; i < attempt && backoff < backoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, backoffMax)
+}
@@ -0,0 +1,56 @@
+package job
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/mail"
+	"proman-backend/internal/pkg/util"
+)
+
+// TypeResetPassword sets a new password for a user and mails it
+const TypeResetPassword = "reset-password"
+
+type resetPasswordPayload struct {
+	UserID bson.ObjectID `bson:"user_id"`
+}
+
+// ResetPassword enqueues a new password for the user. The password is generated when the job runs,
+// so it is never stored in the queue.
+func (q *Queue) ResetPassword(userID bson.ObjectID) error {
+	return q.Enqueue(TypeResetPassword, resetPasswordPayload{UserID: userID})
+}
+
+// resetPassword saves the new password only after it was mailed, so the saved password is always one the user
+// received. A failed attempt leaves the old password in place and the retry mails another one.
+func resetPassword(userRepo *repository.UserCollRepository) Handler {
+	return func(job *repository.Job) error {
+		payload := resetPasswordPayload{}
+		if err := bson.Unmarshal(job.Payload, &payload); err != nil {
+			return err
+		}
+
+		user, err := userRepo.FindOneByID(payload.UserID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			log.Warnf("Password reset skipped, user %v not found", payload.UserID.Hex())
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if user.IsDeactivated {
+			log.Warnf("Password reset skipped, user %v is deactivated", user.Email)
+			return nil
+		}
+
+		newPassword := util.RandomString(10)
+		if err := mail.SendMail(nil, []string{user.Email}, "New Password", mail.ForgotTemplate(newPassword)); err != nil {
+			return err
+		}
+		user.Password = util.CryptPassword(newPassword)
+		_, err = userRepo.Update(user)
+		return err
+	}
+}
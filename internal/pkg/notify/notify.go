@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"proman-backend/api/repository"
+	"proman-backend/internal/pkg/const"
+	"proman-backend/internal/pkg/context"
+	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/realtime"
+	"slices"
+	"time"
+)
+
+const (
+	// DueSoonWithin is how long before its end date the contributors of a task are reminded
+	DueSoonWithin = 24 * time.Hour
+	// DueSoonSchedule is when tasks nearing their end date are looked for, every hour
+	DueSoonSchedule = "0 * * * *"
+)
+
+// contributorTypes maps entity types to the notification sent to new contributors
+var contributorTypes = map[string]string{
+	_const.EntityProject:  _const.NotificationProjectContributor,
+	_const.EntityTask:     _const.NotificationTaskContributor,
+	_const.EntitySchedule: _const.NotificationScheduleContributor,
+}
+
+// Notifier creates notifications. Notifying never fails the request, errors are only logged.
+// The user making the change is never notified of it.
+type Notifier struct {
+	notificationRepo *repository.NotificationCollRepository
+	userRepo         *repository.UserCollRepository
+	taskRepo         *repository.TaskCollRepository
+}
+
+func NewNotifier(db *mongo.Database) *Notifier {
+	return &Notifier{
+		notificationRepo: repository.NewNotificationCollRepository(db),
+		userRepo:         repository.NewUserCollRepository(db),
+		taskRepo:         repository.NewTaskCollRepository(db),
+	}
+}
+
+// Contributors notifies the users in after that were not in before that they were added to the entity
+func (n *Notifier) Contributors(c echo.Context, entityType string, entityID, projectID bson.ObjectID, name string, before, after []bson.ObjectID) {
+	notificationType, ok := contributorTypes[entityType]
+	if !ok {
+		return
+	}
+
+	added := make([]bson.ObjectID, 0)
+	for _, userID := range after {
+		if !slices.Contains(before, userID) && !slices.Contains(added, userID) {
+			added = append(added, userID)
+		}
+	}
+
+	n.send(c, added, repository.Notification{
+		Type:       notificationType,
+		EntityType: entityType,
+		EntityID:   entityID,
+		ProjectID:  projectID,
+		Title:      fmt.Sprintf("You were added to the %v %v", entityType, name),
+	})
+}
+
+// TaskStatus notifies the contributors of the task that it moved from the given status
+func (n *Notifier) TaskStatus(c echo.Context, task *repository.Task, from string) {
+	if task.Status == from {
+		return
+	}
+
+	n.send(c, task.Contributor, repository.Notification{
+		Type:       _const.NotificationTaskStatus,
+		EntityType: _const.EntityTask,
+		EntityID:   task.ID,
+		ProjectID:  task.ProjectID,
+		Title:      fmt.Sprintf("%v is now %v", task.Name, task.Status),
+		Message:    fmt.Sprintf("The task moved from %v to %v.", from, task.Status),
+	})
+}
+
+// DueSoon reminds the contributors of the active and testing tasks ending within DueSoonWithin.
+// Every contributor is reminded once per end date, moving the end date reminds them again.
+func (n *Notifier) DueSoon(now time.Time) error {
+	tasks, err := n.taskRepo.FindAllDueBetween(now, now.Add(DueSoonWithin))
+	if err != nil {
+		return err
+	}
+
+	users := map[bson.ObjectID]*repository.User{}
+	for _, task := range tasks {
+		for _, userID := range task.Contributor {
+			user, ok := users[userID]
+			if !ok {
+				if user, err = n.userRepo.FindOneByID(userID); err != nil {
+					log.Warnf("Error finding user %v to notify: %v", userID.Hex(), err)
+				}
+				users[userID] = user
+			}
+			if user == nil || !user.WantsNotification(_const.NotificationTaskDue) {
+				continue
+			}
+
+			notification := &repository.Notification{
+				ID:         bson.NewObjectID(),
+				UserID:     userID,
+				Type:       _const.NotificationTaskDue,
+				EntityType: _const.EntityTask,
+				EntityID:   task.ID,
+				ProjectID:  task.ProjectID,
+				Title:      fmt.Sprintf("%v is due soon", task.Name),
+				Message:    fmt.Sprintf("The task ends on %v.", task.EndDate.Local().Format("02 Jan 2006 15:04")),
+				Key:        fmt.Sprintf("%v:%v:%v:%v", _const.NotificationTaskDue, task.ID.Hex(), task.EndDate.Unix(), userID.Hex()),
+				CreatedAt:  now,
+			}
+			created, err := n.notificationRepo.CreateOnce(notification)
+			if err != nil {
+				log.Errorf("Error creating notification: %v", err)
+				continue
+			}
+			if created {
+				publish(notification)
+			}
+		}
+	}
+	return nil
+}
+
+// send stores a copy of the notification for every user that wants it, except the current user
+func (n *Notifier) send(c echo.Context, userIDs []bson.ObjectID, notification repository.Notification) {
+	actorID := c.(*context.Context).Claims.IDAsObjectID
+	now := time.Now()
+
+	for _, userID := range userIDs {
+		if userID == actorID {
+			continue
+		}
+
+		user, err := n.userRepo.FindOneByID(userID)
+		if err != nil {
+			log.Warnf("Error finding user %v to notify: %v", userID.Hex(), err)
+			continue
+		}
+		if !user.WantsNotification(notification.Type) {
+			continue
+		}
+
+		doc := notification
+		doc.ID = bson.NewObjectID()
+		doc.UserID = userID
+		doc.ActorID = actorID
+		doc.CreatedAt = now
+		if err := n.notificationRepo.CreateOne(&doc); err != nil {
+			log.Errorf("Error creating notification: %v", err)
+			continue
+		}
+		publish(&doc)
+	}
+}
+
+// publish pushes a stored notification to the streams of its user
+func publish(notification *repository.Notification) {
+	realtime.Default.Publish(realtime.Event{
+		Type:      realtime.EventNotification,
+		Action:    _const.ActivityCreate,
+		EntityID:  notification.ID,
+		ProjectID: notification.ProjectID,
+		ActorID:   notification.ActorID,
+		UserIDs:   []bson.ObjectID{notification.UserID},
+		Data:      notification,
+		CreatedAt: notification.CreatedAt,
+	})
+}
@@ -0,0 +1,127 @@
+package realtime
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// EventNotification is the type of events carrying a new notification, the other events are named after their entity type
+	EventNotification = "notification"
+	// bufferSize is how many events a subscriber may fall behind before it is dropped
+	bufferSize = 64
+)
+
+// Event is a change pushed to the subscribers of its project, or only to UserIDs when they are set
+type Event struct {
+	Type      string          `json:"type"`
+	Action    string          `json:"action"`
+	EntityID  bson.ObjectID   `json:"entity_id"`
+	ProjectID bson.ObjectID   `json:"project_id"`
+	ActorID   bson.ObjectID   `json:"actor_id"`
+	UserIDs   []bson.ObjectID `json:"-"`
+	Data      interface{}     `json:"data,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Subscription receives the events of its projects and of its user until it is unsubscribed.
+// Events is closed when the subscription ends, also when the subscriber falls too far behind.
+type Subscription struct {
+	UserID     bson.ObjectID
+	ProjectIDs []bson.ObjectID
+	events     chan Event
+}
+
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) wants(event Event) bool {
+	if len(event.UserIDs) > 0 {
+		return slices.Contains(event.UserIDs, s.UserID)
+	}
+	return !event.ProjectID.IsZero() && slices.Contains(s.ProjectIDs, event.ProjectID)
+}
+
+// Broker delivers events to subscriptions. Hub only reaches the subscribers of this instance,
+// running several instances needs a Broker backed by a shared message bus.
+type Broker interface {
+	Publish(event Event)
+	Subscribe(userID bson.ObjectID, projectIDs []bson.ObjectID) *Subscription
+	Unsubscribe(sub *Subscription)
+	// Close ends every subscription, their streams finish so the server can shut down
+	Close()
+}
+
+// Default is the broker used by the handlers
+var Default Broker = NewHub()
+
+// Hub is an in-process Broker
+type Hub struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish never blocks, a subscriber whose buffer is full is dropped so its client reconnects and reloads
+func (h *Hub) Publish(event Event) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		if !sub.wants(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			delete(h.subs, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (h *Hub) Subscribe(userID bson.ObjectID, projectIDs []bson.ObjectID) *Subscription {
+	sub := &Subscription{
+		UserID:     userID,
+		ProjectIDs: projectIDs,
+		events:     make(chan Event, bufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
+
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		close(sub.events)
+	}
+}
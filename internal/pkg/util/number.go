@@ -0,0 +1,8 @@
+package util
+
+import "math"
+
+// Round rounds the value to two decimals
+func Round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
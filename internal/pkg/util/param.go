@@ -4,19 +4,32 @@ import (
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"math"
+	"proman-backend/internal/pkg/const"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// MaxPeriods is the most periods an overview can have, a year of days
+const MaxPeriods = 366
+
 type CommonQuery struct {
 	Q         string
 	Type      string
 	Status    string
+	Role      string
 	UserId    bson.ObjectID
 	ProjectId bson.ObjectID
-	Start     time.Time
-	End       time.Time
+	// ProjectIds limits results to these projects when not nil, used to scope developers to their projects
+	ProjectIds []bson.ObjectID
+	// TopLevel leaves subtasks out of task lists and counts
+	TopLevel bool
+	SprintId bson.ObjectID
+	Start    time.Time
+	End      time.Time
+	// Granularity and Periods shape overviews, empty and zero fall back to the overview defaults
+	Granularity string
+	Periods     int
 
 	Sort  int8
 	Page  int64
@@ -27,6 +40,7 @@ func NewCommonQuery(c echo.Context) *CommonQuery {
 	qParam := c.QueryParam("q")
 	statusParam := strings.ToLower(strings.TrimSpace(c.QueryParam("status")))
 	typeParam := strings.ToLower(strings.TrimSpace(c.QueryParam("type")))
+	roleParam := strings.ToLower(strings.TrimSpace(c.QueryParam("role")))
 	userIdParam := strings.TrimSpace(c.QueryParam("userId"))
 	projectIdParam := strings.TrimSpace(c.QueryParam("projectId"))
 	startParam := strings.TrimSpace(c.QueryParam("start"))
@@ -34,11 +48,16 @@ func NewCommonQuery(c echo.Context) *CommonQuery {
 	sortParam := strings.TrimSpace(c.QueryParam("sort"))
 	pageParam := strings.TrimSpace(c.QueryParam("page"))
 	limitParam := strings.TrimSpace(c.QueryParam("limit"))
+	topLevelParam := strings.TrimSpace(c.QueryParam("topLevel"))
+	sprintIdParam := strings.TrimSpace(c.QueryParam("sprintId"))
+	granularityParam := strings.ToLower(strings.TrimSpace(c.QueryParam("granularity")))
+	periodsParam := strings.TrimSpace(c.QueryParam("periods"))
 
 	cq := CommonQuery{
 		Q:      qParam,
 		Status: statusParam,
 		Type:   typeParam,
+		Role:   roleParam,
 		UserId: bson.NilObjectID,
 		Start:  time.UnixMilli(0),
 		End:    time.UnixMilli(math.MaxInt64),
@@ -62,6 +81,31 @@ func NewCommonQuery(c echo.Context) *CommonQuery {
 		}
 	}
 
+	if len(sprintIdParam) > 0 {
+		sprintId, err := bson.ObjectIDFromHex(sprintIdParam)
+		if err == nil {
+			cq.SprintId = sprintId
+		}
+	}
+
+	if len(topLevelParam) > 0 {
+		topLevel, err := strconv.ParseBool(topLevelParam)
+		if err == nil {
+			cq.TopLevel = topLevel
+		}
+	}
+
+	if _const.IsValidGranularity(granularityParam) {
+		cq.Granularity = granularityParam
+	}
+
+	if len(periodsParam) > 0 {
+		periods, err := strconv.Atoi(periodsParam)
+		if err == nil && periods > 0 && periods <= MaxPeriods {
+			cq.Periods = periods
+		}
+	}
+
 	if len(startParam) > 0 {
 		startUnixMilli, err := strconv.ParseInt(startParam, 10, 64)
 		if err == nil {
@@ -106,10 +150,16 @@ func NilCommonQuery() *CommonQuery {
 	dr.Q = ""
 	dr.Status = ""
 	dr.Type = ""
+	dr.Role = ""
 	dr.UserId = bson.NilObjectID
 	dr.ProjectId = bson.NilObjectID
+	dr.ProjectIds = nil
+	dr.TopLevel = false
+	dr.SprintId = bson.NilObjectID
 	dr.Start = time.UnixMilli(0)
 	dr.End = time.UnixMilli(math.MaxInt64)
+	dr.Granularity = ""
+	dr.Periods = 0
 	dr.Sort = 1
 	dr.Page = 1
 	dr.Limit = math.MaxInt64
@@ -129,10 +179,16 @@ func (dr *CommonQuery) ResetAll() *CommonQuery {
 	dr.Q = ""
 	dr.Status = ""
 	dr.Type = ""
+	dr.Role = ""
 	dr.UserId = bson.NilObjectID
 	dr.ProjectId = bson.NilObjectID
+	dr.ProjectIds = nil
+	dr.TopLevel = false
+	dr.SprintId = bson.NilObjectID
 	dr.Start = time.UnixMilli(0)
 	dr.End = time.Date(time.Now().Year(), time.Now().Month(), time.Now().Day(), 23, 59, 59, 0, time.Local)
+	dr.Granularity = ""
+	dr.Periods = 0
 	dr.Sort = 1
 	dr.Page = 1
 	dr.Limit = math.MaxInt64
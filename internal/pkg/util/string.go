@@ -1,6 +1,9 @@
 package util
 
 import (
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"math/rand"
 	"time"
 	"unsafe"
@@ -27,3 +30,18 @@ func RandomString(n int) string {
 	}
 	return *(*string)(unsafe.Pointer(&b))
 }
+
+// SecureRandomString returns a hex encoded token of n random bytes, suitable for secrets
+func SecureRandomString(n int) string {
+	b := make([]byte, n)
+	if _, err := cryptorand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// HashToken returns the SHA-256 hex digest of a token, so it can be stored without revealing it
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
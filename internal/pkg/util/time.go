@@ -1,6 +1,10 @@
 package util
 
-import "time"
+import (
+	"proman-backend/internal/pkg/const"
+	"strings"
+	"time"
+)
 
 // StartOfDay calculates the start of the day with an offset in days
 func StartOfDay(offset int) time.Time {
@@ -8,6 +12,12 @@ func StartOfDay(offset int) time.Time {
 	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 }
 
+// DateOf returns midnight of the day of the time in the server timezone
+func DateOf(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
 // EndOfDay calculates the end of the day with an offset in days
 func EndOfDay(offset int) time.Time {
 	return StartOfDay(offset + 1).Add(-time.Second)
@@ -32,8 +42,8 @@ func EndOfWeek(offset int) time.Time {
 
 // StartOfMonth calculates the start of the month with an offset in months
 func StartOfMonth(offset int) time.Time {
-	now := time.Now().AddDate(0, offset, 0)
-	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, offset, 0)
 }
 
 // EndOfMonth calculates the end of the month with an offset in months
@@ -41,6 +51,17 @@ func EndOfMonth(offset int) time.Time {
 	return StartOfMonth(offset + 1).Add(-time.Second)
 }
 
+// StartOfQuarter calculates the start of the quarter with an offset in quarters
+func StartOfQuarter(offset int) time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month()-(now.Month()-1)%3, 1, 0, 0, 0, 0, now.Location()).AddDate(0, offset*3, 0)
+}
+
+// EndOfQuarter calculates the end of the quarter with an offset in quarters
+func EndOfQuarter(offset int) time.Time {
+	return StartOfQuarter(offset + 1).Add(-time.Second)
+}
+
 // StartOfYear calculates the start of the year with an offset in years
 func StartOfYear(offset int) time.Time {
 	now := time.Now().AddDate(offset, 0, 0)
@@ -51,3 +72,27 @@ func StartOfYear(offset int) time.Time {
 func EndOfYear(offset int) time.Time {
 	return StartOfYear(offset + 1).Add(-time.Second)
 }
+
+// StartOfPeriod calculates the start of the day, week, month, quarter or year with an offset in that granularity
+func StartOfPeriod(granularity string, offset int) time.Time {
+	switch granularity {
+	case _const.GranularityDay:
+		return StartOfDay(offset)
+	case _const.GranularityMonth:
+		return StartOfMonth(offset)
+	case _const.GranularityQuarter:
+		return StartOfQuarter(offset)
+	case _const.GranularityYear:
+		return StartOfYear(offset)
+	}
+	return StartOfWeek(offset)
+}
+
+// Timezone returns the server timezone for MongoDB date operators, the TZ name when it is set
+// or else the current UTC offset
+func Timezone() string {
+	if name := time.Local.String(); strings.Contains(name, "/") || name == "UTC" {
+		return name
+	}
+	return time.Now().Format("-07:00")
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -10,21 +12,46 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	echoswagger "github.com/swaggo/echo-swagger"
 	"net/http"
+	"os"
+	"os/signal"
+	"proman-backend/api/handler/activity"
+	"proman-backend/api/handler/admin"
 	"proman-backend/api/handler/auth"
+	"proman-backend/api/handler/calendar"
+	"proman-backend/api/handler/chart"
 	"proman-backend/api/handler/code"
+	"proman-backend/api/handler/comment"
+	"proman-backend/api/handler/digest"
+	"proman-backend/api/handler/event"
+	"proman-backend/api/handler/job"
 	"proman-backend/api/handler/me"
+	"proman-backend/api/handler/milestone"
+	"proman-backend/api/handler/notification"
 	"proman-backend/api/handler/option"
 	"proman-backend/api/handler/project"
 	"proman-backend/api/handler/schedule"
+	"proman-backend/api/handler/sprint"
 	"proman-backend/api/handler/task"
+	"proman-backend/api/handler/timeline"
+	"proman-backend/api/handler/timesheet"
 	"proman-backend/api/handler/user"
+	"proman-backend/api/handler/workday"
+	"proman-backend/api/handler/workload"
+	"proman-backend/api/repository"
 	"proman-backend/config"
 	"proman-backend/docs"
 	"proman-backend/internal/database"
+	"proman-backend/internal/pkg/const"
+	_digest "proman-backend/internal/pkg/digest"
 	"proman-backend/internal/pkg/file"
+	_job "proman-backend/internal/pkg/job"
 	"proman-backend/internal/pkg/log"
+	"proman-backend/internal/pkg/notify"
+	"proman-backend/internal/pkg/realtime"
 	"proman-backend/version"
 	"strings"
+	"syscall"
+	"time"
 )
 
 // @title Proman Backend
@@ -39,6 +66,8 @@ import (
 
 const appName = "Proman Backend"
 
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	defer log.RecoverWithTrace()
 
@@ -47,7 +76,17 @@ func main() {
 
 	//git_api.InitGitlab()
 	db := database.ConnectMongo()
-	var err error
+	err := repository.CreateIndexes(db)
+	if err != nil {
+		log.Fatal("MongoDB index error: ", err)
+	}
+
+	if config.App.AdminEmail != "" {
+		err = repository.NewUserCollRepository(db).UpdateRoleByEmail(config.App.AdminEmail, _const.RoleAdmin)
+		if err != nil {
+			log.Errorf("Failed to promote %v to admin: %v", config.App.AdminEmail, err)
+		}
+	}
 
 	//projects, _, err := git_api.Client.Users.CreateUser(
 	//	&gitlab.CreateUserOptions{
@@ -102,13 +141,69 @@ func main() {
 	docs.SwaggerInfo.Host = config.App.SwaggerHost
 
 	auth.NewHandler(e, db)
+	admin.NewHandler(e, db)
 	me.NewHandler(e, db)
 	project.NewHandler(e, db)
 	task.NewHandler(e, db)
+	timeline.NewHandler(e, db)
+	timesheet.NewHandler(e, db)
+	milestone.NewHandler(e, db)
+	comment.NewHandler(e, db)
+	activity.NewHandler(e, db)
+	notification.NewHandler(e, db)
+	event.NewHandler(e, db)
+	digest.NewHandler(e, db)
+	job.NewHandler(e, db)
 	user.NewHandler(e, db)
 	schedule.NewHandler(e, db)
+	sprint.NewHandler(e, db)
+	chart.NewHandler(e, db)
+	calendar.NewHandler(e, db)
+	workday.NewHandler(e, db)
+	workload.NewHandler(e, db)
 	code.NewHandler(e, db)
 	option.NewHandler(e, db)
 
-	log.Fatal(e.Start(fmt.Sprintf(`%v:%v`, config.App.Host, config.App.Port)))
+	runner := _job.NewRunner(db)
+	periodic := []struct {
+		name string
+		spec string
+		fn   func(at time.Time) error
+	}{
+		{"due-soon", notify.DueSoonSchedule, notify.NewNotifier(db).DueSoon},
+		{"digest", _digest.Schedule, _digest.NewDigester(db).Run},
+		{"cleanup", _job.CleanupSchedule, _job.NewCleanup(db).Run},
+	}
+	for _, p := range periodic {
+		if err := runner.Periodic(p.name, p.spec, p.fn); err != nil {
+			log.Fatal("Job schedule error: ", err)
+		}
+	}
+	runner.Start()
+
+	// Event streams stay open until they are closed, end them so the shutdown does not wait for them.
+	e.Server.RegisterOnShutdown(realtime.Default.Close)
+
+	go func() {
+		err := e.Start(fmt.Sprintf(`%v:%v`, config.App.Host, config.App.Port))
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	// Finish the requests and the running jobs, jobs cut off by the timeout are picked up again later.
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(ctx); err != nil {
+		log.Errorf("Server shutdown error: %v", err)
+	}
+	if err := runner.Stop(ctx); err != nil {
+		log.Errorf("Job runner shutdown error: %v", err)
+	}
+	log.Info("Server stopped")
 }